@@ -5,6 +5,7 @@ import (
 
 	"github.com/machinefi/w3bstream/cmd/srv-applet-mgr/apis/middleware"
 	"github.com/machinefi/w3bstream/pkg/depends/kit/httptransport/httpx"
+	"github.com/machinefi/w3bstream/pkg/errors/status"
 	"github.com/machinefi/w3bstream/pkg/modules/vm"
 	"github.com/machinefi/w3bstream/pkg/types"
 )
@@ -50,3 +51,26 @@ func (r *GetInstanceByAppletID) Output(ctx context.Context) (interface{}, error)
 	ins.State, _ = vm.GetInstanceState(ins.InstanceID)
 	return ins, nil
 }
+
+type GetInstanceStats struct {
+	httpx.MethodGet
+	InstanceID types.SFID `in:"path" name:"instanceID"`
+}
+
+func (r *GetInstanceStats) Path() string {
+	return "/instance/:instanceID/stats"
+}
+
+func (r *GetInstanceStats) Output(ctx context.Context) (interface{}, error) {
+	ctx, err := middleware.MustCurrentAccountFromContext(ctx).
+		WithInstanceContextBySFID(ctx, r.InstanceID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, ok := vm.GetInstanceStats(types.MustInstanceFromContext(ctx).InstanceID)
+	if !ok {
+		return nil, status.InstanceNotFound
+	}
+	return stats, nil
+}
@@ -14,6 +14,7 @@ func init() {
 	Root.Register(kit.NewRouter(&CreateAndStartInstance{}))
 	Root.Register(kit.NewRouter(&GetInstanceByInstanceID{}))
 	Root.Register(kit.NewRouter(&GetInstanceByAppletID{}))
+	Root.Register(kit.NewRouter(&GetInstanceStats{}))
 	Root.Register(kit.NewRouter(&ControlInstance{}))
 	Root.Register(kit.NewRouter(&RemoveInstance{}))
 	Root.Register(kit.NewRouter(&middleware.ProjectProvider{}, &BatchRemoveInstance{}))
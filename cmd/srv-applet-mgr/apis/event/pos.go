@@ -38,10 +38,20 @@ func (r *HandleEvent) Output(ctx context.Context) (interface{}, error) {
 	defer l.End()
 
 	receivedTs := time.Now().UTC().UnixMilli()
+	// only the event id supplied by client is deduplicated
+	supplied := r.EventID != ""
 	r.EventReq.SetDefault()
+	clientEventID := ""
+	if supplied {
+		clientEventID = r.EventID
+	}
+
+	if traceID := r.TraceID(); traceID != "" {
+		l = l.WithValues("trace_id", traceID)
+	}
 
 	if r.IsDataPush() {
-		return handleDataPush(ctx, r.Channel, r.Payload.Bytes())
+		return handleDataPush(ctx, r.Channel, r.Payload.Bytes(), r.TraceID())
 	}
 
 	pub := middleware.MustPublisher(ctx)
@@ -72,18 +82,32 @@ func (r *HandleEvent) Output(ctx context.Context) (interface{}, error) {
 		return rsp, nil
 	}
 
-	ctx, err = pub.WithStrategiesByChanAndType(ctx, r.Channel, r.EventType)
-	if err != nil {
-		rsp.Error = statusx.FromErr(err).Key
-		return rsp, nil
-	}
-
 	prj := types.MustProjectFromContext(ctx)
 
 	ctx = types.WithEventID(ctx, r.EventID)
 	ctx = types.WithPublisher(ctx, pub.Publisher)
+	if traceID := r.TraceID(); traceID != "" {
+		ctx = types.WithTraceID(ctx, traceID)
+	}
 
-	rsp.Results = event.OnEvent(ctx, r.Payload.Bytes())
+	rsp.Results, err = event.Dedup(ctx, prj.ProjectID, clientEventID, func() ([]*event.Result, error) {
+		ctx, err := pub.WithStrategiesByChanAndType(ctx, r.Channel, r.EventType)
+		if err != nil {
+			return nil, err
+		}
+		return event.OnEvent(ctx, r.Payload.Bytes()), nil
+	})
+	if err != nil {
+		if err == event.ErrDuplicateEvent {
+			rsp.Results = append([]*event.Result{}, &event.Result{
+				ReturnCode: -1,
+				Error:      err.Error(),
+			})
+			return rsp, nil
+		}
+		rsp.Error = statusx.FromErr(err).Key
+		return rsp, nil
+	}
 	rsp.Timestamp = time.Now().UTC().UnixMilli()
 
 	job.Dispatch(ctx, job.NewEventLogTask(&models.EventLog{
@@ -94,6 +118,8 @@ func (r *HandleEvent) Output(ctx context.Context) (interface{}, error) {
 			PublishedAt:  r.Timestamp,
 			ReceivedAt:   receivedTs,
 			RespondedAt:  time.Now().UTC().UnixMilli(),
+			EventType:    r.EventType,
+			Payload:      event.ReplayablePayload(r.Payload.Bytes()),
 		},
 	}))
 	go metrics.EventMetricsInc(ctx, prj.AccountID.String(), prj.Name, pub.Key, r.EventType)
@@ -108,6 +134,8 @@ type (
 		EventType string `json:"event_type,omitempty"`
 		Payload   string `json:"payload"`
 		Timestamp int64  `json:"timestamp,omitempty"`
+		// EventID optional, the event with id is deduplicated
+		EventID string `json:"event_id,omitempty"`
 	}
 
 	DataPushRsps []*DataPushRsp
@@ -117,7 +145,7 @@ type (
 	}
 )
 
-func handleDataPush(ctx context.Context, ch string, payload []byte) (interface{}, error) {
+func handleDataPush(ctx context.Context, ch string, payload []byte, traceID string) (interface{}, error) {
 	ctx, l := logr.Start(ctx, "api.Event.HandleDataPush")
 	defer l.End()
 
@@ -132,6 +160,9 @@ func handleDataPush(ctx context.Context, ch string, payload []byte) (interface{}
 		return nil, err
 	}
 	prj := types.MustProjectFromContext(ctx)
+	if traceID != "" {
+		ctx = types.WithTraceID(ctx, traceID)
+	}
 	wrapErr := func(i int, err error) *DataPushRsp {
 		return &DataPushRsp{
 			Index: i,
@@ -158,19 +189,33 @@ func handleDataPush(ctx context.Context, ch string, payload []byte) (interface{}
 			rsps = append(rsps, wrapErr(i, err))
 			continue
 		}
-		eventType, eventID := createParamsIfNotExist(v.EventType, "")
+		eventType, eventID := createParamsIfNotExist(v.EventType, v.EventID)
+		receivedTs := time.Now().UTC().UnixMilli()
 		eventResults, err := handleEvent(
 			ctx,
 			prj,
 			pub,
 			eventType,
 			eventID,
+			v.EventID,
 			[]byte(v.Payload),
 		)
 		if err != nil {
 			rsps = append(rsps, wrapErr(i, err))
 			continue
 		}
+		job.Dispatch(ctx, job.NewEventLogTask(&models.EventLog{
+			EventInfo: models.EventInfo{
+				EventID:      eventID,
+				RelProject:   models.RelProject{ProjectID: prj.ProjectID},
+				RelPublisher: models.RelPublisher{PublisherID: pub.PublisherID},
+				PublishedAt:  v.Timestamp,
+				ReceivedAt:   receivedTs,
+				RespondedAt:  time.Now().UTC().UnixMilli(),
+				EventType:    eventType,
+				Payload:      event.ReplayablePayload([]byte(v.Payload)),
+			},
+		}))
 		rsps = append(rsps, &DataPushRsp{
 			Index:   i,
 			Results: eventResults,
@@ -213,20 +258,24 @@ func handleEvent(ctx context.Context,
 	pub *models.Publisher,
 	eventType string,
 	eventID string,
+	clientEventID string,
 	payload []byte) ([]*event.Result, error) {
 	if err := trafficlimit.TrafficLimit(ctx, enums.TRAFFIC_LIMIT_TYPE__EVENT); err != nil {
 		return nil, err
 	}
 
-	res, err := strategy.FilterByProjectAndEvent(ctx, prj.ProjectID, eventType)
+	ctx = types.WithEventID(ctx, eventID)
+	ctx = types.WithPublisher(ctx, pub)
+	ret, err := event.Dedup(ctx, prj.ProjectID, clientEventID, func() ([]*event.Result, error) {
+		res, err := strategy.FilterByProjectAndEvent(ctx, prj.ProjectID, eventType)
+		if err != nil {
+			return nil, err
+		}
+		return event.OnEvent(types.WithStrategyResults(ctx, res), payload), nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	ctx = types.WithStrategyResults(ctx, res)
-
-	ctx = types.WithEventID(ctx, eventID)
-	ctx = types.WithPublisher(ctx, pub)
-	ret := event.OnEvent(ctx, payload)
 	metrics.EventMetricsInc(ctx, prj.AccountID.String(), prj.Name, pub.Key, eventType)
 	return ret, nil
 }
@@ -0,0 +1,46 @@
+package operator
+
+import (
+	"context"
+	"time"
+
+	"github.com/machinefi/w3bstream/cmd/srv-applet-mgr/apis/middleware"
+	"github.com/machinefi/w3bstream/pkg/depends/kit/httptransport/httpx"
+	"github.com/machinefi/w3bstream/pkg/enums"
+	"github.com/machinefi/w3bstream/pkg/errors/status"
+	"github.com/machinefi/w3bstream/pkg/models"
+	"github.com/machinefi/w3bstream/pkg/modules/operator"
+	"github.com/machinefi/w3bstream/pkg/types"
+	"github.com/machinefi/w3bstream/pkg/types/wasm"
+)
+
+// pendingTxsPollInterval the interval of polling pending transactions of
+// operator when rotating key
+const pendingTxsPollInterval = 3 * time.Second
+
+type RotateOperatorKey struct {
+	httpx.MethodPut
+	OperatorID            types.SFID `in:"path" name:"operatorID"`
+	operator.RotateKeyReq `in:"body"`
+}
+
+func (r *RotateOperatorKey) Path() string { return "/data/:operatorID" }
+
+func (r *RotateOperatorKey) Output(ctx context.Context) (interface{}, error) {
+	ca, ok := middleware.MustCurrentAccountFromContext(ctx).CheckRole(enums.ACCOUNT_ROLE__ADMIN)
+	if !ok {
+		return nil, status.NoAdminPermission
+	}
+	ctx = ca.WithAccount(ctx)
+
+	op, err := operator.GetBySFID(ctx, r.OperatorID)
+	if err != nil {
+		return nil, err
+	}
+	ctx = types.WithOperator(ctx, op)
+
+	chains := types.MustChainConfigFromContext(ctx)
+	return nil, operator.RotateKey(ctx, &r.RotateKeyReq, func(ctx context.Context, op *models.Operator) error {
+		return wasm.WaitPendingTxsDrained(ctx, chains, op, pendingTxsPollInterval)
+	})
+}
@@ -13,6 +13,7 @@ func init() {
 	Root.Register(kit.NewRouter(&CreateOperator{}))
 	Root.Register(kit.NewRouter(&RemoveOperator{}))
 	Root.Register(kit.NewRouter(&ListOperator{}))
+	Root.Register(kit.NewRouter(&RotateOperatorKey{}))
 
 	access_key.RouterRegister(Root, enums.ApiGroupOperator, enums.ApiGroupOperatorDesc)
 }
@@ -7,6 +7,7 @@ import (
 
 	"github.com/machinefi/w3bstream/cmd/srv-applet-mgr/apis/middleware"
 	"github.com/machinefi/w3bstream/pkg/depends/kit/httptransport/httpx"
+	"github.com/machinefi/w3bstream/pkg/enums"
 	"github.com/machinefi/w3bstream/pkg/errors/status"
 	"github.com/machinefi/w3bstream/pkg/modules/project"
 	"github.com/machinefi/w3bstream/pkg/types"
@@ -75,3 +76,54 @@ func (r *ListProjectDetail) Output(ctx context.Context) (interface{}, error) {
 	r.AccountID = ca.AccountID
 	return project.ListDetail(types.WithAccount(ctx, &ca.Account), &r.ListReq)
 }
+
+type GetProjectDatabaseIndexes struct {
+	httpx.MethodGet
+}
+
+func (r *GetProjectDatabaseIndexes) Path() string { return "/db/indexes" }
+
+func (r *GetProjectDatabaseIndexes) Output(ctx context.Context) (interface{}, error) {
+	ca, ok := middleware.MustCurrentAccountFromContext(ctx).CheckRole(enums.ACCOUNT_ROLE__ADMIN)
+	if !ok {
+		return nil, status.NoAdminPermission
+	}
+	ctx, err := ca.WithProjectContextByName(ctx, middleware.MustProjectName(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return project.IndexStats(ctx)
+}
+
+type GetProjectKVStats struct {
+	httpx.MethodGet
+}
+
+func (r *GetProjectKVStats) Path() string { return "/kv/stats" }
+
+func (r *GetProjectKVStats) Output(ctx context.Context) (interface{}, error) {
+	ca, ok := middleware.MustCurrentAccountFromContext(ctx).CheckRole(enums.ACCOUNT_ROLE__ADMIN)
+	if !ok {
+		return nil, status.NoAdminPermission
+	}
+	ctx, err := ca.WithProjectContextByName(ctx, middleware.MustProjectName(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return project.KVStats(ctx)
+}
+
+type GetProjectLogStats struct {
+	httpx.MethodGet
+}
+
+func (r *GetProjectLogStats) Path() string { return "/logs/stats" }
+
+func (r *GetProjectLogStats) Output(ctx context.Context) (interface{}, error) {
+	ctx, err := middleware.MustCurrentAccountFromContext(ctx).
+		WithProjectContextByName(ctx, middleware.MustProjectName(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return project.GetLogStats(ctx)
+}
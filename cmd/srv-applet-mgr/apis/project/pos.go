@@ -2,12 +2,18 @@ package project
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
 
 	"github.com/machinefi/w3bstream/cmd/srv-applet-mgr/apis/middleware"
 	"github.com/machinefi/w3bstream/pkg/depends/kit/httptransport/httpx"
+	"github.com/machinefi/w3bstream/pkg/enums"
+	"github.com/machinefi/w3bstream/pkg/errors/status"
 	"github.com/machinefi/w3bstream/pkg/modules/blockchain"
+	"github.com/machinefi/w3bstream/pkg/modules/event"
 	"github.com/machinefi/w3bstream/pkg/modules/metrics"
 	"github.com/machinefi/w3bstream/pkg/modules/project"
+	"github.com/machinefi/w3bstream/pkg/types"
 )
 
 type CreateProject struct {
@@ -36,3 +42,78 @@ func (r *CreateProject) Output(ctx context.Context) (interface{}, error) {
 	rsp.Name, _ = middleware.ProjectNameForDisplay(rsp.Name)
 	return rsp, nil
 }
+
+type ReplayProjectEvents struct {
+	httpx.MethodPost
+	event.ReplayReq
+}
+
+func (r *ReplayProjectEvents) Path() string { return "/events/replay" }
+
+func (r *ReplayProjectEvents) Output(ctx context.Context) (interface{}, error) {
+	ctx, err := middleware.MustCurrentAccountFromContext(ctx).
+		WithProjectContextByName(ctx, middleware.MustProjectName(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return &eventReplayer{ctx: ctx, req: &r.ReplayReq}, nil
+}
+
+// eventReplayer streams replay results as JSON Lines
+type eventReplayer struct {
+	ctx context.Context
+	req *event.ReplayReq
+}
+
+func (r *eventReplayer) Upgrade(rw http.ResponseWriter, _ *http.Request) error {
+	rw.Header().Set(httpx.HeaderContentType, "application/x-ndjson")
+	rw.WriteHeader(http.StatusOK)
+
+	enc := json.NewEncoder(rw)
+	flusher, _ := rw.(http.Flusher)
+	flush := func() {
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	err := event.Replay(r.ctx, r.req, func(res *event.ReplayResult) error {
+		if err := enc.Encode(res); err != nil {
+			return err
+		}
+		flush()
+		return nil
+	})
+	if err != nil {
+		_ = enc.Encode(map[string]string{"error": err.Error()})
+		flush()
+	}
+	return nil
+}
+
+type CreateProjectDatabaseSnapshot struct {
+	httpx.MethodPost
+}
+
+func (r *CreateProjectDatabaseSnapshot) Path() string { return "/db/snapshot" }
+
+func (r *CreateProjectDatabaseSnapshot) Output(ctx context.Context) (interface{}, error) {
+	ca, ok := middleware.MustCurrentAccountFromContext(ctx).CheckRole(enums.ACCOUNT_ROLE__ADMIN)
+	if !ok {
+		return nil, status.NoAdminPermission
+	}
+	ctx, err := ca.WithProjectContextByName(ctx, middleware.MustProjectName(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	rc, err := project.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	prj := types.MustProjectFromContext(ctx)
+	return httpx.Compose(
+		httpx.WrapContentType("application/gzip"),
+		httpx.WrapMeta(httpx.Metadata(httpx.HeaderContentDisposition, "attachment; filename="+prj.DatabaseName()+".sql.gz")),
+	)(rc), nil
+}
@@ -0,0 +1,42 @@
+package project
+
+import (
+	"context"
+
+	"github.com/machinefi/w3bstream/cmd/srv-applet-mgr/apis/middleware"
+	"github.com/machinefi/w3bstream/pkg/depends/kit/httptransport/httpx"
+	"github.com/machinefi/w3bstream/pkg/models"
+	"github.com/machinefi/w3bstream/pkg/modules/project"
+)
+
+type SetProjectLogLevel struct {
+	httpx.MethodPut
+	project.SetLogLevelReq `in:"body"`
+}
+
+func (r *SetProjectLogLevel) Path() string { return "/loglevel" }
+
+func (r *SetProjectLogLevel) Output(ctx context.Context) (interface{}, error) {
+	ctx, err := middleware.MustCurrentAccountFromContext(ctx).
+		WithProjectContextByName(ctx, middleware.MustProjectName(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return project.SetLogLevel(ctx, &r.SetLogLevelReq)
+}
+
+type SetProjectLogRetention struct {
+	httpx.MethodPut
+	models.LogRetentionConfig `in:"body"`
+}
+
+func (r *SetProjectLogRetention) Path() string { return "/logs/retention" }
+
+func (r *SetProjectLogRetention) Output(ctx context.Context) (interface{}, error) {
+	ctx, err := middleware.MustCurrentAccountFromContext(ctx).
+		WithProjectContextByName(ctx, middleware.MustProjectName(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return project.SetLogRetention(ctx, &r.LogRetentionConfig)
+}
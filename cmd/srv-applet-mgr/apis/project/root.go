@@ -16,6 +16,13 @@ func init() {
 	Root.Register(kit.NewRouter(&ListProject{}))
 	Root.Register(kit.NewRouter(&ListProjectDetail{}))
 	Root.Register(kit.NewRouter(&middleware.ProjectProvider{}, &RemoveProject{}))
+	Root.Register(kit.NewRouter(&middleware.ProjectProvider{}, &SetProjectLogLevel{}))
+	Root.Register(kit.NewRouter(&middleware.ProjectProvider{}, &CreateProjectDatabaseSnapshot{}))
+	Root.Register(kit.NewRouter(&middleware.ProjectProvider{}, &GetProjectDatabaseIndexes{}))
+	Root.Register(kit.NewRouter(&middleware.ProjectProvider{}, &GetProjectKVStats{}))
+	Root.Register(kit.NewRouter(&middleware.ProjectProvider{}, &SetProjectLogRetention{}))
+	Root.Register(kit.NewRouter(&middleware.ProjectProvider{}, &GetProjectLogStats{}))
+	Root.Register(kit.NewRouter(&middleware.ProjectProvider{}, &ReplayProjectEvents{}))
 
 	access_key.RouterRegister(Root, enums.ApiGroupProject, enums.ApiGroupProjectDesc)
 }
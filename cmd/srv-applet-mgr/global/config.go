@@ -13,13 +13,16 @@ import (
 	"github.com/machinefi/w3bstream/pkg/depends/conf/filesystem/local"
 	confhttp "github.com/machinefi/w3bstream/pkg/depends/conf/http"
 	confid "github.com/machinefi/w3bstream/pkg/depends/conf/id"
+	confipfs "github.com/machinefi/w3bstream/pkg/depends/conf/ipfs"
 	confjwt "github.com/machinefi/w3bstream/pkg/depends/conf/jwt"
 	conflog "github.com/machinefi/w3bstream/pkg/depends/conf/log"
 	conflogger "github.com/machinefi/w3bstream/pkg/depends/conf/logger"
 	confmqtt "github.com/machinefi/w3bstream/pkg/depends/conf/mqtt"
+	confnats "github.com/machinefi/w3bstream/pkg/depends/conf/nats"
 	confpostgres "github.com/machinefi/w3bstream/pkg/depends/conf/postgres"
 	confrate "github.com/machinefi/w3bstream/pkg/depends/conf/rate_limit"
 	confredis "github.com/machinefi/w3bstream/pkg/depends/conf/redis"
+	confsecret "github.com/machinefi/w3bstream/pkg/depends/conf/secret"
 	conftracer "github.com/machinefi/w3bstream/pkg/depends/conf/tracer"
 	"github.com/machinefi/w3bstream/pkg/depends/kit/httptransport/client"
 	"github.com/machinefi/w3bstream/pkg/depends/kit/kit"
@@ -31,6 +34,7 @@ import (
 	"github.com/machinefi/w3bstream/pkg/models"
 	"github.com/machinefi/w3bstream/pkg/modules/operator/pool"
 	"github.com/machinefi/w3bstream/pkg/modules/vm/wasmapi"
+	"github.com/machinefi/w3bstream/pkg/modules/vm/wasmapi/async"
 	"github.com/machinefi/w3bstream/pkg/types"
 	"github.com/machinefi/w3bstream/pkg/types/wasm/kvdb"
 )
@@ -77,9 +81,15 @@ func init() {
 		AmazonS3      *amazonS3.AmazonS3
 		LocalFS       *local.LocalFileSystem
 		WasmDBConfig  *types.WasmDBConfig
+		WasmRuntime   *types.WasmRuntimeConfig
+		Event         *types.EventConfig
+		WasmLog       *types.WasmLogConfig
 		RateLimit     *confrate.RateLimit
 		MetricsCenter *types.MetricsCenterConfig
 		RobotNotifier *types.RobotNotifierConfig
+		NATS          *confnats.NATSConfig
+		IPFS          *confipfs.IPFSConfig
+		Secret        *confsecret.SecretProviderConfig
 	}{
 		Postgres:      db,
 		MonitorDB:     monitordb,
@@ -99,9 +109,15 @@ func init() {
 		AmazonS3:      &amazonS3.AmazonS3{},
 		LocalFS:       &local.LocalFileSystem{},
 		WasmDBConfig:  &types.WasmDBConfig{},
+		WasmRuntime:   &types.WasmRuntimeConfig{},
+		Event:         &types.EventConfig{},
+		WasmLog:       &types.WasmLogConfig{},
 		RateLimit:     &confrate.RateLimit{},
 		MetricsCenter: &types.MetricsCenterConfig{},
 		RobotNotifier: &types.RobotNotifierConfig{},
+		NATS:          &confnats.NATSConfig{},
+		IPFS:          &confipfs.IPFSConfig{},
+		Secret:        &confsecret.SecretProviderConfig{},
 	}
 
 	name := os.Getenv(consts.EnvProjectName)
@@ -137,8 +153,25 @@ func init() {
 		config.RobotNotifier = nil
 	}
 
+	if config.NATS.IsZero() {
+		config.NATS = nil
+	}
+
+	if config.IPFS.IsZero() {
+		config.IPFS = nil
+	}
+
+	if config.Secret.IsZero() {
+		config.Secret = nil
+	}
+
 	confhttp.RegisterCheckerBy(config, worker)
 
+	// unreachable chains are warned only, some of them may be optional
+	for _, err := range config.ChainConfig.Validate(5 * time.Second) {
+		std.Warn(err)
+	}
+
 	proxy = &client.Client{Port: uint16(ServerEvent.Port), Timeout: 10 * time.Second}
 	proxy.SetDefault()
 
@@ -147,6 +180,7 @@ func init() {
 
 	tb := mq.NewTaskBoard(tasks)
 
+	async.SetMaxConcurrentApiResults(config.WasmRuntime.MaxConcurrentApiResults)
 	wasmApiServer, err := wasmapi.NewServer(std, config.Redis, config.Postgres, redisKvDB, config.ChainConfig, tb, worker, operatorPool)
 	if err != nil {
 		std.Fatal(err)
@@ -170,10 +204,16 @@ func init() {
 		types.WithFileSystemOpContext(fs),
 		types.WithProxyClientContext(proxy),
 		types.WithWasmDBConfigContext(config.WasmDBConfig),
+		types.WithWasmRuntimeConfigContext(config.WasmRuntime),
+		types.WithEventConfigContext(config.Event),
+		types.WithWasmLogConfigContext(config.WasmLog),
 		confrate.WithRateLimitKeyContext(config.RateLimit),
 		kvdb.WithRedisDBKeyContext(redisKvDB),
 		types.WithMetricsCenterConfigContext(config.MetricsCenter),
 		types.WithRobotNotifierConfigContext(config.RobotNotifier),
+		types.WithNATSContext(config.NATS),
+		types.WithIPFSContext(config.IPFS),
+		types.WithSecretProviderContext(config.Secret),
 		types.WithWasmApiServerContext(wasmApiServer),
 		types.WithOperatorPoolContext(operatorPool),
 	)
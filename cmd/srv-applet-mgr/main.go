@@ -14,6 +14,7 @@ import (
 	"github.com/machinefi/w3bstream/pkg/modules/blockchain"
 	"github.com/machinefi/w3bstream/pkg/modules/cronjob"
 	"github.com/machinefi/w3bstream/pkg/modules/deploy"
+	"github.com/machinefi/w3bstream/pkg/modules/job"
 	"github.com/machinefi/w3bstream/pkg/modules/metrics"
 	"github.com/machinefi/w3bstream/pkg/modules/operator"
 	"github.com/machinefi/w3bstream/pkg/modules/project"
@@ -86,6 +87,15 @@ func main() {
 			func() {
 				metrics.Init(ctx)
 			},
+			func() {
+				job.RunWasmLogRotation(ctx)
+			},
+			func() {
+				project.RunIndexStatsRefresh(ctx)
+			},
+			func() {
+				project.RunTableRowsCollection(ctx)
+			},
 		)
 	})
 }
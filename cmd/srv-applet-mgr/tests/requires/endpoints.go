@@ -215,6 +215,15 @@ func init() {
 	}
 	_chainConf.Init()
 
+	_wasmRuntimeConf := &types.WasmRuntimeConfig{}
+	_wasmRuntimeConf.SetDefault()
+
+	_eventConf := &types.EventConfig{}
+	_eventConf.SetDefault()
+
+	_wasmLogConf := &types.WasmLogConfig{}
+	_wasmLogConf.SetDefault()
+
 	redisKvDB := kvdb.NewRedisDB(_redis)
 	operatorPool := pool.NewPool(_dbMgr)
 
@@ -242,6 +251,9 @@ func init() {
 		types.WithTaskBoardContext(tb),
 		types.WithETHClientConfigContext(_ethClients),
 		types.WithChainConfigContext(_chainConf),
+		types.WithWasmRuntimeConfigContext(_wasmRuntimeConf),
+		types.WithEventConfigContext(_eventConf),
+		types.WithWasmLogConfigContext(_wasmLogConf),
 		types.WithWasmApiServerContext(wasmApiServer),
 		types.WithProxyClientContext(&client.Client{}),
 		types.WithOperatorPoolContext(operatorPool),
@@ -37,7 +37,10 @@ func (l *LocalFileSystem) Upload(key string, data []byte) error {
 		err error
 	)
 
-	path := filepath.Join(l.Root, key)
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
 	if isPathExists(path) {
 		return nil
 	}
@@ -55,15 +58,27 @@ func (l *LocalFileSystem) Upload(key string, data []byte) error {
 }
 
 func (l *LocalFileSystem) Read(key string) ([]byte, error) {
-	return os.ReadFile(l.path(key))
+	path, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(path)
 }
 
 func (l *LocalFileSystem) Delete(key string) error {
-	return os.Remove(l.path(key))
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
 }
 
 func (l *LocalFileSystem) StatObject(key string) (*filesystem.ObjectMeta, error) {
-	info, err := os.Stat(l.path(key))
+	path, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
 	if err != nil {
 		return nil, filesystem.ErrNotExistObjectKey
 	}
@@ -77,8 +92,9 @@ func (l *LocalFileSystem) StatObject(key string) (*filesystem.ObjectMeta, error)
 	return om, nil
 }
 
-func (l *LocalFileSystem) path(name string) string {
-	return filepath.Join(l.Root, name)
+// path joins name to root, the name escaping root is rejected
+func (l *LocalFileSystem) path(name string) (string, error) {
+	return filesystem.SafeJoin(l.Root, name)
 }
 
 func isPathExists(path string) bool {
@@ -0,0 +1,18 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SafeJoin joins rel to root and returns error if the cleaned path escapes
+// root, eg: `../../etc/passwd`
+func SafeJoin(root, rel string) (string, error) {
+	base := filepath.Clean(root)
+	path := filepath.Clean(filepath.Join(base, rel))
+	if path != base && !strings.HasPrefix(path, base+string(os.PathSeparator)) {
+		return "", ErrInvalidObjectKey
+	}
+	return path, nil
+}
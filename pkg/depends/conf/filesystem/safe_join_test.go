@@ -0,0 +1,37 @@
+package filesystem_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/machinefi/w3bstream/pkg/depends/conf/filesystem"
+)
+
+func TestSafeJoin(t *testing.T) {
+	cases := []struct {
+		name string
+		root string
+		rel  string
+		path string
+	}{
+		{"Simple", "/tmp/srv", "group/1", "/tmp/srv/group/1"},
+		{"Cleaned", "/tmp/srv/", "group/../group/./1", "/tmp/srv/group/1"},
+		{"AbsoluteRel", "/tmp/srv", "/group/1", "/tmp/srv/group/1"},
+		{"Escape", "/tmp/srv", "../../../etc/passwd", ""},
+		{"EscapeNested", "/tmp/srv", "group/../../etc", ""},
+		{"SiblingPrefix", "/tmp/srv", "../srv2/1", ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			path, err := filesystem.SafeJoin(c.root, c.rel)
+			if c.path == "" {
+				NewWithT(t).Expect(err).To(Equal(filesystem.ErrInvalidObjectKey))
+				return
+			}
+			NewWithT(t).Expect(err).To(BeNil())
+			NewWithT(t).Expect(path).To(Equal(c.path))
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package ipfs
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// MaxDataSize the max size of data added to or read from ipfs
+const MaxDataSize = 1 << 20
+
+// IPFSConfig ipfs http api client, it is not configured if APIEndpoint is empty
+type IPFSConfig struct {
+	// APIEndpoint ipfs node rpc api endpoint, eg: http://127.0.0.1:5001
+	APIEndpoint string `env:""`
+
+	cli *http.Client
+}
+
+func (c *IPFSConfig) IsZero() bool { return c == nil || c.APIEndpoint == "" }
+
+func (c *IPFSConfig) Init() error {
+	if c.IsZero() {
+		return nil
+	}
+	if _, err := url.Parse(c.APIEndpoint); err != nil {
+		return errors.Wrap(err, "parse ipfs api endpoint")
+	}
+	c.cli = &http.Client{Timeout: 30 * time.Second}
+	return nil
+}
+
+func (c *IPFSConfig) Name() string { return "ipfs-cli" }
+
+func (c *IPFSConfig) LivenessCheck() map[string]string {
+	m := map[string]string{}
+	if c.IsZero() {
+		return m
+	}
+	rsp, err := c.call("/api/v0/version", nil, "")
+	if err != nil {
+		m[c.APIEndpoint] = err.Error()
+		return m
+	}
+	rsp.Body.Close()
+	m[c.APIEndpoint] = "ok"
+	return m
+}
+
+// Add uploads data to ipfs and returns its cid
+func (c *IPFSConfig) Add(data []byte) (string, error) {
+	if len(data) > MaxDataSize {
+		return "", errors.Errorf("data size exceeds %d bytes", MaxDataSize)
+	}
+
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	part, err := w.CreateFormFile("file", "data")
+	if err != nil {
+		return "", err
+	}
+	if _, err = part.Write(data); err != nil {
+		return "", err
+	}
+	if err = w.Close(); err != nil {
+		return "", err
+	}
+
+	rsp, err := c.call("/api/v0/add?pin=true", body, w.FormDataContentType())
+	if err != nil {
+		return "", err
+	}
+	defer rsp.Body.Close()
+
+	ret := struct {
+		Hash string `json:"Hash"`
+	}{}
+	if err = json.NewDecoder(rsp.Body).Decode(&ret); err != nil {
+		return "", errors.Wrap(err, "decode ipfs add response")
+	}
+	if ret.Hash == "" {
+		return "", errors.New("empty cid returned")
+	}
+	return ret.Hash, nil
+}
+
+// Cat reads data of cid from ipfs, data larger than MaxDataSize is rejected
+func (c *IPFSConfig) Cat(cid string) ([]byte, error) {
+	if cid == "" {
+		return nil, errors.New("cid is required")
+	}
+
+	rsp, err := c.call("/api/v0/cat?arg="+url.QueryEscape(cid), nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rsp.Body, MaxDataSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxDataSize {
+		return nil, errors.Errorf("data size exceeds %d bytes", MaxDataSize)
+	}
+	return data, nil
+}
+
+// call posts to the ipfs rpc api, the rpc api accepts POST only
+func (c *IPFSConfig) call(path string, body io.Reader, contentType string) (*http.Response, error) {
+	if c.IsZero() || c.cli == nil {
+		return nil, errors.New("ipfs is not configured")
+	}
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(c.APIEndpoint, "/")+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	rsp, err := c.cli.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if rsp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(rsp.Body, 1024))
+		rsp.Body.Close()
+		return nil, errors.Errorf("ipfs api %s: %s %s", path, rsp.Status, msg)
+	}
+	return rsp, nil
+}
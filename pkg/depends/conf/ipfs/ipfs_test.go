@@ -0,0 +1,54 @@
+package ipfs_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/machinefi/w3bstream/pkg/depends/conf/ipfs"
+)
+
+func TestIPFSConfig(t *testing.T) {
+	stored := map[string][]byte{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/add":
+			f, _, err := r.FormFile("file")
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			data, _ := io.ReadAll(f)
+			stored["QmTest"] = data
+			_, _ = w.Write([]byte(`{"Name":"data","Hash":"QmTest","Size":"5"}`))
+		case "/api/v0/cat":
+			data, ok := stored[r.URL.Query().Get("arg")]
+			if !ok {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			_, _ = w.Write(data)
+		}
+	}))
+	defer srv.Close()
+
+	c := &ipfs.IPFSConfig{APIEndpoint: srv.URL}
+	NewWithT(t).Expect(c.Init()).To(BeNil())
+
+	cid, err := c.Add([]byte("hello"))
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(cid).To(Equal("QmTest"))
+
+	data, err := c.Cat(cid)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(data).To(Equal([]byte("hello")))
+
+	_, err = c.Cat("QmUnknown")
+	NewWithT(t).Expect(err).NotTo(BeNil())
+
+	_, err = c.Add(make([]byte, ipfs.MaxDataSize+1))
+	NewWithT(t).Expect(err).NotTo(BeNil())
+}
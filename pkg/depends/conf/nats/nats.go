@@ -0,0 +1,104 @@
+package nats
+
+import (
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+)
+
+// NATSConfig NATS JetStream client, it is not configured if URL is empty
+type NATSConfig struct {
+	URL string `env:""`
+	// Subject the default subject of publishing and the subject of stream
+	Subject string `env:""`
+	// StreamName the stream is created with Subject if it does not exist
+	StreamName string `env:""`
+	// TLSCert client certificate file path
+	TLSCert string `env:""`
+	// TLSKey client private key file path
+	TLSKey string `env:""`
+
+	conn *nats.Conn
+	js   nats.JetStreamContext
+}
+
+func (c *NATSConfig) IsZero() bool { return c == nil || c.URL == "" }
+
+func (c *NATSConfig) Init() error {
+	if c.IsZero() {
+		return nil
+	}
+
+	opts := []nats.Option{
+		nats.Timeout(10 * time.Second),
+		nats.MaxReconnects(-1),
+	}
+	if c.TLSCert != "" || c.TLSKey != "" {
+		opts = append(opts, nats.ClientCert(c.TLSCert, c.TLSKey))
+	}
+	conn, err := nats.Connect(c.URL, opts...)
+	if err != nil {
+		return errors.Wrap(err, "connect nats")
+	}
+	js, err := conn.JetStream()
+	if err != nil {
+		conn.Close()
+		return errors.Wrap(err, "init jetstream")
+	}
+
+	if c.StreamName != "" {
+		_, err = js.StreamInfo(c.StreamName)
+		if errors.Is(err, nats.ErrStreamNotFound) {
+			if c.Subject == "" {
+				conn.Close()
+				return errors.Errorf("subject is required to create stream %s", c.StreamName)
+			}
+			_, err = js.AddStream(&nats.StreamConfig{Name: c.StreamName, Subjects: []string{c.Subject}})
+		}
+		if err != nil {
+			conn.Close()
+			return errors.Wrapf(err, "init stream %s", c.StreamName)
+		}
+	}
+
+	c.conn, c.js = conn, js
+	return nil
+}
+
+func (c *NATSConfig) Name() string { return "nats-cli" }
+
+func (c *NATSConfig) LivenessCheck() map[string]string {
+	m := map[string]string{}
+	if c.IsZero() {
+		return m
+	}
+	if c.conn == nil || !c.conn.IsConnected() {
+		m[c.URL] = "disconnected"
+	} else {
+		m[c.URL] = "ok"
+	}
+	return m
+}
+
+// Publish publishes data to subject by JetStream and waits for the ack, empty
+// subject means the default Subject
+func (c *NATSConfig) Publish(subject string, data []byte) error {
+	if c.IsZero() || c.js == nil {
+		return errors.New("nats is not configured")
+	}
+	if subject == "" {
+		subject = c.Subject
+	}
+	if subject == "" {
+		return errors.New("subject is required")
+	}
+	_, err := c.js.Publish(subject, data)
+	return err
+}
+
+func (c *NATSConfig) Close() {
+	if c.conn != nil {
+		c.conn.Close()
+	}
+}
@@ -165,23 +165,24 @@ func (r *Redis) Init() {
 	}
 }
 
-func (r *Redis) init() {
-	dialer := func() (c redis.Conn, err error) {
-		c, err = redis.Dial(
-			r.Protocol,
-			fmt.Sprintf("%s:%d", r.Host, r.Port),
-
-			redis.DialWriteTimeout(time.Duration(r.WriteTimeout)),
-			redis.DialConnectTimeout(time.Duration(r.ConnectTimeout)),
-			redis.DialReadTimeout(time.Duration(r.ReadTimeout)),
-			redis.DialPassword(r.Password.String()),
-			redis.DialDatabase(r.DB),
-		)
-		return
-	}
+// Dial opens a connection out of the pool, it is used by long-lived
+// connections, eg: subscribing, which should not occupy the pool
+func (r *Redis) Dial() (redis.Conn, error) {
+	return redis.Dial(
+		r.Protocol,
+		fmt.Sprintf("%s:%d", r.Host, r.Port),
+
+		redis.DialWriteTimeout(time.Duration(r.WriteTimeout)),
+		redis.DialConnectTimeout(time.Duration(r.ConnectTimeout)),
+		redis.DialReadTimeout(time.Duration(r.ReadTimeout)),
+		redis.DialPassword(r.Password.String()),
+		redis.DialDatabase(r.DB),
+	)
+}
 
+func (r *Redis) init() {
 	r.pool = &redis.Pool{
-		Dial:        dialer,
+		Dial:        r.Dial,
 		MaxIdle:     r.MaxIdle,
 		MaxActive:   r.MaxActive,
 		IdleTimeout: time.Duration(r.IdleTimeout),
@@ -0,0 +1,139 @@
+package secret
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	vault "github.com/hashicorp/vault/api"
+	"github.com/pkg/errors"
+
+	"github.com/machinefi/w3bstream/pkg/depends/base/types"
+)
+
+// SecretProvider reads secret value by key
+type SecretProvider interface {
+	Get(key string) (string, error)
+}
+
+var ErrSecretNotFound = errors.New("secret not found")
+
+const (
+	// ProviderTypeVault hashicorp vault, key is `path` or `path#field`, the
+	// default field is `value`. kv v2 secrets are unwrapped from `data`
+	ProviderTypeVault = "vault"
+	// ProviderTypeSSM aws ssm parameter store, key is parameter name. the
+	// region and credentials are loaded from aws default config chain
+	ProviderTypeSSM = "ssm"
+)
+
+const defaultVaultField = "value"
+
+// SecretProviderConfig secret provider of wasm, it is not configured if Type
+// is empty
+type SecretProviderConfig struct {
+	// Type provider type, `vault` or `ssm`
+	Type string `env:""`
+	// Endpoint vault address or custom ssm endpoint
+	Endpoint string `env:""`
+	// Token vault token
+	Token types.Password `env:""`
+
+	provider SecretProvider
+}
+
+func (c *SecretProviderConfig) IsZero() bool { return c == nil || c.Type == "" }
+
+func (c *SecretProviderConfig) Init() error {
+	if c.IsZero() {
+		return nil
+	}
+	switch c.Type {
+	case ProviderTypeVault:
+		conf := vault.DefaultConfig()
+		conf.Address = c.Endpoint
+		cli, err := vault.NewClient(conf)
+		if err != nil {
+			return errors.Wrap(err, "new vault client")
+		}
+		cli.SetToken(c.Token.String())
+		c.provider = &vaultProvider{cli: cli}
+	case ProviderTypeSSM:
+		conf := aws.NewConfig()
+		if c.Endpoint != "" {
+			conf = conf.WithEndpoint(c.Endpoint)
+		}
+		sess, err := session.NewSessionWithOptions(session.Options{
+			Config:            *conf,
+			SharedConfigState: session.SharedConfigEnable,
+		})
+		if err != nil {
+			return errors.Wrap(err, "new aws session")
+		}
+		c.provider = &ssmProvider{cli: ssm.New(sess)}
+	default:
+		return errors.Errorf("unknown secret provider type: %s", c.Type)
+	}
+	return nil
+}
+
+func (c *SecretProviderConfig) Name() string { return "secret-provider" }
+
+// Get reads secret by key, it returns ErrSecretNotFound if key not exists
+func (c *SecretProviderConfig) Get(key string) (string, error) {
+	if c.provider == nil {
+		return "", errors.New("secret provider is not initialized")
+	}
+	return c.provider.Get(key)
+}
+
+type vaultProvider struct {
+	cli *vault.Client
+}
+
+func (p *vaultProvider) Get(key string) (string, error) {
+	path, field := key, defaultVaultField
+	if idx := strings.LastIndex(key, "#"); idx >= 0 {
+		path, field = key[:idx], key[idx+1:]
+	}
+
+	s, err := p.cli.Logical().Read(path)
+	if err != nil {
+		return "", err
+	}
+	if s == nil || s.Data == nil {
+		return "", ErrSecretNotFound
+	}
+	data := s.Data
+	if inner, ok := data["data"].(map[string]interface{}); ok {
+		data = inner // kv v2
+	}
+	v, ok := data[field].(string)
+	if !ok {
+		return "", ErrSecretNotFound
+	}
+	return v, nil
+}
+
+type ssmProvider struct {
+	cli *ssm.SSM
+}
+
+func (p *ssmProvider) Get(key string) (string, error) {
+	out, err := p.cli.GetParameter(&ssm.GetParameterInput{
+		Name:           aws.String(key),
+		WithDecryption: aws.Bool(true),
+	})
+	if err != nil {
+		if e, ok := err.(awserr.Error); ok && e.Code() == ssm.ErrCodeParameterNotFound {
+			return "", ErrSecretNotFound
+		}
+		return "", err
+	}
+	if out.Parameter == nil || out.Parameter.Value == nil {
+		return "", ErrSecretNotFound
+	}
+	return *out.Parameter.Value, nil
+}
@@ -0,0 +1,53 @@
+package secret_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/machinefi/w3bstream/pkg/depends/conf/secret"
+)
+
+func TestSecretProviderConfig_Vault(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/app":
+			_, _ = w.Write([]byte(`{"data":{"data":{"value":"v2","api_key":"key"}}}`))
+		case "/v1/kv/app":
+			_, _ = w.Write([]byte(`{"data":{"value":"v1"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c := &secret.SecretProviderConfig{Type: secret.ProviderTypeVault, Endpoint: srv.URL, Token: "root"}
+	NewWithT(t).Expect(c.Init()).To(BeNil())
+
+	v, err := c.Get("secret/data/app")
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(v).To(Equal("v2"))
+
+	v, err = c.Get("secret/data/app#api_key")
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(v).To(Equal("key"))
+
+	v, err = c.Get("kv/app")
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(v).To(Equal("v1"))
+
+	_, err = c.Get("secret/data/app#unknown")
+	NewWithT(t).Expect(err).To(Equal(secret.ErrSecretNotFound))
+
+	_, err = c.Get("kv/unknown")
+	NewWithT(t).Expect(err).To(Equal(secret.ErrSecretNotFound))
+
+	c = &secret.SecretProviderConfig{Type: "unknown"}
+	NewWithT(t).Expect(c.Init()).NotTo(BeNil())
+}
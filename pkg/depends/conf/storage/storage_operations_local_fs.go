@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 
 	"github.com/machinefi/w3bstream/pkg/depends/base/consts"
+	"github.com/machinefi/w3bstream/pkg/depends/conf/filesystem"
 )
 
 type LocalFs struct {
@@ -38,7 +39,10 @@ func (l *LocalFs) Upload(key string, data []byte, chk ...HmacAlgType) error {
 		err error
 	)
 
-	path := filepath.Join(l.Root, key)
+	path, err := filesystem.SafeJoin(l.Root, key)
+	if err != nil {
+		return err
+	}
 	if IsPathExists(path) {
 		return nil
 	}
@@ -56,7 +60,11 @@ func (l *LocalFs) Upload(key string, data []byte, chk ...HmacAlgType) error {
 }
 
 func (l *LocalFs) Read(key string, chk ...HmacAlgType) (data []byte, sum []byte, err error) {
-	data, err = os.ReadFile(filepath.Join(l.Root, key))
+	path, err := filesystem.SafeJoin(l.Root, key)
+	if err != nil {
+		return
+	}
+	data, err = os.ReadFile(path)
 	if err != nil {
 		return
 	}
@@ -69,7 +77,11 @@ func (l *LocalFs) Read(key string, chk ...HmacAlgType) (data []byte, sum []byte,
 }
 
 func (l *LocalFs) Delete(key string) error {
-	return os.Remove(filepath.Join(l.Root, key))
+	path, err := filesystem.SafeJoin(l.Root, key)
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
 }
 
 func IsPathExists(path string) bool {
@@ -11,6 +11,7 @@ import (
 	"github.com/machinefi/w3bstream/pkg/depends/kit/httptransport/transformer"
 	"github.com/machinefi/w3bstream/pkg/depends/kit/metax"
 	"github.com/machinefi/w3bstream/pkg/depends/kit/statusx"
+	vldterr "github.com/machinefi/w3bstream/pkg/depends/kit/validator/errors"
 	"github.com/machinefi/w3bstream/pkg/depends/x/contextx"
 	"github.com/machinefi/w3bstream/pkg/depends/x/typesx"
 )
@@ -127,7 +128,13 @@ func (hdl *RouteHandler) write(rw http.ResponseWriter, r *http.Request, rsp inte
 func (hdl *RouteHandler) writeErr(rw http.ResponseWriter, r *http.Request, err error) {
 	rsp, ok := err.(*httpx.Response)
 	if !ok {
-		rsp = httpx.ResponseFrom(err)
+		var set *vldterr.ErrorSet
+		if errors.As(err, &set) {
+			// encoded by ErrorSet.MarshalJSON as structured field errors
+			rsp = &httpx.Response{Value: set, StatusCode: http.StatusBadRequest}
+		} else {
+			rsp = httpx.ResponseFrom(err)
+		}
 	}
 
 	if se, ok := statusx.IsStatusErr(rsp.Unwrap()); ok {
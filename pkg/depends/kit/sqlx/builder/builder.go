@@ -61,6 +61,8 @@ type Dialect interface {
 	DropColumn(*Column) SqlExpr
 	AddIndex(*Key) SqlExpr
 	DropIndex(*Key) SqlExpr
+	AddCheck(*Column) SqlExpr
+	DropCheck(*Column) SqlExpr
 	DataType(*ColumnType) SqlExpr
 }
 
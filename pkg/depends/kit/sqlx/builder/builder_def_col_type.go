@@ -20,6 +20,24 @@ type ColumnType struct {
 	Desc           []string
 	Rel            []string
 	DeprecatedActs *DeprecatedActs
+	// Check single column CHECK constraint expr, eg: `f_value > 0`
+	Check string
+}
+
+// CheckEqual checks if check constraint is the same as prev's, the expr read
+// from database is normalized with extra brackets, eg: `((f_value > 0))`
+func (ct *ColumnType) CheckEqual(prev *ColumnType) bool {
+	return normalizeCheck(ct.Check) == normalizeCheck(prev.Check)
+}
+
+func normalizeCheck(expr string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '\n', '(', ')':
+			return -1
+		}
+		return r
+	}, strings.ToLower(expr))
 }
 
 func AnalyzeColumnType(t typesx.Type, tag string) *ColumnType {
@@ -14,6 +14,9 @@ func (k Key) On(t *Table) *Key { k.Table = t; return &k }
 
 func (k Key) Using(method string) *Key { k.Method = method; return &k }
 
+// Include assigns non-key columns of covering index
+func (k Key) Include(colNames ...string) *Key { k.Def.Include = colNames; return &k }
+
 func (k *Key) T() *Table { return k.Table }
 
 func (k Key) IsPrimary() bool {
@@ -68,6 +71,8 @@ type IndexDef struct {
 	FieldNames []string
 	ColNames   []string
 	Expr       string
+	// Include non-key column names of covering index, eg: INCLUDE (f_a, f_b)
+	Include []string
 }
 
 func ParseIndexDef(names ...string) *IndexDef {
@@ -111,6 +116,23 @@ func (i IndexDef) TableExpr(t *Table) *Ex {
 	return ex
 }
 
+// IncludeExpr returns INCLUDE clause of covering index, nil if no include columns
+func (i IndexDef) IncludeExpr() *Ex {
+	if len(i.Include) == 0 {
+		return nil
+	}
+	ex := Expr("INCLUDE ")
+	ex.WriteGroup(func(ex *Ex) {
+		ex.WriteQuery(strings.ToLower(strings.Join(i.Include, ", ")))
+	})
+	return ex
+}
+
+// IncludeEqual checks if include columns are the same as other's
+func (i IndexDef) IncludeEqual(other IndexDef) bool {
+	return strings.EqualFold(strings.Join(i.Include, ","), strings.Join(other.Include, ","))
+}
+
 func PrimaryKey(cols *Columns) *Key { return UniqueIndex("PRIMARY", cols) }
 
 func UniqueIndex(name string, cols *Columns, exprs ...string) *Key {
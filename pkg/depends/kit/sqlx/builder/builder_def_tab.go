@@ -32,6 +32,8 @@ type Table struct {
 	Schema    string
 	ModelName string
 	Model     Model
+	// PartitionBy partition clause of partitioned table, eg: RANGE (f_created_at)
+	PartitionBy string
 
 	Columns
 	Keys
@@ -163,6 +165,14 @@ func (t *Table) Diff(prevT *Table, d Dialect) (exprList []SqlExpr) {
 				if currCT != prevCT {
 					exprList = append(exprList, d.ModifyColumn(currC, prevC))
 				}
+				if !currC.CheckEqual(prevC.ColumnType) {
+					if prevC.Check != "" {
+						exprList = append(exprList, d.DropCheck(currC))
+					}
+					if currC.Check != "" {
+						exprList = append(exprList, d.AddCheck(currC))
+					}
+				}
 				return
 			}
 			exprList = append(exprList, d.DropColumn(currC))
@@ -171,6 +181,9 @@ func (t *Table) Diff(prevT *Table, d Dialect) (exprList []SqlExpr) {
 
 		if currC.DeprecatedActs == nil {
 			exprList = append(exprList, d.AddColumn(currC))
+			if currC.Check != "" {
+				exprList = append(exprList, d.AddCheck(currC))
+			}
 		}
 	})
 
@@ -192,7 +205,7 @@ func (t *Table) Diff(prevT *Table, d Dialect) (exprList []SqlExpr) {
 				indexDef := key.Def.TableExpr(key.Table).Ex(context.Background()).Query()
 				prevIndexDef := prevKey.Def.TableExpr(prevKey.Table).Ex(context.Background()).Query()
 
-				if !strings.EqualFold(indexDef, prevIndexDef) {
+				if !strings.EqualFold(indexDef, prevIndexDef) || !key.Def.IncludeEqual(prevKey.Def) {
 					exprList = append(exprList, d.DropIndex(key))
 					exprList = append(exprList, d.AddIndex(key))
 				}
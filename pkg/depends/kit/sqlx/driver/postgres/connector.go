@@ -242,6 +242,11 @@ func (c *Connector) AddIndex(key *builder.Key) builder.SqlExpr {
 	e.WriteQueryByte(' ')
 	e.WriteExpr(key.Def.TableExpr(key.Table))
 
+	if include := key.Def.IncludeExpr(); include != nil {
+		e.WriteQueryByte(' ')
+		e.WriteExpr(include)
+	}
+
 	e.WriteEnd()
 	return e
 }
@@ -292,6 +297,16 @@ func (c *Connector) CreateTableIsNotExists(t *builder.Table) (exprs []builder.Sq
 			e.WriteExpr(c.DataType(col.ColumnType))
 		})
 
+		t.Columns.Range(func(col *builder.Column, idx int) {
+			if col.DeprecatedActs != nil || col.Check == "" {
+				return
+			}
+			e.WriteQueryByte(',')
+			e.WriteQueryByte('\n')
+			e.WriteQueryByte('\t')
+			e.WriteExpr(checkConstraint(col))
+		})
+
 		t.Keys.Range(func(key *builder.Key, idx int) {
 			if key.IsPrimary() {
 				e.WriteQueryByte(',')
@@ -305,6 +320,11 @@ func (c *Connector) CreateTableIsNotExists(t *builder.Table) (exprs []builder.Sq
 		expr.WriteQueryByte('\n')
 	})
 
+	if t.PartitionBy != "" {
+		expr.WriteQuery(" PARTITION BY ")
+		expr.WriteQuery(t.PartitionBy)
+	}
+
 	expr.WriteEnd()
 	exprs = append(exprs, expr)
 
@@ -438,6 +458,31 @@ func (c *Connector) DropColumn(col *builder.Column) builder.SqlExpr {
 	return e
 }
 
+func (c *Connector) AddCheck(col *builder.Column) builder.SqlExpr {
+	e := builder.Expr("ALTER TABLE ")
+	e.WriteExpr(col.Table)
+	e.WriteQuery(" ADD ")
+	e.WriteExpr(checkConstraint(col))
+	e.WriteEnd()
+	return e
+}
+
+func (c *Connector) DropCheck(col *builder.Column) builder.SqlExpr {
+	e := builder.Expr("ALTER TABLE ")
+	e.WriteExpr(col.Table)
+	e.WriteQuery(" DROP CONSTRAINT IF EXISTS ")
+	e.WriteQuery(checkConstraintName(col.Name))
+	e.WriteEnd()
+	return e
+}
+
+// checkConstraint returns `CONSTRAINT chk_<col> CHECK (<expr>)`
+func checkConstraint(col *builder.Column) builder.SqlExpr {
+	return builder.Expr("CONSTRAINT " + checkConstraintName(col.Name) + " CHECK (" + col.Check + ")")
+}
+
+func checkConstraintName(colName string) string { return "chk_" + colName }
+
 func (c *Connector) DataType(columnType *builder.ColumnType) builder.SqlExpr {
 	dbDataType := dealias(c.dbDataType(columnType.Type, columnType))
 	return builder.Expr(dbDataType +
@@ -25,7 +25,15 @@ func TestConnector(t *testing.T) {
 		builder.UniqueIndex("I_name", builder.Cols("F_name")).Using("BTREE"),
 		builder.Index("I_created_at", builder.Cols("F_created_at")).Using("BTREE"),
 		builder.Index("I_geo", builder.Cols("F_geo")).Using("SPATIAL"),
+		builder.Index("I_name_covering", builder.Cols("F_name")).Using("BTREE").Include("F_created_at", "F_updated_at"),
 	)
+	table.Col("F_created_at").Check = "f_created_at >= 0"
+
+	partitioned := builder.T("t_metrics",
+		builder.Col("f_value").Type(int64(0), ""),
+		builder.Col("f_created_at").Type(int64(0), ""),
+	)
+	partitioned.PartitionBy = "RANGE (f_created_at)"
 
 	cases := map[string]struct {
 		expr   builder.SqlExpr
@@ -43,6 +51,10 @@ func TestConnector(t *testing.T) {
 			c.AddIndex(table.Key("I_name")),
 			builder.Expr( /* language=PostgreSQL */ "CREATE UNIQUE INDEX t_i_name ON t USING BTREE (f_name);"),
 		},
+		"AddCoveringIndex": {
+			c.AddIndex(table.Key("I_name_covering")),
+			builder.Expr( /* language=PostgreSQL */ "CREATE INDEX t_i_name_covering ON t USING BTREE (f_name) INCLUDE (f_created_at, f_updated_at);"),
+		},
 		"AddPrimaryKey": {
 			c.AddIndex(table.Key("PRIMARY")),
 			builder.Expr( /* language=PostgreSQL */ "ALTER TABLE t ADD PRIMARY KEY (f_id);"),
@@ -67,8 +79,16 @@ func TestConnector(t *testing.T) {
 	f_geo POINT NOT NULL,
 	f_created_at bigint NOT NULL DEFAULT '0',
 	f_updated_at bigint NOT NULL DEFAULT '0',
+	CONSTRAINT chk_f_created_at CHECK (f_created_at >= 0),
 	PRIMARY KEY (f_id)
 );`),
+		},
+		"CreatePartitionedTable": {
+			c.CreateTableIsNotExists(partitioned)[0],
+			builder.Expr( /* language=PostgreSQL */ `CREATE TABLE IF NOT EXISTS t_metrics (
+	f_value bigint NOT NULL,
+	f_created_at bigint NOT NULL
+) PARTITION BY RANGE (f_created_at);`),
 		},
 		"DropTable": {
 			c.DropTable(table),
@@ -82,6 +102,14 @@ func TestConnector(t *testing.T) {
 			c.AddColumn(table.Col("F_name")),
 			builder.Expr( /* language=PostgreSQL */ "ALTER TABLE t ADD COLUMN f_name varchar(128) NOT NULL DEFAULT '';"),
 		},
+		"AddCheck": {
+			c.AddCheck(table.Col("F_created_at")),
+			builder.Expr( /* language=PostgreSQL */ "ALTER TABLE t ADD CONSTRAINT chk_f_created_at CHECK (f_created_at >= 0);"),
+		},
+		"DropCheck": {
+			c.DropCheck(table.Col("F_created_at")),
+			builder.Expr( /* language=PostgreSQL */ "ALTER TABLE t DROP CONSTRAINT IF EXISTS chk_f_created_at;"),
+		},
 		"DropColumn": {
 			c.DropColumn(table.Col("F_name")),
 			builder.Expr( /* language=PostgreSQL */ "ALTER TABLE t DROP COLUMN f_name;"),
@@ -80,21 +80,58 @@ func databaseFromSchema(db sqlx.DBExecutor) (*sqlx.Database, error) {
 
 		for _, index := range indexes {
 			table := d.Table(index.TABLE_NAME)
+			expr, include := splitIndexInclude(strings.TrimSpace(regexpUsing.Split(index.INDEX_DEF, 2)[1]))
 			key := &builder.Key{
 				Name:     strings.ToLower(index.INDEX_NAME[len(table.Name)+1:]),
 				Method:   strings.ToUpper(regexpUsing.FindString(index.INDEX_DEF)[6:]),
 				IsUnique: strings.Contains(index.INDEX_DEF, "UNIQUE"),
 				Def: builder.IndexDef{
-					Expr: strings.TrimSpace(regexpUsing.Split(index.INDEX_DEF, 2)[1]),
+					Expr:    expr,
+					Include: include,
 				},
 			}
 			table.AddKey(key)
 		}
+
+		checks := make([]CheckSchema, 0)
+		if len(tableNames) > 0 {
+			err = db.QueryAndScan(builder.Expr(checkSchemaQuery, schema, tableNames), &checks)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		for _, check := range checks {
+			if !strings.HasPrefix(check.CHECK_NAME, "chk_") {
+				continue
+			}
+			table := d.Table(check.TABLE_NAME)
+			if table == nil {
+				continue
+			}
+			if col := table.Col(strings.TrimPrefix(check.CHECK_NAME, "chk_")); col != nil {
+				col.Check = strings.TrimPrefix(check.CHECK_DEF, "CHECK ")
+			}
+		}
 	}
 
 	return d, nil
 }
 
+// splitIndexInclude splits index expr from pg_indexes, eg: `(f_a) INCLUDE (f_b, f_c)`
+// to key expr `(f_a)` and include column names [f_b, f_c]
+func splitIndexInclude(def string) (string, []string) {
+	parts := strings.SplitN(def, " INCLUDE ", 2)
+	if len(parts) != 2 {
+		return def, nil
+	}
+	var include []string
+	for _, name := range strings.Split(strings.Trim(strings.TrimSpace(parts[1]), "()"), ",") {
+		include = append(include, strings.TrimSpace(name))
+	}
+	return strings.TrimSpace(parts[0]), include
+}
+
 func colFromSchema(columnSchema *ColumnSchema) *builder.Column {
 	col := builder.Col(columnSchema.COLUMN_NAME)
 
@@ -161,6 +198,19 @@ type IndexSchema struct {
 
 func (IndexSchema) TableName() string { return "pg_indexes" }
 
+// CheckSchema check constraint from pg_constraint
+type CheckSchema struct {
+	TABLE_NAME string `db:"tablename"`
+	CHECK_NAME string `db:"conname"`
+	CHECK_DEF  string `db:"condef"`
+}
+
+const checkSchemaQuery = `SELECT c.relname AS tablename, con.conname AS conname, pg_get_constraintdef(con.oid) AS condef
+FROM pg_constraint con
+JOIN pg_class c ON c.oid = con.conrelid
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE con.contype = 'c' AND n.nspname = ? AND c.relname IN (?)`
+
 var SchemaDB = sqlx.NewDatabase("INFORMATION_SCHEMA")
 
 func init() {
@@ -2,6 +2,7 @@ package errors
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 
 	"github.com/machinefi/w3bstream/pkg/depends/kit/statusx"
@@ -91,6 +92,27 @@ func (set *ErrorSet) ToErrorFields() statusx.ErrorFields {
 	return errorFields
 }
 
+// MarshalJSON encodes the flattened errors as an array of statusx.ErrorField,
+// the field path includes the location if it is not leading
+func (set *ErrorSet) MarshalJSON() ([]byte, error) {
+	errorFields := make(statusx.ErrorFields, 0)
+
+	set.Flatten().Each(func(fieldErr *FieldError) {
+		f := &statusx.ErrorField{
+			Field: fieldErr.Field.String(),
+			Msg:   fieldErr.Error.Error(),
+		}
+		if len(fieldErr.Field) > 0 {
+			if l, ok := fieldErr.Field[0].(Location); ok {
+				f.In, f.Field = string(l), fieldErr.Field[1:].String()
+			}
+		}
+		errorFields = append(errorFields, f)
+	})
+
+	return json.Marshal(errorFields)
+}
+
 type FieldError struct {
 	Field KeyPath
 	Error error `json:"msg"`
@@ -0,0 +1,33 @@
+package errors_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/machinefi/w3bstream/pkg/depends/kit/statusx"
+	vldterr "github.com/machinefi/w3bstream/pkg/depends/kit/validator/errors"
+)
+
+func TestErrorSet_MarshalJSON(t *testing.T) {
+	sub := vldterr.NewErrorSet()
+	sub.AddErr(errors.New("required"), "name")
+	sub.AddErr(errors.New("out of range"), "items", 1)
+
+	set := vldterr.NewErrorSet()
+	set.AddErr(sub, vldterr.Location("body"))
+	set.AddErr(errors.New("invalid"), "id")
+
+	data, err := json.Marshal(set)
+	NewWithT(t).Expect(err).To(BeNil())
+
+	fields := statusx.ErrorFields{}
+	NewWithT(t).Expect(json.Unmarshal(data, &fields)).To(BeNil())
+	NewWithT(t).Expect(fields).To(Equal(statusx.ErrorFields{
+		{In: "body", Field: "name", Msg: "required"},
+		{In: "body", Field: "items[1]", Msg: "out of range"},
+		{Field: "id", Msg: "invalid"},
+	}))
+}
@@ -0,0 +1,13 @@
+package enums
+
+// StrategyMatchMode presents how strategy event type matches the type of event
+//
+//go:generate toolkit gen enum StrategyMatchMode
+type StrategyMatchMode uint8
+
+const (
+	STRATEGY_MATCH_MODE_UNKNOWN StrategyMatchMode = iota
+	STRATEGY_MATCH_MODE__EXACT                    // event type equals
+	STRATEGY_MATCH_MODE__PREFIX                   // event type has prefix
+	STRATEGY_MATCH_MODE__REGEX                    // event type matches regular expression
+)
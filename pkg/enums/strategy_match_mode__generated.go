@@ -0,0 +1,127 @@
+// This is a generated source file. DO NOT EDIT
+// Source: enums/strategy_match_mode__generated.go
+
+package enums
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+
+	"github.com/machinefi/w3bstream/pkg/depends/kit/enum"
+)
+
+var InvalidStrategyMatchMode = errors.New("invalid StrategyMatchMode type")
+
+func ParseStrategyMatchModeFromString(s string) (StrategyMatchMode, error) {
+	switch s {
+	default:
+		return STRATEGY_MATCH_MODE_UNKNOWN, InvalidStrategyMatchMode
+	case "":
+		return STRATEGY_MATCH_MODE_UNKNOWN, nil
+	case "EXACT":
+		return STRATEGY_MATCH_MODE__EXACT, nil
+	case "PREFIX":
+		return STRATEGY_MATCH_MODE__PREFIX, nil
+	case "REGEX":
+		return STRATEGY_MATCH_MODE__REGEX, nil
+	}
+}
+
+func ParseStrategyMatchModeFromLabel(s string) (StrategyMatchMode, error) {
+	switch s {
+	default:
+		return STRATEGY_MATCH_MODE_UNKNOWN, InvalidStrategyMatchMode
+	case "":
+		return STRATEGY_MATCH_MODE_UNKNOWN, nil
+	case "event type equals":
+		return STRATEGY_MATCH_MODE__EXACT, nil
+	case "event type has prefix":
+		return STRATEGY_MATCH_MODE__PREFIX, nil
+	case "event type matches regular expression":
+		return STRATEGY_MATCH_MODE__REGEX, nil
+	}
+}
+
+func (v StrategyMatchMode) Int() int {
+	return int(v)
+}
+
+func (v StrategyMatchMode) String() string {
+	switch v {
+	default:
+		return "UNKNOWN"
+	case STRATEGY_MATCH_MODE_UNKNOWN:
+		return ""
+	case STRATEGY_MATCH_MODE__EXACT:
+		return "EXACT"
+	case STRATEGY_MATCH_MODE__PREFIX:
+		return "PREFIX"
+	case STRATEGY_MATCH_MODE__REGEX:
+		return "REGEX"
+	}
+}
+
+func (v StrategyMatchMode) Label() string {
+	switch v {
+	default:
+		return "UNKNOWN"
+	case STRATEGY_MATCH_MODE_UNKNOWN:
+		return ""
+	case STRATEGY_MATCH_MODE__EXACT:
+		return "event type equals"
+	case STRATEGY_MATCH_MODE__PREFIX:
+		return "event type has prefix"
+	case STRATEGY_MATCH_MODE__REGEX:
+		return "event type matches regular expression"
+	}
+}
+
+func (v StrategyMatchMode) TypeName() string {
+	return "github.com/machinefi/w3bstream/pkg/enums.StrategyMatchMode"
+}
+
+func (v StrategyMatchMode) ConstValues() []enum.IntStringerEnum {
+	return []enum.IntStringerEnum{STRATEGY_MATCH_MODE__EXACT, STRATEGY_MATCH_MODE__PREFIX, STRATEGY_MATCH_MODE__REGEX}
+}
+
+func (v StrategyMatchMode) MarshalText() ([]byte, error) {
+	s := v.String()
+	if s == "UNKNOWN" {
+		return nil, InvalidStrategyMatchMode
+	}
+	return []byte(s), nil
+}
+
+func (v *StrategyMatchMode) UnmarshalText(data []byte) error {
+	s := string(bytes.ToUpper(data))
+	val, err := ParseStrategyMatchModeFromString(s)
+	if err != nil {
+		return err
+	}
+	*(v) = val
+	return nil
+}
+
+func (v *StrategyMatchMode) Scan(src interface{}) error {
+	offset := 0
+	o, ok := interface{}(v).(enum.ValueOffset)
+	if ok {
+		offset = o.Offset()
+	}
+	i, err := enum.ScanIntEnumStringer(src, offset)
+	if err != nil {
+		return err
+	}
+	*(v) = StrategyMatchMode(i)
+	return nil
+}
+
+func (v StrategyMatchMode) Value() (driver.Value, error) {
+	offset := 0
+	o, ok := interface{}(v).(enum.ValueOffset)
+	if ok {
+		offset = o.Offset()
+	}
+	return int64(v) + int64(offset), nil
+}
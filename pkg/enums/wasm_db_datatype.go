@@ -22,4 +22,6 @@ const (
 	WASM_DB_DATATYPE__TIMESTAMP // use epoch timestamp (integer, UTC)
 	WASM_DB_DATATYPE__DECIMAL
 	WASM_DB_DATATYPE__NUMERIC
+	WASM_DB_DATATYPE__TIMESTAMPTZ // timestamp with time zone (RFC3339)
+	WASM_DB_DATATYPE__TSVECTOR    // full-text search document
 )
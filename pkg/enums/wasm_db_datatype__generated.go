@@ -53,6 +53,10 @@ func ParseWasmDBDatatypeFromString(s string) (WasmDBDatatype, error) {
 		return WASM_DB_DATATYPE__DECIMAL, nil
 	case "NUMERIC":
 		return WASM_DB_DATATYPE__NUMERIC, nil
+	case "TIMESTAMPTZ":
+		return WASM_DB_DATATYPE__TIMESTAMPTZ, nil
+	case "TSVECTOR":
+		return WASM_DB_DATATYPE__TSVECTOR, nil
 	}
 }
 
@@ -96,6 +100,10 @@ func ParseWasmDBDatatypeFromLabel(s string) (WasmDBDatatype, error) {
 		return WASM_DB_DATATYPE__DECIMAL, nil
 	case "NUMERIC":
 		return WASM_DB_DATATYPE__NUMERIC, nil
+	case "timestamp with time zone (RFC3339)":
+		return WASM_DB_DATATYPE__TIMESTAMPTZ, nil
+	case "full-text search document":
+		return WASM_DB_DATATYPE__TSVECTOR, nil
 	}
 }
 
@@ -143,6 +151,10 @@ func (v WasmDBDatatype) String() string {
 		return "DECIMAL"
 	case WASM_DB_DATATYPE__NUMERIC:
 		return "NUMERIC"
+	case WASM_DB_DATATYPE__TIMESTAMPTZ:
+		return "TIMESTAMPTZ"
+	case WASM_DB_DATATYPE__TSVECTOR:
+		return "TSVECTOR"
 	}
 }
 
@@ -186,6 +198,10 @@ func (v WasmDBDatatype) Label() string {
 		return "DECIMAL"
 	case WASM_DB_DATATYPE__NUMERIC:
 		return "NUMERIC"
+	case WASM_DB_DATATYPE__TIMESTAMPTZ:
+		return "timestamp with time zone (RFC3339)"
+	case WASM_DB_DATATYPE__TSVECTOR:
+		return "full-text search document"
 	}
 }
 
@@ -194,7 +210,7 @@ func (v WasmDBDatatype) TypeName() string {
 }
 
 func (v WasmDBDatatype) ConstValues() []enum.IntStringerEnum {
-	return []enum.IntStringerEnum{WASM_DB_DATATYPE__INT, WASM_DB_DATATYPE__INT8, WASM_DB_DATATYPE__INT16, WASM_DB_DATATYPE__INT32, WASM_DB_DATATYPE__INT64, WASM_DB_DATATYPE__UINT, WASM_DB_DATATYPE__UINT8, WASM_DB_DATATYPE__UINT16, WASM_DB_DATATYPE__UINT32, WASM_DB_DATATYPE__UINT64, WASM_DB_DATATYPE__FLOAT32, WASM_DB_DATATYPE__FLOAT64, WASM_DB_DATATYPE__TEXT, WASM_DB_DATATYPE__BOOL, WASM_DB_DATATYPE__TIMESTAMP, WASM_DB_DATATYPE__DECIMAL, WASM_DB_DATATYPE__NUMERIC}
+	return []enum.IntStringerEnum{WASM_DB_DATATYPE__INT, WASM_DB_DATATYPE__INT8, WASM_DB_DATATYPE__INT16, WASM_DB_DATATYPE__INT32, WASM_DB_DATATYPE__INT64, WASM_DB_DATATYPE__UINT, WASM_DB_DATATYPE__UINT8, WASM_DB_DATATYPE__UINT16, WASM_DB_DATATYPE__UINT32, WASM_DB_DATATYPE__UINT64, WASM_DB_DATATYPE__FLOAT32, WASM_DB_DATATYPE__FLOAT64, WASM_DB_DATATYPE__TEXT, WASM_DB_DATATYPE__BOOL, WASM_DB_DATATYPE__TIMESTAMP, WASM_DB_DATATYPE__DECIMAL, WASM_DB_DATATYPE__NUMERIC, WASM_DB_DATATYPE__TIMESTAMPTZ, WASM_DB_DATATYPE__TSVECTOR}
 }
 
 func (v WasmDBDatatype) MarshalText() ([]byte, error) {
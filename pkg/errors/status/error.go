@@ -106,6 +106,10 @@ const (
 	AccessKeyExpired
 	// @errTalk Access Key Permission Denied
 	AccessKeyPermissionDenied
+	// @errTalk Unsupported File Type
+	UnsupportedFileType
+	// @errTalk Upload File Sha256 Unmatched
+	UploadFileSha256Unmatched
 )
 
 const (
@@ -149,6 +153,8 @@ const (
 	ProjectOperatorConflict
 	// @errTalk Access Key Name Conflict
 	AccessKeyNameConflict
+	// @errTalk Operator Pending Transactions Not Drained
+	OperatorPendingTxsNotDrained
 )
 
 const (
@@ -226,3 +232,8 @@ const (
 	// @errTalk Account Key Not Found
 	AccessKeyNotFound
 )
+
+const (
+	// service unavailable
+	ServiceUnavailable Error = http.StatusServiceUnavailable*1e6 + iota + 1
+)
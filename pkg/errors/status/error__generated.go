@@ -121,6 +121,10 @@ func (v Error) Key() string {
 		return "AccessKeyExpired"
 	case AccessKeyPermissionDenied:
 		return "AccessKeyPermissionDenied"
+	case UnsupportedFileType:
+		return "UnsupportedFileType"
+	case UploadFileSha256Unmatched:
+		return "UploadFileSha256Unmatched"
 	case NotFound:
 		return "NotFound"
 	case ProjectNotFound:
@@ -205,6 +209,8 @@ func (v Error) Key() string {
 		return "ProjectOperatorConflict"
 	case AccessKeyNameConflict:
 		return "AccessKeyNameConflict"
+	case OperatorPendingTxsNotDrained:
+		return "OperatorPendingTxsNotDrained"
 	case InternalServerError:
 		return "InternalServerError"
 	case DatabaseError:
@@ -237,6 +243,8 @@ func (v Error) Key() string {
 		return "CreateTrafficSchedulerFailed"
 	case UpdateTrafficSchedulerFailed:
 		return "UpdateTrafficSchedulerFailed"
+	case ServiceUnavailable:
+		return "ServiceUnavailable"
 	}
 	return "UNKNOWN"
 }
@@ -327,6 +335,10 @@ func (v Error) Msg() string {
 		return "Account Access Key Expired"
 	case AccessKeyPermissionDenied:
 		return "Access Key Permission Denied"
+	case UnsupportedFileType:
+		return "Unsupported File Type"
+	case UploadFileSha256Unmatched:
+		return "Upload File Sha256 Unmatched"
 	case NotFound:
 		return "NotFound"
 	case ProjectNotFound:
@@ -411,6 +423,8 @@ func (v Error) Msg() string {
 		return "Project Operator relationship Conflict"
 	case AccessKeyNameConflict:
 		return "Access Key Name Conflict"
+	case OperatorPendingTxsNotDrained:
+		return "Operator Pending Transactions Not Drained"
 	case InternalServerError:
 		return "internal error"
 	case DatabaseError:
@@ -443,6 +457,8 @@ func (v Error) Msg() string {
 		return "Create Traffic Scheduler Failed"
 	case UpdateTrafficSchedulerFailed:
 		return "Update Traffic Scheduler Failed"
+	case ServiceUnavailable:
+		return "service unavailable"
 	}
 	return "-"
 }
@@ -533,6 +549,10 @@ func (v Error) CanBeTalk() bool {
 		return true
 	case AccessKeyPermissionDenied:
 		return true
+	case UnsupportedFileType:
+		return true
+	case UploadFileSha256Unmatched:
+		return true
 	case NotFound:
 		return true
 	case ProjectNotFound:
@@ -617,6 +637,8 @@ func (v Error) CanBeTalk() bool {
 		return true
 	case AccessKeyNameConflict:
 		return true
+	case OperatorPendingTxsNotDrained:
+		return true
 	case InternalServerError:
 		return false
 	case DatabaseError:
@@ -649,6 +671,8 @@ func (v Error) CanBeTalk() bool {
 		return true
 	case UpdateTrafficSchedulerFailed:
 		return true
+	case ServiceUnavailable:
+		return false
 	}
 	return false
 }
@@ -26,4 +26,8 @@ type EventInfo struct {
 	ReceivedAt int64 `db:"f_received_at" json:"receivedAt"`
 	// RespondedAt the timestamp when event handled and send response
 	RespondedAt int64 `db:"f_responded_at" json:"respondedAt"`
+	// EventType the event type used for filtering strategies
+	EventType string `db:"f_event_type,default=''" json:"eventType"`
+	// Payload event payload, it is kept for replaying event
+	Payload []byte `db:"f_payload,default=''" json:"-"`
 }
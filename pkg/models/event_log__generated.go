@@ -41,6 +41,8 @@ func (*EventLog) TableDesc() []string {
 
 func (*EventLog) Comments() map[string]string {
 	return map[string]string{
+		"EventType":   "EventType the event type used for filtering strategies",
+		"Payload":     "Payload event payload, it is kept for replaying event",
 		"PublishedAt": "PublishedAt the timestamp when device publish event",
 		"ReceivedAt":  "ReceivedAt the timestamp when event received by us",
 		"RespondedAt": "RespondedAt the timestamp when event handled and send response",
@@ -49,6 +51,12 @@ func (*EventLog) Comments() map[string]string {
 
 func (*EventLog) ColDesc() map[string][]string {
 	return map[string][]string{
+		"EventType": []string{
+			"EventType the event type used for filtering strategies",
+		},
+		"Payload": []string{
+			"Payload event payload, it is kept for replaying event",
+		},
 		"PublishedAt": []string{
 			"PublishedAt the timestamp when device publish event",
 		},
@@ -153,6 +161,22 @@ func (*EventLog) FieldRespondedAt() string {
 	return "RespondedAt"
 }
 
+func (m *EventLog) ColEventType() *builder.Column {
+	return EventLogTable.ColByFieldName(m.FieldEventType())
+}
+
+func (*EventLog) FieldEventType() string {
+	return "EventType"
+}
+
+func (m *EventLog) ColPayload() *builder.Column {
+	return EventLogTable.ColByFieldName(m.FieldPayload())
+}
+
+func (*EventLog) FieldPayload() string {
+	return "Payload"
+}
+
 func (m *EventLog) ColCreatedAt() *builder.Column {
 	return EventLogTable.ColByFieldName(m.FieldCreatedAt())
 }
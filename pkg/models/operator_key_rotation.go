@@ -0,0 +1,30 @@
+package models
+
+import (
+	"github.com/machinefi/w3bstream/pkg/depends/base/types"
+	"github.com/machinefi/w3bstream/pkg/depends/kit/sqlx/datatypes"
+)
+
+// OperatorKeyRotation records of operator key rotation
+// @def primary                                 ID
+// @def unique_index UI_operator_key_rotation_id OperatorKeyRotationID
+// @def index        I_operator_id               OperatorID
+//
+//go:generate toolkit gen model OperatorKeyRotation --database DB
+type OperatorKeyRotation struct {
+	datatypes.PrimaryID
+	RelOperatorKeyRotation
+	RelOperator
+	OperatorKeyRotationInfo
+	datatypes.OperationTimes
+}
+
+type RelOperatorKeyRotation struct {
+	OperatorKeyRotationID types.SFID `db:"f_operator_key_rotation_id" json:"operatorKeyRotationID"`
+}
+
+type OperatorKeyRotationInfo struct {
+	OperatorName string          `db:"f_operator_name"            json:"operatorName"`
+	RotatedBy    types.SFID      `db:"f_rotated_by"               json:"rotatedBy"`
+	RotatedAt    types.Timestamp `db:"f_rotated_at,default='0'"   json:"rotatedAt"`
+}
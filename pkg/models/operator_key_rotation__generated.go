@@ -0,0 +1,345 @@
+// This is a generated source file. DO NOT EDIT
+// Source: models/operator_key_rotation__generated.go
+
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/machinefi/w3bstream/pkg/depends/base/types"
+	"github.com/machinefi/w3bstream/pkg/depends/kit/sqlx"
+	"github.com/machinefi/w3bstream/pkg/depends/kit/sqlx/builder"
+)
+
+var OperatorKeyRotationTable *builder.Table
+
+func init() {
+	OperatorKeyRotationTable = DB.Register(&OperatorKeyRotation{})
+}
+
+type OperatorKeyRotationIterator struct {
+}
+
+func (*OperatorKeyRotationIterator) New() interface{} {
+	return &OperatorKeyRotation{}
+}
+
+func (*OperatorKeyRotationIterator) Resolve(v interface{}) *OperatorKeyRotation {
+	return v.(*OperatorKeyRotation)
+}
+
+func (*OperatorKeyRotation) TableName() string {
+	return "t_operator_key_rotation"
+}
+
+func (*OperatorKeyRotation) TableDesc() []string {
+	return []string{
+		"OperatorKeyRotation records of operator key rotation",
+	}
+}
+
+func (*OperatorKeyRotation) Comments() map[string]string {
+	return map[string]string{}
+}
+
+func (*OperatorKeyRotation) ColDesc() map[string][]string {
+	return map[string][]string{}
+}
+
+func (*OperatorKeyRotation) ColRel() map[string][]string {
+	return map[string][]string{}
+}
+
+func (*OperatorKeyRotation) PrimaryKey() []string {
+	return []string{
+		"ID",
+	}
+}
+
+func (*OperatorKeyRotation) Indexes() builder.Indexes {
+	return builder.Indexes{
+		"i_operator_id": []string{
+			"OperatorID",
+		},
+	}
+}
+
+func (m *OperatorKeyRotation) IndexFieldNames() []string {
+	return []string{
+		"ID",
+		"OperatorKeyRotationID",
+		"OperatorID",
+	}
+}
+
+func (*OperatorKeyRotation) UniqueIndexes() builder.Indexes {
+	return builder.Indexes{
+		"ui_operator_key_rotation_id": []string{
+			"OperatorKeyRotationID",
+		},
+	}
+}
+
+func (*OperatorKeyRotation) UniqueIndexUIOperatorKeyRotationID() string {
+	return "ui_operator_key_rotation_id"
+}
+
+func (m *OperatorKeyRotation) ColID() *builder.Column {
+	return OperatorKeyRotationTable.ColByFieldName(m.FieldID())
+}
+
+func (*OperatorKeyRotation) FieldID() string {
+	return "ID"
+}
+
+func (m *OperatorKeyRotation) ColOperatorKeyRotationID() *builder.Column {
+	return OperatorKeyRotationTable.ColByFieldName(m.FieldOperatorKeyRotationID())
+}
+
+func (*OperatorKeyRotation) FieldOperatorKeyRotationID() string {
+	return "OperatorKeyRotationID"
+}
+
+func (m *OperatorKeyRotation) ColOperatorID() *builder.Column {
+	return OperatorKeyRotationTable.ColByFieldName(m.FieldOperatorID())
+}
+
+func (*OperatorKeyRotation) FieldOperatorID() string {
+	return "OperatorID"
+}
+
+func (m *OperatorKeyRotation) ColOperatorName() *builder.Column {
+	return OperatorKeyRotationTable.ColByFieldName(m.FieldOperatorName())
+}
+
+func (*OperatorKeyRotation) FieldOperatorName() string {
+	return "OperatorName"
+}
+
+func (m *OperatorKeyRotation) ColRotatedBy() *builder.Column {
+	return OperatorKeyRotationTable.ColByFieldName(m.FieldRotatedBy())
+}
+
+func (*OperatorKeyRotation) FieldRotatedBy() string {
+	return "RotatedBy"
+}
+
+func (m *OperatorKeyRotation) ColRotatedAt() *builder.Column {
+	return OperatorKeyRotationTable.ColByFieldName(m.FieldRotatedAt())
+}
+
+func (*OperatorKeyRotation) FieldRotatedAt() string {
+	return "RotatedAt"
+}
+
+func (m *OperatorKeyRotation) ColCreatedAt() *builder.Column {
+	return OperatorKeyRotationTable.ColByFieldName(m.FieldCreatedAt())
+}
+
+func (*OperatorKeyRotation) FieldCreatedAt() string {
+	return "CreatedAt"
+}
+
+func (m *OperatorKeyRotation) ColUpdatedAt() *builder.Column {
+	return OperatorKeyRotationTable.ColByFieldName(m.FieldUpdatedAt())
+}
+
+func (*OperatorKeyRotation) FieldUpdatedAt() string {
+	return "UpdatedAt"
+}
+
+func (m *OperatorKeyRotation) CondByValue(db sqlx.DBExecutor) builder.SqlCondition {
+	var (
+		tbl  = db.T(m)
+		fvs  = builder.FieldValueFromStructByNoneZero(m)
+		cond = make([]builder.SqlCondition, 0)
+	)
+
+	for _, fn := range m.IndexFieldNames() {
+		if v, ok := fvs[fn]; ok {
+			cond = append(cond, tbl.ColByFieldName(fn).Eq(v))
+			delete(fvs, fn)
+		}
+	}
+	if len(cond) == 0 {
+		panic(fmt.Errorf("no field for indexes has value"))
+	}
+	for fn, v := range fvs {
+		cond = append(cond, tbl.ColByFieldName(fn).Eq(v))
+	}
+	return builder.And(cond...)
+}
+
+func (m *OperatorKeyRotation) Create(db sqlx.DBExecutor) error {
+
+	if m.CreatedAt.IsZero() {
+		m.CreatedAt.Set(time.Now())
+	}
+
+	if m.UpdatedAt.IsZero() {
+		m.UpdatedAt.Set(time.Now())
+	}
+
+	_, err := db.Exec(sqlx.InsertToDB(db, m, nil))
+	return err
+}
+
+func (m *OperatorKeyRotation) List(db sqlx.DBExecutor, cond builder.SqlCondition, adds ...builder.Addition) ([]OperatorKeyRotation, error) {
+	var (
+		tbl = db.T(m)
+		lst = make([]OperatorKeyRotation, 0)
+	)
+	adds = append([]builder.Addition{builder.Where(cond), builder.Comment("OperatorKeyRotation.List")}, adds...)
+	err := db.QueryAndScan(builder.Select(nil).From(tbl, adds...), &lst)
+	return lst, err
+}
+
+func (m *OperatorKeyRotation) Count(db sqlx.DBExecutor, cond builder.SqlCondition, adds ...builder.Addition) (cnt int64, err error) {
+	tbl := db.T(m)
+	adds = append([]builder.Addition{builder.Where(cond), builder.Comment("OperatorKeyRotation.List")}, adds...)
+	err = db.QueryAndScan(builder.Select(builder.Count()).From(tbl, adds...), &cnt)
+	return
+}
+
+func (m *OperatorKeyRotation) FetchByID(db sqlx.DBExecutor) error {
+	tbl := db.T(m)
+	err := db.QueryAndScan(
+		builder.Select(nil).
+			From(
+				tbl,
+				builder.Where(
+					builder.And(
+						tbl.ColByFieldName("ID").Eq(m.ID),
+					),
+				),
+				builder.Comment("OperatorKeyRotation.FetchByID"),
+			),
+		m,
+	)
+	return err
+}
+
+func (m *OperatorKeyRotation) FetchByOperatorKeyRotationID(db sqlx.DBExecutor) error {
+	tbl := db.T(m)
+	err := db.QueryAndScan(
+		builder.Select(nil).
+			From(
+				tbl,
+				builder.Where(
+					builder.And(
+						tbl.ColByFieldName("OperatorKeyRotationID").Eq(m.OperatorKeyRotationID),
+					),
+				),
+				builder.Comment("OperatorKeyRotation.FetchByOperatorKeyRotationID"),
+			),
+		m,
+	)
+	return err
+}
+
+func (m *OperatorKeyRotation) UpdateByIDWithFVs(db sqlx.DBExecutor, fvs builder.FieldValues) error {
+
+	if _, ok := fvs["UpdatedAt"]; !ok {
+		fvs["UpdatedAt"] = types.Timestamp{Time: time.Now()}
+	}
+	tbl := db.T(m)
+	res, err := db.Exec(
+		builder.Update(tbl).
+			Where(
+				builder.And(
+					tbl.ColByFieldName("ID").Eq(m.ID),
+				),
+				builder.Comment("OperatorKeyRotation.UpdateByIDWithFVs"),
+			).
+			Set(tbl.AssignmentsByFieldValues(fvs)...),
+	)
+	if err != nil {
+		return err
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return m.FetchByID(db)
+	}
+	return nil
+}
+
+func (m *OperatorKeyRotation) UpdateByID(db sqlx.DBExecutor, zeros ...string) error {
+	fvs := builder.FieldValueFromStructByNoneZero(m, zeros...)
+	return m.UpdateByIDWithFVs(db, fvs)
+}
+
+func (m *OperatorKeyRotation) UpdateByOperatorKeyRotationIDWithFVs(db sqlx.DBExecutor, fvs builder.FieldValues) error {
+
+	if _, ok := fvs["UpdatedAt"]; !ok {
+		fvs["UpdatedAt"] = types.Timestamp{Time: time.Now()}
+	}
+	tbl := db.T(m)
+	res, err := db.Exec(
+		builder.Update(tbl).
+			Where(
+				builder.And(
+					tbl.ColByFieldName("OperatorKeyRotationID").Eq(m.OperatorKeyRotationID),
+				),
+				builder.Comment("OperatorKeyRotation.UpdateByOperatorKeyRotationIDWithFVs"),
+			).
+			Set(tbl.AssignmentsByFieldValues(fvs)...),
+	)
+	if err != nil {
+		return err
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return m.FetchByOperatorKeyRotationID(db)
+	}
+	return nil
+}
+
+func (m *OperatorKeyRotation) UpdateByOperatorKeyRotationID(db sqlx.DBExecutor, zeros ...string) error {
+	fvs := builder.FieldValueFromStructByNoneZero(m, zeros...)
+	return m.UpdateByOperatorKeyRotationIDWithFVs(db, fvs)
+}
+
+func (m *OperatorKeyRotation) Delete(db sqlx.DBExecutor) error {
+	_, err := db.Exec(
+		builder.Delete().
+			From(
+				db.T(m),
+				builder.Where(m.CondByValue(db)),
+				builder.Comment("OperatorKeyRotation.Delete"),
+			),
+	)
+	return err
+}
+
+func (m *OperatorKeyRotation) DeleteByID(db sqlx.DBExecutor) error {
+	tbl := db.T(m)
+	_, err := db.Exec(
+		builder.Delete().
+			From(
+				tbl,
+				builder.Where(
+					builder.And(
+						tbl.ColByFieldName("ID").Eq(m.ID),
+					),
+				),
+				builder.Comment("OperatorKeyRotation.DeleteByID"),
+			),
+	)
+	return err
+}
+
+func (m *OperatorKeyRotation) DeleteByOperatorKeyRotationID(db sqlx.DBExecutor) error {
+	tbl := db.T(m)
+	_, err := db.Exec(
+		builder.Delete().
+			From(
+				tbl,
+				builder.Where(
+					builder.And(
+						tbl.ColByFieldName("OperatorKeyRotationID").Eq(m.OperatorKeyRotationID),
+					),
+				),
+				builder.Comment("OperatorKeyRotation.DeleteByOperatorKeyRotationID"),
+			),
+	)
+	return err
+}
@@ -22,6 +22,7 @@ type Project struct {
 	RelAccount
 	ProjectName
 	ProjectBase
+	ProjectConfig
 	datatypes.OperationTimesWithDeleted
 }
 
@@ -39,6 +40,20 @@ type ProjectBase struct {
 	Description string         `db:"f_description,default=''"    json:"description,omitempty"`
 }
 
+type ProjectConfig struct {
+	// MaxConcurrentHandlers limits the concurrent event handlers of project, 0 means unlimited
+	MaxConcurrentHandlers int `db:"f_max_concurrent_handlers,default='0'" json:"maxConcurrentHandlers,omitempty"`
+	LogRetentionConfig
+}
+
+// LogRetentionConfig wasm log retention policy of project
+type LogRetentionConfig struct {
+	// MaxRows max wasm log rows kept, 0 means using the default limit
+	MaxRows int `db:"f_log_max_rows,default='0'" json:"maxRows,omitempty"`
+	// RetentionHours wasm logs older than it will be pruned, 0 means no limit
+	RetentionHours int `db:"f_log_retention_hours,default='0'" json:"retentionHours,omitempty"`
+}
+
 func (m Project) DatabaseName() string {
 	return "w3b_" + m.ProjectID.String()
 }
@@ -41,10 +41,13 @@ func (*Project) TableDesc() []string {
 
 func (*Project) Comments() map[string]string {
 	return map[string]string{
-		"AccountID": "AccountID  account id",
-		"Name":      "Name project name",
-		"Proto":     "Proto project protocol for event publisher",
-		"Version":   "Version project version",
+		"AccountID":             "AccountID  account id",
+		"MaxConcurrentHandlers": "MaxConcurrentHandlers limits the concurrent event handlers of project, 0 means unlimited",
+		"MaxRows":               "MaxRows max wasm log rows kept, 0 means using the default limit",
+		"Name":                  "Name project name",
+		"Proto":                 "Proto project protocol for event publisher",
+		"RetentionHours":        "RetentionHours wasm logs older than it will be pruned, 0 means no limit",
+		"Version":               "Version project version",
 	}
 }
 
@@ -53,12 +56,21 @@ func (*Project) ColDesc() map[string][]string {
 		"AccountID": []string{
 			"AccountID  account id",
 		},
+		"MaxConcurrentHandlers": []string{
+			"MaxConcurrentHandlers limits the concurrent event handlers of project, 0 means unlimited",
+		},
+		"MaxRows": []string{
+			"MaxRows max wasm log rows kept, 0 means using the default limit",
+		},
 		"Name": []string{
 			"Name project name",
 		},
 		"Proto": []string{
 			"Proto project protocol for event publisher",
 		},
+		"RetentionHours": []string{
+			"RetentionHours wasm logs older than it will be pruned, 0 means no limit",
+		},
 		"Version": []string{
 			"Version project version",
 		},
@@ -165,6 +177,30 @@ func (*Project) FieldDescription() string {
 	return "Description"
 }
 
+func (m *Project) ColMaxConcurrentHandlers() *builder.Column {
+	return ProjectTable.ColByFieldName(m.FieldMaxConcurrentHandlers())
+}
+
+func (*Project) FieldMaxConcurrentHandlers() string {
+	return "MaxConcurrentHandlers"
+}
+
+func (m *Project) ColMaxRows() *builder.Column {
+	return ProjectTable.ColByFieldName(m.FieldMaxRows())
+}
+
+func (*Project) FieldMaxRows() string {
+	return "MaxRows"
+}
+
+func (m *Project) ColRetentionHours() *builder.Column {
+	return ProjectTable.ColByFieldName(m.FieldRetentionHours())
+}
+
+func (*Project) FieldRetentionHours() string {
+	return "RetentionHours"
+}
+
 func (m *Project) ColCreatedAt() *builder.Column {
 	return ProjectTable.ColByFieldName(m.FieldCreatedAt())
 }
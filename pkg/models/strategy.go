@@ -32,10 +32,13 @@ type StrategyInfo struct {
 	Handler string `db:"f_handler" json:"handler"`
 	// AutoCollectMetric if allow host collect event data for metering
 	AutoCollectMetric datatypes.Bool `db:"f_auto_collect_metric,default='2'" json:"autoCollectMetric,omitempty"`
+	// MatchMode how EventType matches the type of event, default exact
+	MatchMode enums.StrategyMatchMode `db:"f_match_mode,default='1'" json:"matchMode,omitempty"`
 }
 
 var DefaultStrategyInfo = StrategyInfo{
 	EventType:         enums.EVENTTYPEDEFAULT,
 	Handler:           "start",
 	AutoCollectMetric: datatypes.FALSE,
+	MatchMode:         enums.STRATEGY_MATCH_MODE__EXACT,
 }
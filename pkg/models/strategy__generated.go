@@ -44,6 +44,7 @@ func (*Strategy) Comments() map[string]string {
 		"AutoCollectMetric": "AutoCollectMetric if allow host collect event data for metering",
 		"EventType":         "EventType user defined event type",
 		"Handler":           "Handler wasm handler fn name",
+		"MatchMode":         "MatchMode how EventType matches the type of event, default exact",
 	}
 }
 
@@ -58,6 +59,9 @@ func (*Strategy) ColDesc() map[string][]string {
 		"Handler": []string{
 			"Handler wasm handler fn name",
 		},
+		"MatchMode": []string{
+			"MatchMode how EventType matches the type of event, default exact",
+		},
 	}
 }
 
@@ -160,6 +164,14 @@ func (*Strategy) FieldAutoCollectMetric() string {
 	return "AutoCollectMetric"
 }
 
+func (m *Strategy) ColMatchMode() *builder.Column {
+	return StrategyTable.ColByFieldName(m.FieldMatchMode())
+}
+
+func (*Strategy) FieldMatchMode() string {
+	return "MatchMode"
+}
+
 func (m *Strategy) ColCreatedAt() *builder.Column {
 	return StrategyTable.ColByFieldName(m.FieldCreatedAt())
 }
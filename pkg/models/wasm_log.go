@@ -8,6 +8,7 @@ import (
 // WasmLog database model event
 // @def primary                           ID
 // @def unique_index UI_wasm_log_id       WasmLogID
+// @def index        I_project_name       ProjectName
 //
 //go:generate toolkit gen model WasmLog --database DB
 type WasmLog struct {
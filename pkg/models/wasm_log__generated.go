@@ -57,9 +57,18 @@ func (*WasmLog) PrimaryKey() []string {
 	}
 }
 
+func (*WasmLog) Indexes() builder.Indexes {
+	return builder.Indexes{
+		"i_project_name": []string{
+			"ProjectName",
+		},
+	}
+}
+
 func (m *WasmLog) IndexFieldNames() []string {
 	return []string{
 		"ID",
+		"ProjectName",
 		"WasmLogID",
 	}
 }
@@ -151,7 +151,7 @@ func Create(ctx context.Context, r *CreateReq) (*CreateRsp, error) {
 	if filename == "" {
 		filename = r.AppletName + ".wasm"
 	}
-	res, raw, err = resource.Create(ctx, acc.AccountID, r.File, filename, r.WasmMd5)
+	res, raw, err = resource.Create(ctx, acc.AccountID, r.File, filename, r.WasmMd5, r.WasmSha256)
 	if err != nil {
 		return nil, err
 	}
@@ -225,7 +225,7 @@ func Update(ctx context.Context, r *UpdateReq) (*UpdateRsp, error) {
 		if filename == "" {
 			filename = r.AppletName + ".wasm"
 		}
-		res, raw, err = resource.Create(ctx, acc.AccountID, r.File, filename, md5)
+		res, raw, err = resource.Create(ctx, acc.AccountID, r.File, filename, md5, r.Info.WasmSha256)
 		if err != nil {
 			return nil, err
 		}
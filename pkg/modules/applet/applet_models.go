@@ -70,6 +70,7 @@ type Info struct {
 	AppletName string                `json:"appletName"`
 	WasmName   string                `json:"wasmName,omitempty"`
 	WasmMd5    string                `json:"wasmMd5,omitempty"`
+	WasmSha256 string                `json:"wasmSha256,omitempty"`
 	WasmCache  *wasm.Cache           `json:"wasmCache,omitempty"`
 	Strategies []models.StrategyInfo `json:"strategies,omitempty"`
 }
@@ -180,6 +180,7 @@ func RemoveBySFID(ctx context.Context, id types.SFID) error {
 			return config.Remove(ctx, &config.CondArgs{RelIDs: []types.SFID{id}})
 		},
 		func(d sqlx.DBExecutor) error {
+			_ = vm.StopInstance(ctx, m.InstanceID)
 			if err := vm.DelInstance(ctx, m.InstanceID); err != nil {
 				// Warn
 			}
@@ -307,6 +308,7 @@ func UpsertByCode(ctx context.Context, r *CreateReq, code []byte, state enums.In
 		},
 		func(d sqlx.DBExecutor) error {
 			if forUpdate {
+				_ = vm.StopInstance(ctx, ins.InstanceID)
 				_ = vm.DelInstance(ctx, ins.InstanceID)
 			}
 			_ctx, err := WithInstanceRuntimeContext(types.WithInstance(ctx, ins))
@@ -15,6 +15,7 @@ import (
 	"github.com/machinefi/w3bstream/pkg/modules/projectoperator"
 	"github.com/machinefi/w3bstream/pkg/types"
 	"github.com/machinefi/w3bstream/pkg/types/wasm"
+	"github.com/machinefi/w3bstream/pkg/types/wasm/kvdb"
 )
 
 func WithInstanceRuntimeContext(parent context.Context) (context.Context, error) {
@@ -50,6 +51,9 @@ func WithInstanceRuntimeContext(parent context.Context) (context.Context, error)
 		parent = types.WithOperators(parent, ops)
 	}
 	apisrv := types.MustWasmApiServerFromContext(parent)
+	nats, _ := types.NATSFromContext(parent)
+	ipfs, _ := types.IPFSFromContext(parent)
+	secrets, _ := types.SecretProviderFromContext(parent)
 	account := prj.AccountID.String()
 	if strings.HasPrefix(prj.Name, "eth_") {
 		parts := strings.Split(prj.Name, "_")
@@ -60,6 +64,10 @@ func WithInstanceRuntimeContext(parent context.Context) (context.Context, error)
 	metric := metrics.NewCustomMetric(account, prj.Name)
 	logger := types.MustLoggerFromContext(parent)
 	sfid := confid.MustSFIDGeneratorFromContext(parent)
+	evc := wasm.NewEventCounter(types.MustRedisEndpointFromContext(parent), prj.ProjectID, func() (int64, error) {
+		m := &models.EventLog{}
+		return m.Count(d, m.ColProjectID().Eq(prj.ProjectID))
+	})
 
 	// wasm runtime context
 	// all configurations will be init from parent(host) context and with value to wasm runtime context
@@ -100,13 +108,21 @@ func WithInstanceRuntimeContext(parent context.Context) (context.Context, error)
 		types.WithWasmApiServerContext(apisrv),
 		types.WithLoggerContext(logger),
 		wasm.WithCustomMetricsContext(metric),
+		wasm.WithEventCounterContext(evc),
 		confid.WithSFIDGeneratorContext(sfid),
 		types.WithProjectContext(prj),
+		types.WithMgrDBExecutorContext(d),
+		kvdb.WithRedisDBKeyContext(kvdb.MustRedisDBKeyFromContext(parent)),
 		types.WithAppletContext(app),
 		types.WithInstanceContext(ins),
 		types.WithTaskWorkerContext(types.MustTaskWorkerFromContext(parent)),
 		types.WithTaskBoardContext(types.MustTaskBoardFromContext(parent)),
 		types.WithChainConfigContext(types.MustChainConfigFromContext(parent)),
+		types.WithWasmRuntimeConfigContext(types.MustWasmRuntimeConfigFromContext(parent)),
+		types.WithWasmDBConfigContext(types.MustWasmDBConfigFromContext(parent)),
 		types.WithOperatorPoolContext(types.MustOperatorPoolFromContext(parent)),
+		types.WithNATSContext(nats),
+		types.WithIPFSContext(ipfs),
+		types.WithSecretProviderContext(secrets),
 	)(ctx), nil
 }
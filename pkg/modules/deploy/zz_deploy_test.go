@@ -12,9 +12,12 @@ import (
 	"github.com/pkg/errors"
 
 	confid "github.com/machinefi/w3bstream/pkg/depends/conf/id"
+	confipfs "github.com/machinefi/w3bstream/pkg/depends/conf/ipfs"
 	conflog "github.com/machinefi/w3bstream/pkg/depends/conf/log"
 	confmqtt "github.com/machinefi/w3bstream/pkg/depends/conf/mqtt"
+	confnats "github.com/machinefi/w3bstream/pkg/depends/conf/nats"
 	confredis "github.com/machinefi/w3bstream/pkg/depends/conf/redis"
+	confsecret "github.com/machinefi/w3bstream/pkg/depends/conf/secret"
 	"github.com/machinefi/w3bstream/pkg/depends/kit/mq"
 	"github.com/machinefi/w3bstream/pkg/depends/kit/sqlx/builder"
 	"github.com/machinefi/w3bstream/pkg/depends/x/contextx"
@@ -30,6 +33,7 @@ import (
 	"github.com/machinefi/w3bstream/pkg/test/patch_modules"
 	"github.com/machinefi/w3bstream/pkg/types"
 	"github.com/machinefi/w3bstream/pkg/types/wasm"
+	"github.com/machinefi/w3bstream/pkg/types/wasm/kvdb"
 )
 
 var (
@@ -76,11 +80,13 @@ func TestDeploy(t *testing.T) {
 		types.WithInstanceContext(&models.Instance{}),
 		types.WithWasmDBConfigContext(&types.WasmDBConfig{}),
 		types.WithRedisEndpointContext(&confredis.Redis{}),
+		kvdb.WithRedisDBKeyContext(kvdb.NewRedisDB(&confredis.Redis{})),
 		types.WithTaskWorkerContext(&mq.TaskWorker{}),
 		types.WithTaskBoardContext(&mq.TaskBoard{}),
 		types.WithMqttBrokerContext(mqttBroker),
 		types.WithETHClientConfigContext(&types.ETHClientConfig{}),
 		types.WithChainConfigContext(&types.ChainConfig{}),
+		types.WithWasmRuntimeConfigContext(&types.WasmRuntimeConfig{}),
 		wasm.WithMQTTClientContext(mqttClient),
 		types.WithWasmApiServerContext(wasmApiServer),
 		types.WithOperatorPoolContext(operatorPool),
@@ -580,6 +586,28 @@ func TestDeploy(t *testing.T) {
 			_, err := deploy.WithInstanceRuntimeContext(ctx)
 			NewWithT(t).Expect(err).To(BeNil())
 		})
+
+		t.Run("#WithHostClients", func(t *testing.T) {
+			nats := &confnats.NATSConfig{}
+			ipfs := &confipfs.IPFSConfig{}
+			secrets := &confsecret.SecretProviderConfig{}
+
+			rtCtx, err := deploy.WithInstanceRuntimeContext(contextx.WithContextCompose(
+				types.WithNATSContext(nats),
+				types.WithIPFSContext(ipfs),
+				types.WithSecretProviderContext(secrets),
+			)(ctx))
+			NewWithT(t).Expect(err).To(BeNil())
+
+			v, _ := types.NATSFromContext(rtCtx)
+			NewWithT(t).Expect(v).To(BeIdenticalTo(nats))
+			v2, _ := types.IPFSFromContext(rtCtx)
+			NewWithT(t).Expect(v2).To(BeIdenticalTo(ipfs))
+			v3, _ := types.SecretProviderFromContext(rtCtx)
+			NewWithT(t).Expect(v3).To(BeIdenticalTo(secrets))
+			v4, _ := types.WasmDBConfigFromContext(rtCtx)
+			NewWithT(t).Expect(v4).To(BeIdenticalTo(types.MustWasmDBConfigFromContext(ctx)))
+		})
 	})
 }
 
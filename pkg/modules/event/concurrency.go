@@ -0,0 +1,98 @@
+package event
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/machinefi/w3bstream/pkg/types"
+)
+
+// HandlerAcquireTimeout the max duration waiting for a free handler slot of project
+var HandlerAcquireTimeout = 3 * time.Second
+
+var (
+	// limitersMtx guards limiters
+	limitersMtx sync.Mutex
+	// limiters project handler limiters keyed by project id
+	limiters = make(map[types.SFID]*handlerLimiter)
+)
+
+// handlerLimiter counts the in-flight handlers of project. the limit is given
+// on acquiring, so a changed limit applies to the handlers in flight
+type handlerLimiter struct {
+	mtx      sync.Mutex
+	inflight int
+	// freed is closed and replaced when a slot freed
+	freed chan struct{}
+}
+
+func (hl *handlerLimiter) acquire(ctx context.Context, limit int) error {
+	for {
+		hl.mtx.Lock()
+		if hl.inflight < limit {
+			hl.inflight++
+			hl.mtx.Unlock()
+			return nil
+		}
+		freed := hl.freed
+		hl.mtx.Unlock()
+
+		select {
+		case <-freed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (hl *handlerLimiter) release() {
+	hl.mtx.Lock()
+	defer hl.mtx.Unlock()
+
+	hl.inflight--
+	close(hl.freed)
+	hl.freed = make(chan struct{})
+}
+
+func handlerLimiterOf(prj types.SFID) *handlerLimiter {
+	limitersMtx.Lock()
+	defer limitersMtx.Unlock()
+
+	hl, ok := limiters[prj]
+	if !ok {
+		hl = &handlerLimiter{freed: make(chan struct{})}
+		limiters[prj] = hl
+	}
+	return hl
+}
+
+// RemoveHandlerLimiter evicts the handler limiter of project, it should be
+// called when project removed. the handlers in flight release to the evicted one
+func RemoveHandlerLimiter(prj types.SFID) {
+	limitersMtx.Lock()
+	defer limitersMtx.Unlock()
+
+	delete(limiters, prj)
+}
+
+// acquireHandler acquires a handler slot of project in context before handling
+// event. it waits until a slot freed, ctx done or HandlerAcquireTimeout elapsed,
+// and returns a release func which frees the slot at most once
+func acquireHandler(ctx context.Context) (func(), error) {
+	prj, ok := types.ProjectFromContext(ctx)
+	if !ok || prj.MaxConcurrentHandlers <= 0 {
+		return func() {}, nil
+	}
+
+	hl := handlerLimiterOf(prj.ProjectID)
+
+	ctx, cancel := context.WithTimeout(ctx, HandlerAcquireTimeout)
+	defer cancel()
+
+	if err := hl.acquire(ctx, prj.MaxConcurrentHandlers); err != nil {
+		return nil, err
+	}
+	once := &sync.Once{}
+	return func() { once.Do(hl.release) }, nil
+}
@@ -0,0 +1,70 @@
+package event
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/machinefi/w3bstream/pkg/models"
+	"github.com/machinefi/w3bstream/pkg/types"
+)
+
+func TestAcquireHandler(t *testing.T) {
+	timeout := HandlerAcquireTimeout
+	HandlerAcquireTimeout = 50 * time.Millisecond
+	defer func() { HandlerAcquireTimeout = timeout }()
+
+	prj := &models.Project{}
+	prj.ProjectID = 1
+	prj.MaxConcurrentHandlers = 1
+	ctx := types.WithProject(context.Background(), prj)
+
+	release, err := acquireHandler(ctx)
+	NewWithT(t).Expect(err).To(BeNil())
+
+	_, err = acquireHandler(ctx)
+	NewWithT(t).Expect(err).To(Equal(context.DeadlineExceeded))
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = acquireHandler(canceled)
+	NewWithT(t).Expect(err).To(Equal(context.Canceled))
+
+	// releasing twice frees only one slot
+	release()
+	release()
+	release, err = acquireHandler(ctx)
+	NewWithT(t).Expect(err).To(BeNil())
+	_, err = acquireHandler(ctx)
+	NewWithT(t).Expect(err).NotTo(BeNil())
+	release()
+
+	// limit changed, the handler in flight is counted
+	held, err := acquireHandler(ctx)
+	NewWithT(t).Expect(err).To(BeNil())
+	prj.MaxConcurrentHandlers = 2
+	ctx = types.WithProject(context.Background(), prj)
+	_, err = acquireHandler(ctx)
+	NewWithT(t).Expect(err).To(BeNil())
+	_, err = acquireHandler(ctx)
+	NewWithT(t).Expect(err).To(Equal(context.DeadlineExceeded))
+
+	// waiting handler acquires the slot freed
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		held()
+	}()
+	_, err = acquireHandler(ctx)
+	NewWithT(t).Expect(err).To(BeNil())
+
+	// evicted when project removed
+	RemoveHandlerLimiter(prj.ProjectID)
+	limitersMtx.Lock()
+	_, ok := limiters[prj.ProjectID]
+	limitersMtx.Unlock()
+	NewWithT(t).Expect(ok).To(BeFalse())
+	_, err = acquireHandler(ctx)
+	NewWithT(t).Expect(err).To(BeNil())
+}
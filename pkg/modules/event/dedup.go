@@ -0,0 +1,102 @@
+package event
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	confredis "github.com/machinefi/w3bstream/pkg/depends/conf/redis"
+	"github.com/machinefi/w3bstream/pkg/depends/kit/logr"
+	"github.com/machinefi/w3bstream/pkg/types"
+)
+
+// ErrDuplicateEvent the event handled before
+var ErrDuplicateEvent = errors.New("duplicate event")
+
+type ctxClientEventID struct{}
+
+// WithClientEventID carries the event id supplied by client, HandleEvent
+// deduplicates the event with it
+func WithClientEventID(ctx context.Context, eventID string) context.Context {
+	return context.WithValue(ctx, ctxClientEventID{}, eventID)
+}
+
+func clientEventIDFromContext(ctx context.Context) string {
+	v, _ := ctx.Value(ctxClientEventID{}).(string)
+	return v
+}
+
+// Dedup calls handle at most once for the event id supplied by client of
+// project in EventConfig.DedupTTL, it returns ErrDuplicateEvent if the event id
+// has been claimed. the event id generated by node (empty eventID) is not
+// deduplicated. the claim is released if handle failed, so the event can be
+// retried
+func Dedup(ctx context.Context, prj types.SFID, eventID string, handle func() ([]*Result, error)) ([]*Result, error) {
+	if eventID == "" {
+		return handle()
+	}
+
+	ctx, l := logr.Start(ctx, "modules.event.Dedup", "event_id", eventID)
+	defer l.End()
+
+	dup, err := isDuplicated(ctx, prj, eventID)
+	if err != nil {
+		// handles the event without claim if redis is unavailable
+		l.Warn(errors.Wrap(err, "event deduplication"))
+		return handle()
+	}
+	if dup {
+		return nil, ErrDuplicateEvent
+	}
+
+	results, err := handle()
+	if err != nil || !succeeded(results) {
+		if err := releaseDedup(ctx, prj, eventID); err != nil {
+			l.Warn(errors.Wrap(err, "release event deduplication"))
+		}
+	}
+	return results, err
+}
+
+func dedupKey(rds *confredis.Redis, prj types.SFID, eventID string) string {
+	return rds.Key(fmt.Sprintf("dedup:%d:%s", prj, eventID))
+}
+
+// isDuplicated claims event id of project for handling and reports if it has
+// been claimed in EventConfig.DedupTTL
+func isDuplicated(ctx context.Context, prj types.SFID, eventID string) (bool, error) {
+	rds := types.MustRedisEndpointFromContext(ctx)
+	ttl := types.MustEventConfigFromContext(ctx).DedupTTL.Duration()
+
+	result, err := rds.Exec(&confredis.Cmd{
+		Name: "SET",
+		Args: []interface{}{dedupKey(rds, prj, eventID), 1, "NX", "PX", ttl.Milliseconds()},
+	})
+	if err != nil {
+		return false, err
+	}
+	// SET NX replies nil if key exists
+	return result == nil, nil
+}
+
+// releaseDedup releases the claim of event id of project
+func releaseDedup(ctx context.Context, prj types.SFID, eventID string) error {
+	rds := types.MustRedisEndpointFromContext(ctx)
+
+	_, err := rds.Exec(&confredis.Cmd{
+		Name: "DEL",
+		Args: []interface{}{dedupKey(rds, prj, eventID)},
+	})
+	return err
+}
+
+// succeeded reports if the event is handled successfully by all instances
+func succeeded(results []*Result) bool {
+	for _, r := range results {
+		if r.ReturnCode != 0 || r.Error != "" {
+			return false
+		}
+	}
+	return true
+}
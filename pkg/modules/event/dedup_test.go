@@ -0,0 +1,29 @@
+package event
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestDedup(t *testing.T) {
+	// the event id generated by node is not claimed, there is no redis
+	// endpoint in context
+	called := 0
+	results, err := Dedup(context.Background(), 1, "", func() ([]*Result, error) {
+		called++
+		return []*Result{{}}, nil
+	})
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(results).To(HaveLen(1))
+	NewWithT(t).Expect(called).To(Equal(1))
+
+	NewWithT(t).Expect(succeeded(results)).To(BeTrue())
+	NewWithT(t).Expect(succeeded([]*Result{{}, {ReturnCode: -1}})).To(BeFalse())
+	NewWithT(t).Expect(succeeded([]*Result{{Error: ErrDuplicateEvent.Error()}})).To(BeFalse())
+
+	ctx := WithClientEventID(context.Background(), "client_event")
+	NewWithT(t).Expect(clientEventIDFromContext(ctx)).To(Equal("client_event"))
+	NewWithT(t).Expect(clientEventIDFromContext(context.Background())).To(Equal(""))
+}
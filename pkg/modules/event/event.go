@@ -16,9 +16,14 @@ import (
 	"github.com/machinefi/w3bstream/pkg/modules/strategy"
 	"github.com/machinefi/w3bstream/pkg/modules/trafficlimit"
 	"github.com/machinefi/w3bstream/pkg/modules/vm"
+	"github.com/machinefi/w3bstream/pkg/modules/vm/wasmtime"
 	"github.com/machinefi/w3bstream/pkg/types"
 )
 
+func init() {
+	wasmtime.SetEventEmitter(HandleEvent)
+}
+
 // HandleEvent support other module call
 // TODO the full project info is not in context so query and set here. this impl
 // is for support other module, which is temporary.
@@ -33,7 +38,11 @@ func HandleEvent(ctx context.Context, t string, data []byte) (interface{}, error
 		return nil, err
 	}
 
-	eventID := uuid.NewString() + "_monitor"
+	clientEventID := clientEventIDFromContext(ctx)
+	eventID := clientEventID
+	if eventID == "" {
+		eventID = uuid.NewString() + "_monitor"
+	}
 	ctx = types.WithEventID(ctx, eventID)
 
 	if err := trafficlimit.TrafficLimit(ctx, enums.TRAFFIC_LIMIT_TYPE__EVENT); err != nil {
@@ -48,20 +57,42 @@ func HandleEvent(ctx context.Context, t string, data []byte) (interface{}, error
 		return results, nil
 	}
 
-	strategies, err := strategy.FilterByProjectAndEvent(ctx, prj.ProjectID, t)
+	results, err := Dedup(ctx, prj.ProjectID, clientEventID, func() ([]*Result, error) {
+		strategies, err := strategy.FilterByProjectAndEvent(ctx, prj.ProjectID, t)
+		if err != nil {
+			return nil, err
+		}
+		return OnEvent(types.WithStrategyResults(ctx, strategies), data), nil
+	})
 	if err != nil {
+		if err == ErrDuplicateEvent {
+			return []*Result{{ReturnCode: -1, Error: err.Error()}}, nil
+		}
 		return nil, err
 	}
-
-	ctx = types.WithStrategyResults(ctx, strategies)
-
-	return OnEvent(ctx, data), nil
+	return results, nil
 }
 
 func OnEvent(ctx context.Context, data []byte) (ret []*Result) {
 	ctx, l := logr.Start(ctx, "modules.event.OnEvent", "event_id", types.MustEventIDFromContext(ctx))
 	defer l.End()
 
+	if traceID, ok := types.TraceIDFromContext(ctx); ok {
+		l = l.WithValues("trace_id", traceID)
+	}
+
+	mws := registeredMiddlewares()
+	if len(mws) > 0 {
+		prjName, ev := newEvent(ctx, data)
+		for _, m := range mws {
+			if err := m.Before(ctx, prjName, ev); err != nil {
+				l.Warn(errors.Wrap(err, "event middleware"))
+				return []*Result{{ReturnCode: -1, Error: err.Error()}}
+			}
+		}
+		data = ev.Payload
+	}
+
 	var (
 		r       = types.MustStrategyResultsFromContext(ctx)
 		results = make(chan *Result, len(r))
@@ -93,13 +124,29 @@ func OnEvent(ctx context.Context, data []byte) (ret []*Result) {
 		wg.Add(1)
 		go func(v *types.StrategyResult) {
 			defer wg.Done()
+			// acquires in handling goroutine, so that waiting for a slot
+			// does not block dispatching to other handlers
+			release, err := acquireHandler(ctx)
+			if err != nil {
+				l.Warn(errors.Wrap(err, "too many concurrent handlers"))
+				results <- &Result{
+					AppletName:  v.AppletName,
+					InstanceID:  v.InstanceID,
+					Handler:     v.Handler,
+					ReturnValue: nil,
+					ReturnCode:  -1,
+					Error:       status.ServiceUnavailable.Key(),
+				}
+				return
+			}
+			defer release()
 			l.Debug("instance start to process.")
 			rv := ins.HandleEvent(ctx, v.Handler, v.EventType, data)
 			results <- &Result{
 				AppletName:  v.AppletName,
 				InstanceID:  v.InstanceID,
 				Handler:     v.Handler,
-				ReturnValue: nil,
+				ReturnValue: rv.Output,
 				ReturnCode:  int(rv.Code),
 				Error:       rv.ErrMsg,
 			}
@@ -120,5 +167,11 @@ func OnEvent(ctx context.Context, data []byte) (ret []*Result) {
 		}
 		ret = append(ret, v)
 	}
+
+	for _, m := range mws {
+		if err := m.After(ctx, ret); err != nil {
+			l.Warn(errors.Wrap(err, "event middleware"))
+		}
+	}
 	return ret
 }
@@ -25,6 +25,10 @@ type EventReq struct {
 	EventID string `in:"query" name:"eventID,omitempty"`
 	// Timestamp event time when publisher do send
 	Timestamp int64 `in:"query" name:"timestamp,omitempty"`
+	// XTraceID trace id for tracing event across services
+	XTraceID string `in:"header" name:"X-Trace-ID,omitempty"`
+	// TraceParent W3C trace context, used when XTraceID is not set
+	TraceParent string `in:"header" name:"traceparent,omitempty"`
 	// Payload event payload (binary only)
 	Payload bytes.Buffer `in:"body" mime:"stream"`
 }
@@ -43,6 +47,18 @@ func (r *EventReq) SetDefault() {
 	}
 }
 
+// TraceID returns X-Trace-ID header or the trace id of W3C traceparent header
+// formatted as `version-traceid-parentid-flags`
+func (r *EventReq) TraceID() string {
+	if r.XTraceID != "" {
+		return r.XTraceID
+	}
+	if parts := strings.Split(r.TraceParent, "-"); len(parts) == 4 && len(parts[1]) == 32 {
+		return parts[1]
+	}
+	return ""
+}
+
 func (r *EventReq) IsDataPush() bool {
 	return r.EventType == eventTypeDataPush
 }
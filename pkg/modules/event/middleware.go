@@ -0,0 +1,61 @@
+package event
+
+import (
+	"context"
+	"sync"
+
+	"github.com/machinefi/w3bstream/pkg/depends/protocol/eventpb"
+	"github.com/machinefi/w3bstream/pkg/types"
+)
+
+// EventMiddleware hooks around event processing, Before is called before the
+// event dispatched to instances and the payload modified by Before is
+// dispatched; After is called with the results of instances
+type EventMiddleware interface {
+	Before(ctx context.Context, projectName string, event *eventpb.Event) error
+	After(ctx context.Context, results []*Result) error
+}
+
+var (
+	middlewares   []EventMiddleware
+	middlewaresMu sync.RWMutex
+)
+
+// RegisterMiddleware appends m to event middlewares, middlewares are called in
+// registration order
+func RegisterMiddleware(m EventMiddleware) {
+	middlewaresMu.Lock()
+	defer middlewaresMu.Unlock()
+
+	middlewares = append(middlewares, m)
+}
+
+func registeredMiddlewares() []EventMiddleware {
+	middlewaresMu.RLock()
+	defer middlewaresMu.RUnlock()
+
+	return middlewares
+}
+
+// newEvent builds the event passed to middlewares from context
+func newEvent(ctx context.Context, data []byte) (string, *eventpb.Event) {
+	prjName := ""
+	if prj, ok := types.ProjectFromContext(ctx); ok {
+		prjName = prj.Name
+	}
+
+	ev := &eventpb.Event{Header: &eventpb.Header{}, Payload: data}
+	if id, ok := types.EventIDFromContext(ctx); ok {
+		ev.Header.EventId = id
+	}
+	if pub, ok := types.PublisherFromContext(ctx); ok {
+		ev.Header.PubId = pub.Key
+	}
+	if r, ok := types.StrategyResultsFromContext(ctx); ok && len(r) > 0 {
+		ev.Header.EventType = r[0].EventType
+		if prjName == "" {
+			prjName = r[0].ProjectName
+		}
+	}
+	return prjName, ev
+}
@@ -0,0 +1,187 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/machinefi/w3bstream/pkg/depends/kit/logr"
+	"github.com/machinefi/w3bstream/pkg/depends/kit/sqlx"
+	"github.com/machinefi/w3bstream/pkg/depends/kit/sqlx/builder"
+	"github.com/machinefi/w3bstream/pkg/enums"
+	"github.com/machinefi/w3bstream/pkg/errors/status"
+	"github.com/machinefi/w3bstream/pkg/models"
+	"github.com/machinefi/w3bstream/pkg/modules/strategy"
+	"github.com/machinefi/w3bstream/pkg/types"
+)
+
+const (
+	// ReplayPageSize count of event logs fetched per page when replaying
+	ReplayPageSize = 100
+	// ReplayRate max events replayed per second
+	ReplayRate = 100
+	// MaxReplayPayloadSize max size of event payload kept in event log for
+	// replaying
+	MaxReplayPayloadSize = 64 << 10
+)
+
+// ErrReplayAborted replaying is aborted by the event handled failed
+var ErrReplayAborted = errors.New("replay aborted")
+
+type ReplayReq struct {
+	// From event id replayed from(included), empty means the earliest event
+	From string `in:"query" name:"from,omitempty"`
+	// To event id replayed to(included), empty means the latest event
+	To string `in:"query" name:"to,omitempty"`
+	// ContinueOnError continues replaying when event handled failed
+	ContinueOnError bool `in:"query" name:"continueOnError,omitempty"`
+}
+
+type ReplayResult struct {
+	// EventID replayed event id
+	EventID string `json:"eventID"`
+	// EventType replayed event type
+	EventType string `json:"eventType"`
+	// Results results of instances which handled the event
+	Results []*Result `json:"results"`
+	// Error replaying error message
+	Error string `json:"error,omitempty"`
+	// Skipped the event is skipped because its payload isn't kept in log
+	Skipped bool `json:"skipped,omitempty"`
+}
+
+// ReplayablePayload returns the payload kept in event log for replaying, the
+// payload larger than MaxReplayPayloadSize isn't kept
+func ReplayablePayload(payload []byte) []byte {
+	if len(payload) > MaxReplayPayloadSize {
+		return nil
+	}
+	return payload
+}
+
+// Failed reports if the event is failed to replay or handled failed by any
+// instance
+func (r *ReplayResult) Failed() bool {
+	if r.Error != "" {
+		return true
+	}
+	for _, v := range r.Results {
+		if v.ReturnCode != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Replay re-processes the logged events of project in [From, To] by the
+// running instances in order, emit is called with the result of each event.
+// it returns ErrReplayAborted when an event handled failed unless
+// ContinueOnError is set
+func Replay(ctx context.Context, r *ReplayReq, emit func(*ReplayResult) error) error {
+	ctx, l := logr.Start(ctx, "modules.event.Replay")
+	defer l.End()
+
+	var (
+		d    = types.MustMgrDBExecutorFromContext(ctx)
+		prj  = types.MustProjectFromContext(ctx)
+		m    = &models.EventLog{}
+		cond = []builder.SqlCondition{m.ColProjectID().Eq(prj.ProjectID)}
+	)
+	if r.From != "" {
+		id, err := eventLogID(d, prj.ProjectID, r.From)
+		if err != nil {
+			return err
+		}
+		cond = append(cond, m.ColID().Gte(id))
+	}
+	if r.To != "" {
+		id, err := eventLogID(d, prj.ProjectID, r.To)
+		if err != nil {
+			return err
+		}
+		cond = append(cond, m.ColID().Lte(id))
+	}
+
+	ticker := time.NewTicker(time.Second / ReplayRate)
+	defer ticker.Stop()
+
+	last := uint64(0)
+	for {
+		lst, err := m.List(d, builder.And(append(cond, m.ColID().Gt(last))...),
+			builder.OrderBy(builder.AscOrder(m.ColID())),
+			builder.Limit(ReplayPageSize),
+		)
+		if err != nil {
+			return status.DatabaseError.StatusErr().WithDesc(err.Error())
+		}
+		if len(lst) == 0 {
+			return nil
+		}
+		for i := range lst {
+			ev := &lst[i]
+			last = ev.ID
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-ticker.C:
+			}
+
+			res := replay(ctx, prj, ev)
+			if err = emit(res); err != nil {
+				return err
+			}
+			if res.Failed() && !r.ContinueOnError {
+				l.Warn(errors.Errorf("replay aborted at event %s", ev.EventID))
+				return ErrReplayAborted
+			}
+		}
+	}
+}
+
+func eventLogID(d sqlx.DBExecutor, prj types.SFID, eventID string) (uint64, error) {
+	m := &models.EventLog{}
+	lst, err := m.List(d,
+		builder.And(m.ColProjectID().Eq(prj), m.ColEventID().Eq(eventID)),
+		builder.OrderBy(builder.AscOrder(m.ColID())),
+		builder.Limit(1),
+	)
+	if err != nil {
+		return 0, status.DatabaseError.StatusErr().WithDesc(err.Error())
+	}
+	if len(lst) == 0 {
+		return 0, status.NotFound.StatusErr().WithDesc(fmt.Sprintf("event %s not found", eventID))
+	}
+	return lst[0].ID, nil
+}
+
+// replay handles the logged event ev by the instances hit strategies, the
+// event without payload logged is skipped
+func replay(ctx context.Context, prj *models.Project, ev *models.EventLog) *ReplayResult {
+	eventType := ev.EventType
+	if eventType == "" {
+		eventType = enums.EVENTTYPEDEFAULT
+	}
+	res := &ReplayResult{EventID: ev.EventID, EventType: eventType}
+	if len(ev.Payload) == 0 {
+		res.Skipped = true
+		return res
+	}
+
+	strategies, err := strategy.FilterByProjectAndEvent(ctx, prj.ProjectID, eventType)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+
+	ctx = types.WithStrategyResults(ctx, strategies)
+	ctx = types.WithEventID(ctx, ev.EventID)
+	pub := &models.Publisher{RelPublisher: models.RelPublisher{PublisherID: ev.PublisherID}}
+	if err = pub.FetchByPublisherID(types.MustMgrDBExecutorFromContext(ctx)); err == nil {
+		ctx = types.WithPublisher(ctx, pub)
+	}
+	res.Results = OnEvent(ctx, ev.Payload)
+	return res
+}
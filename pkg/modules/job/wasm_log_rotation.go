@@ -0,0 +1,126 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/machinefi/w3bstream/pkg/depends/kit/logr"
+	"github.com/machinefi/w3bstream/pkg/depends/kit/sqlx/builder"
+	"github.com/machinefi/w3bstream/pkg/depends/x/mapx"
+	"github.com/machinefi/w3bstream/pkg/models"
+	"github.com/machinefi/w3bstream/pkg/types"
+)
+
+// logPrunedAt last pruned time of wasm logs; key: project name
+var logPrunedAt = mapx.New[string, time.Time]()
+
+type WasmLogStats struct {
+	RowCount     int64           `json:"rowCount"`
+	LastPrunedAt types.Timestamp `json:"lastPrunedAt"`
+}
+
+// RunWasmLogRotation prunes wasm logs of all projects periodically until ctx
+// is canceled
+func RunWasmLogRotation(ctx context.Context) {
+	conf := types.MustWasmLogConfigFromContext(ctx)
+	ticker := time.NewTicker(conf.RotationInterval.Duration())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			RotateWasmLogs(ctx)
+		}
+	}
+}
+
+func RotateWasmLogs(ctx context.Context) {
+	ctx, l := logr.Start(ctx, "modules.job.RotateWasmLogs")
+	defer l.End()
+
+	d := types.MustMgrDBExecutorFromContext(ctx)
+
+	prjs, err := (&models.Project{}).List(d, nil)
+	if err != nil {
+		l.Error(err)
+		return
+	}
+	for i := range prjs {
+		prj := &prjs[i]
+		if err = PruneWasmLogs(ctx, prj); err != nil {
+			l.WithValues("prj", prj.Name).Error(err)
+		}
+	}
+}
+
+// PruneWasmLogs deletes the wasm logs of project exceeding retention hours, and
+// the oldest ones exceeding max rows. the max rows is WasmLogConfig.MaxRowsPerProject
+// if project not assigned
+func PruneWasmLogs(ctx context.Context, prj *models.Project) error {
+	var (
+		d       = types.MustMgrDBExecutorFromContext(ctx)
+		m       = &models.WasmLog{}
+		maxRows = prj.MaxRows
+	)
+	if maxRows <= 0 {
+		maxRows = types.MustWasmLogConfigFromContext(ctx).MaxRowsPerProject
+	}
+
+	if prj.RetentionHours > 0 {
+		before := time.Now().Add(-time.Duration(prj.RetentionHours) * time.Hour)
+		_, err := d.Exec(builder.Delete().From(
+			d.T(m),
+			builder.Where(builder.And(
+				m.ColProjectName().Eq(prj.Name),
+				m.ColLogTime().Lt(before.UnixNano()),
+			)),
+		))
+		if err != nil {
+			return err
+		}
+	}
+
+	// the newest log out of max rows, logs not newer than it will be pruned
+	lst, err := m.List(d, m.ColProjectName().Eq(prj.Name),
+		builder.OrderBy(builder.DescOrder(m.ColID())),
+		builder.Limit(1).Offset(int64(maxRows)),
+	)
+	if err != nil {
+		return err
+	}
+	if len(lst) > 0 {
+		_, err = d.Exec(builder.Delete().From(
+			d.T(m),
+			builder.Where(builder.And(
+				m.ColProjectName().Eq(prj.Name),
+				m.ColID().Lte(lst[0].ID),
+			)),
+		))
+		if err != nil {
+			return err
+		}
+	}
+
+	logPrunedAt.Store(prj.Name, time.Now())
+	return nil
+}
+
+// GetWasmLogStats returns current wasm log rows and last pruned time of project
+func GetWasmLogStats(ctx context.Context, prjName string) (*WasmLogStats, error) {
+	var (
+		d = types.MustMgrDBExecutorFromContext(ctx)
+		m = &models.WasmLog{}
+	)
+
+	cnt, err := m.Count(d, m.ColProjectName().Eq(prjName))
+	if err != nil {
+		return nil, err
+	}
+	ret := &WasmLogStats{RowCount: cnt}
+	if at, ok := logPrunedAt.Load(prjName); ok {
+		ret.LastPrunedAt = types.Timestamp{Time: at}
+	}
+	return ret, nil
+}
@@ -16,6 +16,8 @@ const (
 	_eventMtcName        = "inbound_events_metrics"
 	_publisherMtcName    = "publishers_metrics"
 	_blockChainTxMtcName = "w3b_blockchain_tx_metrics"
+	_dbIndexScansMtcName = "w3b_wasm_db_index_scans"
+	_dbTableRowsMtcName  = "w3bstream_db_table_rows"
 )
 
 var (
@@ -41,18 +43,34 @@ var (
 		Name: _blockChainTxMtcName,
 		Help: "blockchain transaction counter metrics.",
 	}, []string{"project", "chainID"})
+
+	// DBIndexScansMtc index scans of project wasm database, refreshed daily
+	DBIndexScansMtc = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: _dbIndexScansMtcName,
+		Help: "wasm database index scans metrics.",
+	}, []string{"project", "schema", "table", "index"})
+
+	// DBTableRowsMtc estimated rows of project wasm database tables
+	DBTableRowsMtc = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: _dbTableRowsMtcName,
+		Help: "wasm database table estimated rows metrics.",
+	}, []string{"project", "schema", "table"})
 )
 
 func init() {
 	prometheus.MustRegister(eventMtc)
 	prometheus.MustRegister(publisherMtc)
 	prometheus.MustRegister(BlockChainTxMtc)
+	prometheus.MustRegister(DBIndexScansMtc)
+	prometheus.MustRegister(DBTableRowsMtc)
 }
 
 func RemoveMetrics(ctx context.Context, account string, project string) {
 	eventMtc.DeletePartialMatch(prometheus.Labels{"account": account, "project": project})
 	publisherMtc.DeletePartialMatch(prometheus.Labels{"account": account, "project": project})
 	BlockChainTxMtc.DeletePartialMatch(prometheus.Labels{"project": project})
+	DBIndexScansMtc.DeletePartialMatch(prometheus.Labels{"project": project})
+	DBTableRowsMtc.DeletePartialMatch(prometheus.Labels{"project": project})
 
 	// erase data in metrics server
 	if err := eraseDataInServer(ctx, account, project); err != nil {
@@ -12,6 +12,10 @@ type CreateReq struct {
 	PrivateKey string `json:"privateKey"`
 }
 
+type RotateKeyReq struct {
+	PrivateKey string `json:"privateKey"`
+}
+
 type CondArgs struct {
 	AccountID types.SFID `name:"-"`
 }
@@ -2,11 +2,18 @@ package operator
 
 import (
 	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
 
 	confid "github.com/machinefi/w3bstream/pkg/depends/conf/id"
 	"github.com/machinefi/w3bstream/pkg/depends/kit/sqlx"
+	"github.com/machinefi/w3bstream/pkg/depends/kit/statusx"
+	"github.com/machinefi/w3bstream/pkg/enums"
 	"github.com/machinefi/w3bstream/pkg/errors/status"
 	"github.com/machinefi/w3bstream/pkg/models"
 	"github.com/machinefi/w3bstream/pkg/modules/projectoperator"
@@ -101,6 +108,84 @@ func Create(ctx context.Context, r *CreateReq) (*models.Operator, error) {
 	return op, nil
 }
 
+// RotateKeyDrainTimeout the maximum duration of waiting for the pending
+// transactions signed with the old key when rotating operator key
+var RotateKeyDrainTimeout = 2 * time.Minute
+
+// RotateKey replaces the private key of operator in context without
+// restarting. the pooled operator is locked during rotating, drain is called
+// to wait for the pending transactions signed with the old key, then the new
+// key is persisted with a rotation record, and the pooled key is replaced at
+// last. the rotation fails with OperatorPendingTxsNotDrained if the pending
+// transactions are not drained in RotateKeyDrainTimeout
+func RotateKey(ctx context.Context, r *RotateKeyReq, drain func(ctx context.Context, op *models.Operator) error) error {
+	var (
+		d    = types.MustMgrDBExecutorFromContext(ctx)
+		op   = types.MustOperatorFromContext(ctx)
+		acc  = types.MustAccountFromContext(ctx)
+		pool = types.MustOperatorPoolFromContext(ctx)
+		idg  = confid.MustSFIDGeneratorFromContext(ctx)
+	)
+
+	key := common.FromHex(r.PrivateKey)
+	if err := ValidatePrivateKey(op.Type, key); err != nil {
+		return status.InvalidPrivateKey.StatusErr().WithDesc(err.Error())
+	}
+
+	err := pool.RotateKey(op.AccountID, op.Name, key, func() error {
+		dctx, cancel := context.WithTimeout(ctx, RotateKeyDrainTimeout)
+		defer cancel()
+		if err := drain(dctx, op); err != nil {
+			return status.OperatorPendingTxsNotDrained.StatusErr().WithDesc(err.Error())
+		}
+
+		rotated := *op
+		rotated.PrivateKey = hex.EncodeToString(key)
+		return sqlx.NewTasks(d).With(
+			func(d sqlx.DBExecutor) error {
+				if err := rotated.UpdateByOperatorID(d); err != nil {
+					return status.DatabaseError.StatusErr().WithDesc(err.Error())
+				}
+				return nil
+			},
+			func(d sqlx.DBExecutor) error {
+				m := &models.OperatorKeyRotation{
+					RelOperatorKeyRotation: models.RelOperatorKeyRotation{OperatorKeyRotationID: idg.MustGenSFID()},
+					RelOperator:            models.RelOperator{OperatorID: op.OperatorID},
+					OperatorKeyRotationInfo: models.OperatorKeyRotationInfo{
+						OperatorName: op.Name,
+						RotatedBy:    acc.AccountID,
+						RotatedAt:    types.Timestamp{Time: time.Now()},
+					},
+				}
+				if err := m.Create(d); err != nil {
+					return status.DatabaseError.StatusErr().WithDesc(err.Error())
+				}
+				return nil
+			},
+		).Do()
+	})
+	if err != nil {
+		if _, ok := statusx.IsStatusErr(err); ok {
+			return err
+		}
+		return status.InvalidPrivateKey.StatusErr().WithDesc(err.Error())
+	}
+	return nil
+}
+
+// ValidatePrivateKey checks if the private key can be used as a signer of typ
+func ValidatePrivateKey(typ enums.OperatorKeyType, key []byte) error {
+	if typ == enums.OPERATOR_KEY__ED25519 {
+		if len(key) != ed25519.PrivateKeySize {
+			return errors.Errorf("invalid ed25519 private key length: %d", len(key))
+		}
+		return nil
+	}
+	_, err := crypto.ToECDSA(key)
+	return err
+}
+
 func ListByCond(ctx context.Context, r *CondArgs) ([]models.Operator, error) {
 	var (
 		d = types.MustMgrDBExecutorFromContext(ctx)
@@ -2,6 +2,7 @@ package pool
 
 import (
 	"context"
+	"encoding/hex"
 	"fmt"
 	"sync"
 
@@ -56,6 +57,49 @@ func (p *Pool) setOperator(accountID types.SFID, opName string) (*optypes.SyncOp
 	return nsop, nil
 }
 
+// RotateKey replaces the private key of the pooled operator, the operator is
+// loaded from database if not pooled yet. the operator is locked while
+// rotating, so no transaction is signed with the old key after prepare called.
+// the pooled key is replaced only if prepare succeeded.
+func (p *Pool) RotateKey(accountID types.SFID, opName string, newPrivateKey []byte, prepare func() error) error {
+	sop, err := p.Get(accountID, opName)
+	if err != nil {
+		return err
+	}
+
+	sop.Mux.Lock()
+	defer sop.Mux.Unlock()
+
+	if err = operator.ValidatePrivateKey(sop.Op.Type, newPrivateKey); err != nil {
+		return err
+	}
+	if err = prepare(); err != nil {
+		return err
+	}
+
+	op := *sop.Op
+	op.PrivateKey = hex.EncodeToString(newPrivateKey)
+	sop.Op = &op
+	return nil
+}
+
+// List returns the operator names of account from database, the operators not
+// loaded into pool are included
+func (p *Pool) List(accountID types.SFID) ([]string, error) {
+	ops, err := operator.ListByCond(
+		types.WithMgrDBExecutor(context.Background(), p.db),
+		&operator.CondArgs{AccountID: accountID},
+	)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(ops))
+	for i := range ops {
+		names = append(names, ops[i].Name)
+	}
+	return names, nil
+}
+
 // operator memory pool
 // TODO support operator delete
 func NewPool(mgrDB sqlx.DBExecutor) optypes.Pool {
@@ -0,0 +1,67 @@
+package pool
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+
+	"github.com/machinefi/w3bstream/pkg/enums"
+	"github.com/machinefi/w3bstream/pkg/models"
+	optypes "github.com/machinefi/w3bstream/pkg/modules/operator/pool/types"
+	"github.com/machinefi/w3bstream/pkg/types"
+)
+
+func TestPool_RotateKey(t *testing.T) {
+	oldKey, err := crypto.GenerateKey()
+	NewWithT(t).Expect(err).To(BeNil())
+	newKey, err := crypto.GenerateKey()
+	NewWithT(t).Expect(err).To(BeNil())
+
+	var (
+		accountID = types.SFID(1)
+		opName    = "op"
+		oldHex    = hex.EncodeToString(crypto.FromECDSA(oldKey))
+		newHex    = hex.EncodeToString(crypto.FromECDSA(newKey))
+	)
+	p := &Pool{operators: map[string]*optypes.SyncOperator{}}
+	sop := &optypes.SyncOperator{Op: &models.Operator{
+		OperatorInfo: models.OperatorInfo{Name: opName, PrivateKey: oldHex, Type: enums.OPERATOR_KEY__ECDSA},
+	}}
+	p.operators[p.getKey(accountID, opName)] = sop
+
+	t.Run("PrepareFailed", func(t *testing.T) {
+		err := p.RotateKey(accountID, opName, crypto.FromECDSA(newKey), func() error {
+			return errors.New("not drained")
+		})
+		NewWithT(t).Expect(err).NotTo(BeNil())
+		NewWithT(t).Expect(sop.Operator().PrivateKey).To(Equal(oldHex))
+	})
+
+	t.Run("InvalidKey", func(t *testing.T) {
+		prepared := false
+		err := p.RotateKey(accountID, opName, []byte{1, 2, 3}, func() error {
+			prepared = true
+			return nil
+		})
+		NewWithT(t).Expect(err).NotTo(BeNil())
+		NewWithT(t).Expect(prepared).To(BeFalse())
+		NewWithT(t).Expect(sop.Operator().PrivateKey).To(Equal(oldHex))
+	})
+
+	t.Run("Rotated", func(t *testing.T) {
+		old := sop.Operator()
+		err := p.RotateKey(accountID, opName, crypto.FromECDSA(newKey), func() error {
+			// senders are blocked while preparing
+			NewWithT(t).Expect(sop.Mux.TryLock()).To(BeFalse())
+			NewWithT(t).Expect(sop.Op.PrivateKey).To(Equal(oldHex))
+			return nil
+		})
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(sop.Operator().PrivateKey).To(Equal(newHex))
+		// the operator read before is not modified
+		NewWithT(t).Expect(old.PrivateKey).To(Equal(oldHex))
+	})
+}
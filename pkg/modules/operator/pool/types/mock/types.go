@@ -50,3 +50,32 @@ func (mr *MockPoolMockRecorder) Get(accountID, opName interface{}) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockPool)(nil).Get), accountID, opName)
 }
+
+// List mocks base method.
+func (m *MockPool) List(accountID types.SFID) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", accountID)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockPoolMockRecorder) List(accountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockPool)(nil).List), accountID)
+}
+
+// RotateKey mocks base method.
+func (m *MockPool) RotateKey(accountID types.SFID, opName string, newPrivateKey []byte, prepare func() error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateKey", accountID, opName, newPrivateKey, prepare)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RotateKey indicates an expected call of RotateKey.
+func (mr *MockPoolMockRecorder) RotateKey(accountID, opName, newPrivateKey, prepare interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateKey", reflect.TypeOf((*MockPool)(nil).RotateKey), accountID, opName, newPrivateKey, prepare)
+}
@@ -7,11 +7,28 @@ import (
 	"github.com/machinefi/w3bstream/pkg/models"
 )
 
+// SyncOperator operator shared by the senders of account. Mux serializes the
+// transactions signed by operator, and Op is replaced as a whole under Mux when
+// key rotated, so read it by Operator if Mux is not held
 type SyncOperator struct {
 	Mux sync.Mutex
 	Op  *models.Operator
 }
 
+// Operator returns the current operator under Mux, the operator returned is
+// never modified
+func (o *SyncOperator) Operator() *models.Operator {
+	o.Mux.Lock()
+	defer o.Mux.Unlock()
+	return o.Op
+}
+
 type Pool interface {
 	Get(accountID basetypes.SFID, opName string) (*SyncOperator, error)
+	// RotateKey replaces the private key of a pooled operator. the operator is
+	// locked while prepare drains the transactions signed with the old key and
+	// persists the new key, the pooled key is replaced if prepare succeeded
+	RotateKey(accountID basetypes.SFID, opName string, newPrivateKey []byte, prepare func() error) error
+	// List returns the operator names of account
+	List(accountID basetypes.SFID) ([]string, error)
 }
@@ -4,6 +4,9 @@ package project
 
 import (
 	"context"
+	"io"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -11,13 +14,18 @@ import (
 	"github.com/machinefi/w3bstream/pkg/depends/kit/logr"
 	"github.com/machinefi/w3bstream/pkg/depends/kit/sqlx"
 	"github.com/machinefi/w3bstream/pkg/depends/kit/sqlx/datatypes"
+	"github.com/machinefi/w3bstream/pkg/enums"
 	"github.com/machinefi/w3bstream/pkg/errors/status"
 	"github.com/machinefi/w3bstream/pkg/models"
 	"github.com/machinefi/w3bstream/pkg/modules/applet"
 	"github.com/machinefi/w3bstream/pkg/modules/config"
+	"github.com/machinefi/w3bstream/pkg/modules/event"
+	"github.com/machinefi/w3bstream/pkg/modules/job"
+	"github.com/machinefi/w3bstream/pkg/modules/metrics"
 	"github.com/machinefi/w3bstream/pkg/modules/transporter/mqtt"
 	"github.com/machinefi/w3bstream/pkg/types"
 	"github.com/machinefi/w3bstream/pkg/types/wasm"
+	"github.com/machinefi/w3bstream/pkg/types/wasm/kvdb"
 )
 
 func GetBySFID(ctx context.Context, prj types.SFID) (*models.Project, error) {
@@ -135,6 +143,7 @@ func Create(ctx context.Context, r *CreateReq) (*CreateRsp, error) {
 			Proto:       r.Proto,
 			Description: r.Description,
 		},
+		ProjectConfig: r.ProjectConfig,
 	}
 
 	rsp := &CreateRsp{
@@ -194,6 +203,242 @@ func Create(ctx context.Context, r *CreateReq) (*CreateRsp, error) {
 	return rsp, nil
 }
 
+// SetLogLevel updates the minimum wasm log level of project in context, it
+// takes effect on running instances without redeploying
+func SetLogLevel(ctx context.Context, r *SetLogLevelReq) (*wasm.Env, error) {
+	prj := types.MustProjectFromContext(ctx)
+
+	c, err := config.GetValueByRelAndType(ctx, prj.ProjectID, enums.CONFIG_TYPE__PROJECT_ENV)
+	if err != nil {
+		return nil, err
+	}
+	env := c.(*wasm.Env)
+	lv := r.Level
+	env.MinLogLevel = &lv
+	if _, err = config.Upsert(ctx, prj.ProjectID, env); err != nil {
+		return nil, err
+	}
+	return env, nil
+}
+
+// SetLogRetention updates the wasm log retention policy of project in context
+func SetLogRetention(ctx context.Context, r *models.LogRetentionConfig) (*models.Project, error) {
+	d := types.MustMgrDBExecutorFromContext(ctx)
+	prj := types.MustProjectFromContext(ctx)
+
+	prj.LogRetentionConfig = *r
+	if err := prj.UpdateByProjectID(d, "MaxRows", "RetentionHours"); err != nil {
+		return nil, status.DatabaseError.StatusErr().WithDesc(err.Error())
+	}
+	return prj, nil
+}
+
+// GetLogStats returns wasm log rows and last pruned time of project in context
+func GetLogStats(ctx context.Context) (*job.WasmLogStats, error) {
+	stats, err := job.GetWasmLogStats(ctx, types.MustProjectFromContext(ctx).Name)
+	if err != nil {
+		return nil, status.DatabaseError.StatusErr().WithDesc(err.Error())
+	}
+	return stats, nil
+}
+
+// Snapshot dumps the wasm database of project in context as gzip compressed sql
+func Snapshot(ctx context.Context) (io.ReadCloser, error) {
+	prj := types.MustProjectFromContext(ctx)
+
+	c, err := config.GetValueByRelAndType(ctx, prj.ProjectID, enums.CONFIG_TYPE__PROJECT_DATABASE)
+	if err != nil {
+		return nil, err
+	}
+	db := c.(*wasm.Database)
+	if err = db.Open(ctx); err != nil {
+		return nil, status.DatabaseError.StatusErr().WithDesc(err.Error())
+	}
+	rc, err := db.Snapshot(ctx)
+	if err != nil {
+		_ = db.Close()
+		return nil, status.DatabaseError.StatusErr().WithDesc(err.Error())
+	}
+	return &snapshotReader{ReadCloser: rc, db: db}, nil
+}
+
+// snapshotReader closes the database connections after snapshot stream closed
+type snapshotReader struct {
+	io.ReadCloser
+	db *wasm.Database
+}
+
+func (r *snapshotReader) Close() error {
+	err := r.ReadCloser.Close()
+	if cerr := r.db.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// IndexStats returns the index usage statistics of the wasm database of
+// project in context
+func IndexStats(ctx context.Context) ([]wasm.IndexStat, error) {
+	prj := types.MustProjectFromContext(ctx)
+
+	c, err := config.GetValueByRelAndType(ctx, prj.ProjectID, enums.CONFIG_TYPE__PROJECT_DATABASE)
+	if err != nil {
+		return nil, err
+	}
+	db := c.(*wasm.Database)
+	if err = db.Open(ctx); err != nil {
+		return nil, status.DatabaseError.StatusErr().WithDesc(err.Error())
+	}
+	defer db.Close()
+
+	stats, err := db.IndexStats(ctx)
+	if err != nil {
+		return nil, status.DatabaseError.StatusErr().WithDesc(err.Error())
+	}
+	return stats, nil
+}
+
+// IndexStatsRefreshInterval the interval of refreshing index scans metrics
+var IndexStatsRefreshInterval = 24 * time.Hour
+
+// RunIndexStatsRefresh refreshes the index scans metrics of all projects'
+// wasm databases periodically until ctx is canceled
+func RunIndexStatsRefresh(ctx context.Context) {
+	RefreshIndexStats(ctx)
+
+	ticker := time.NewTicker(IndexStatsRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			RefreshIndexStats(ctx)
+		}
+	}
+}
+
+var (
+	indexScansMtx sync.Mutex
+	// indexScansSeries the series of index scans metrics emitted by last
+	// refresh, keyed by project name and labeled by schema, table and index
+	indexScansSeries = make(map[string]map[[3]string]struct{})
+)
+
+func RefreshIndexStats(ctx context.Context) {
+	ctx, l := logr.Start(ctx, "modules.project.RefreshIndexStats")
+	defer l.End()
+
+	prjs, err := (&models.Project{}).List(types.MustMgrDBExecutorFromContext(ctx), nil)
+	if err != nil {
+		l.Error(err)
+		return
+	}
+	indexScansMtx.Lock()
+	defer indexScansMtx.Unlock()
+
+	refreshed := make(map[string]map[[3]string]struct{})
+	for i := range prjs {
+		prj := &prjs[i]
+		stats, err := IndexStats(types.WithProject(ctx, prj))
+		if err != nil {
+			l.WithValues("prj", prj.Name).Warn(err)
+			// keeps the series of project failed to refresh
+			refreshed[prj.Name] = indexScansSeries[prj.Name]
+			continue
+		}
+		series := make(map[[3]string]struct{}, len(stats))
+		for _, s := range stats {
+			metrics.DBIndexScansMtc.WithLabelValues(prj.Name, s.SchemaName, s.TableName, s.IndexName).Set(float64(s.IndexScans))
+			series[[3]string{s.SchemaName, s.TableName, s.IndexName}] = struct{}{}
+		}
+		refreshed[prj.Name] = series
+	}
+
+	// deletes the series of indexes or projects dropped since last refresh
+	for prj, series := range indexScansSeries {
+		for k := range series {
+			if _, ok := refreshed[prj][k]; !ok {
+				metrics.DBIndexScansMtc.DeleteLabelValues(prj, k[0], k[1], k[2])
+			}
+		}
+	}
+	indexScansSeries = refreshed
+}
+
+// CollectTableRows emits the estimated rows of tables of the wasm database of
+// project in context
+func CollectTableRows(ctx context.Context) error {
+	prj := types.MustProjectFromContext(ctx)
+
+	c, err := config.GetValueByRelAndType(ctx, prj.ProjectID, enums.CONFIG_TYPE__PROJECT_DATABASE)
+	if err != nil {
+		return err
+	}
+	db := c.(*wasm.Database)
+	if err = db.Open(ctx); err != nil {
+		return status.DatabaseError.StatusErr().WithDesc(err.Error())
+	}
+	defer db.Close()
+
+	if err = db.CollectTableRows(ctx); err != nil {
+		return status.DatabaseError.StatusErr().WithDesc(err.Error())
+	}
+	return nil
+}
+
+// RunTableRowsCollection collects the estimated table rows of all projects'
+// wasm databases every WasmDBConfig.TableMetricsInterval until ctx is
+// canceled. it is the only collector of process, the projects removed are not
+// collected any more
+func RunTableRowsCollection(ctx context.Context) {
+	interval := types.MustWasmDBConfigFromContext(ctx).TableMetricsInterval.Duration()
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			CollectAllTableRows(ctx)
+		}
+	}
+}
+
+func CollectAllTableRows(ctx context.Context) {
+	ctx, l := logr.Start(ctx, "modules.project.CollectAllTableRows")
+	defer l.End()
+
+	prjs, err := (&models.Project{}).List(types.MustMgrDBExecutorFromContext(ctx), nil)
+	if err != nil {
+		l.Error(err)
+		return
+	}
+	for i := range prjs {
+		prj := &prjs[i]
+		if err = CollectTableRows(types.WithProject(ctx, prj)); err != nil {
+			l.WithValues("prj", prj.Name).Warn(err)
+		}
+	}
+}
+
+// KVStats returns the storage usage of wasm kv namespace, the bytes stored by
+// project are reported as UsedBytes
+func KVStats(ctx context.Context) (*kvdb.NamespaceStats, error) {
+	prj := types.MustProjectFromContext(ctx)
+	stats, err := kvdb.NewProjectRedisDB(types.MustRedisEndpointFromContext(ctx), prj.ProjectID.String()).Stats()
+	if err != nil {
+		return nil, status.InternalServerError.StatusErr().WithDesc(err.Error())
+	}
+	return stats, nil
+}
+
 func RemoveBySFID(ctx context.Context, id types.SFID) (err error) {
 	ctx, l := logr.Start(ctx, "modules.project.RemoveBySFID", "porject_id", id)
 	defer l.End()
@@ -203,7 +448,7 @@ func RemoveBySFID(ctx context.Context, id types.SFID) (err error) {
 		p *models.Project
 	)
 
-	return sqlx.NewTasks(d).With(
+	err = sqlx.NewTasks(d).With(
 		func(d sqlx.DBExecutor) error {
 			ctx := types.WithMgrDBExecutor(ctx, d)
 			if p, err = GetBySFID(ctx, id); err != nil {
@@ -229,6 +474,11 @@ func RemoveBySFID(ctx context.Context, id types.SFID) (err error) {
 			return applet.Remove(ctx, &applet.CondArgs{ProjectID: p.ProjectID})
 		},
 	).Do()
+	if err != nil {
+		return err
+	}
+	event.RemoveHandlerLimiter(p.ProjectID)
+	return nil
 }
 
 func Init(ctx context.Context) error {
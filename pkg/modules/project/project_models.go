@@ -1,6 +1,7 @@
 package project
 
 import (
+	conflog "github.com/machinefi/w3bstream/pkg/depends/conf/log"
 	"github.com/machinefi/w3bstream/pkg/depends/kit/sqlx/builder"
 	"github.com/machinefi/w3bstream/pkg/depends/kit/sqlx/datatypes"
 	"github.com/machinefi/w3bstream/pkg/models"
@@ -61,11 +62,18 @@ type ListDetailRsp struct {
 type CreateReq struct {
 	models.ProjectName
 	models.ProjectBase
+	models.ProjectConfig
 	Env      *wasm.Env      `json:"envs,omitempty"`
 	Database *wasm.Database `json:"database,omitempty"`
 	Flow     *wasm.Flow     `json:"flow,omitempty"`
 }
 
+type SetLogLevelReq struct {
+	// Level minimum wasm log level, one of trace, debug, info, warning, error,
+	// fatal and panic
+	Level conflog.Level `json:"level"`
+}
+
 type CreateRsp struct {
 	*models.Project
 	Env          *wasm.Env      `json:"envs,omitempty"`
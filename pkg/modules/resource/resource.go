@@ -17,8 +17,8 @@ import (
 	"github.com/machinefi/w3bstream/pkg/types"
 )
 
-func Create(ctx context.Context, acc types.SFID, fh *multipart.FileHeader, filename, md5 string) (*models.Resource, []byte, error) {
-	data, sum, err := CheckFileMd5SumAndGetData(ctx, fh, md5)
+func Create(ctx context.Context, acc types.SFID, fh *multipart.FileHeader, filename, md5, sha256 string) (*models.Resource, []byte, error) {
+	data, sum, err := CheckFileMd5SumAndGetData(ctx, fh, md5, sha256)
 	if err != nil {
 		return nil, nil, err
 	}
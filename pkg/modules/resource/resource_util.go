@@ -3,10 +3,12 @@ package resource
 import (
 	"context"
 	"crypto/md5"
+	"crypto/sha256"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"os"
+	"strings"
 
 	"github.com/shirou/gopsutil/v3/disk"
 
@@ -17,12 +19,20 @@ import (
 
 var reserve = int64(100 * 1024 * 1024)
 
-func CheckFileMd5SumAndGetData(ctx context.Context, fh *multipart.FileHeader, md5Str string) (data []byte, sum string, err error) {
+// CheckFileMd5SumAndGetData reads the uploaded file and computes its md5 and
+// sha256 digests in one pass, the digests are checked if md5Str or sha256Str
+// assigned. the hex md5 sum is returned as the key of resource
+func CheckFileMd5SumAndGetData(ctx context.Context, fh *multipart.FileHeader, md5Str, sha256Str string) (data []byte, sum string, err error) {
 	uploadConf := types.MustUploadConfigFromContext(ctx)
 
 	limit := uploadConf.FilesizeLimitBytes
 	diskReserve := uploadConf.DiskReserveBytes
 
+	if !uploadConf.IsAllowedExtension(fh.Filename) {
+		err = status.UnsupportedFileType.StatusErr().WithDesc(fh.Filename)
+		return
+	}
+
 	if diskReserve != 0 {
 		info, _err := disk.Usage(os.TempDir())
 		if _err != nil {
@@ -42,29 +52,39 @@ func CheckFileMd5SumAndGetData(ctx context.Context, fh *multipart.FileHeader, md
 	}
 	defer f.Close()
 
-	data, err = io.ReadAll(f)
-	if err != nil {
-		return
-	}
-
+	// compute digests while reading, and read at most limit+1 bytes to detect
+	// oversize content without loading the whole file
+	var (
+		hash    = md5.New()
+		hash256 = sha256.New()
+		r       = io.Reader(f)
+	)
 	if limit > 0 {
-		if int64(len(data)) > limit {
+		if fh.Size > limit {
 			err = status.UploadFileSizeLimit
 			return
 		}
+		r = io.LimitReader(f, limit+1)
 	}
 
-	hash := md5.New()
-	_, err = hash.Write(data)
+	data, err = io.ReadAll(io.TeeReader(r, io.MultiWriter(hash, hash256)))
 	if err != nil {
 		return
 	}
+	if limit > 0 && int64(len(data)) > limit {
+		err = status.UploadFileSizeLimit
+		return
+	}
 
 	sum = fmt.Sprintf("%x", hash.Sum(nil))
 	if md5Str != "" && sum != md5Str {
 		err = status.UploadFileMd5Unmatched
 		return
 	}
+	if sha256Str != "" && fmt.Sprintf("%x", hash256.Sum(nil)) != strings.ToLower(sha256Str) {
+		err = status.UploadFileSha256Unmatched
+		return
+	}
 	return
 }
 
@@ -1,7 +1,12 @@
 package resource
 
 import (
+	"bytes"
 	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"fmt"
+	"mime/multipart"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -107,3 +112,39 @@ func TestResource(t *testing.T) {
 		})
 	})
 }
+
+func TestCheckFileMd5SumAndGetData(t *testing.T) {
+	content := []byte("wasm code")
+	md5Sum := fmt.Sprintf("%x", md5.Sum(content))
+	sha256Sum := fmt.Sprintf("%x", sha256.Sum256(content))
+
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	fw, err := w.CreateFormFile("file", "code.wasm")
+	NewWithT(t).Expect(err).To(BeNil())
+	_, err = fw.Write(content)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(w.Close()).To(BeNil())
+	form, err := multipart.NewReader(body, w.Boundary()).ReadForm(1 << 20)
+	NewWithT(t).Expect(err).To(BeNil())
+	fh := form.File["file"][0]
+
+	ctx := types.WithUploadConfig(context.Background(), &types.UploadConfig{
+		FilesizeLimitBytes: 1 << 20,
+		AllowedExtensions:  []string{".wasm"},
+	})
+
+	data, sum, err := CheckFileMd5SumAndGetData(ctx, fh, md5Sum, sha256Sum)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(data).To(Equal(content))
+	NewWithT(t).Expect(sum).To(Equal(md5Sum))
+
+	_, _, err = CheckFileMd5SumAndGetData(ctx, fh, "", "")
+	NewWithT(t).Expect(err).To(BeNil())
+
+	_, _, err = CheckFileMd5SumAndGetData(ctx, fh, "", md5Sum)
+	NewWithT(t).Expect(err).To(Equal(status.UploadFileSha256Unmatched))
+
+	_, _, err = CheckFileMd5SumAndGetData(ctx, fh, sha256Sum, "")
+	NewWithT(t).Expect(err).To(Equal(status.UploadFileMd5Unmatched))
+}
@@ -0,0 +1,93 @@
+package strategy
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strings"
+
+	"github.com/machinefi/w3bstream/pkg/depends/x/mapx"
+	"github.com/machinefi/w3bstream/pkg/enums"
+	"github.com/machinefi/w3bstream/pkg/types"
+)
+
+const (
+	// MaxPatternLength the max length of prefix or regex event type pattern
+	MaxPatternLength = 256
+	// maxPatternInsts the max instructions of compiled regex pattern
+	maxPatternInsts = 1000
+)
+
+// patterns compiled regex patterns keyed by pattern
+var patterns = mapx.New[string, *regexp.Regexp]()
+
+// ValidatePattern checks the event type pattern of match mode. regex patterns
+// are compiled as RE2 which matches in linear time and rejects backtracking
+// constructs such as backreferences and lookarounds, and the size of compiled
+// program is limited to avoid patterns like `(a{100}){100}` blowing up.
+func ValidatePattern(mode enums.StrategyMatchMode, pattern string) error {
+	switch mode {
+	case enums.STRATEGY_MATCH_MODE_UNKNOWN, enums.STRATEGY_MATCH_MODE__EXACT:
+		return nil
+	case enums.STRATEGY_MATCH_MODE__PREFIX, enums.STRATEGY_MATCH_MODE__REGEX:
+	default:
+		return fmt.Errorf("invalid match mode: %d", mode)
+	}
+	if pattern == "" || len(pattern) > MaxPatternLength {
+		return fmt.Errorf("pattern length must be in [1, %d]", MaxPatternLength)
+	}
+	if mode == enums.STRATEGY_MATCH_MODE__PREFIX {
+		return nil
+	}
+
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return fmt.Errorf("invalid regex pattern: %v", err)
+	}
+	prog, err := syntax.Compile(re.Simplify())
+	if err != nil {
+		return fmt.Errorf("invalid regex pattern: %v", err)
+	}
+	if n := len(prog.Inst); n > maxPatternInsts {
+		return fmt.Errorf("regex pattern too complex: %d instructions exceeds %d", n, maxPatternInsts)
+	}
+	return nil
+}
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	return patterns.LoadOrStore(pattern, func() (*regexp.Regexp, error) {
+		if err := ValidatePattern(enums.STRATEGY_MATCH_MODE__REGEX, pattern); err != nil {
+			return nil, err
+		}
+		return regexp.Compile(pattern)
+	})
+}
+
+// matchPatterns filters the prefix and regex strategies matching tpe and
+// sorts them by specificity: prefix strategies by prefix length descending,
+// then regex strategies. invalid regex patterns never match
+func matchPatterns(data []*types.StrategyResult, tpe string) []*types.StrategyResult {
+	matched := make([]*types.StrategyResult, 0, len(data))
+	for _, v := range data {
+		switch v.MatchMode {
+		case enums.STRATEGY_MATCH_MODE__PREFIX:
+			if strings.HasPrefix(tpe, v.EventType) {
+				matched = append(matched, v)
+			}
+		case enums.STRATEGY_MATCH_MODE__REGEX:
+			if re, err := compilePattern(v.EventType); err == nil && re.MatchString(tpe) {
+				matched = append(matched, v)
+			}
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		mi, mj := matched[i].MatchMode, matched[j].MatchMode
+		if mi != mj {
+			return mi < mj
+		}
+		return mi == enums.STRATEGY_MATCH_MODE__PREFIX &&
+			len(matched[i].EventType) > len(matched[j].EventType)
+	})
+	return matched
+}
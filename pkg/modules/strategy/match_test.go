@@ -0,0 +1,36 @@
+package strategy
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/machinefi/w3bstream/pkg/enums"
+	"github.com/machinefi/w3bstream/pkg/types"
+)
+
+func TestValidatePattern(t *testing.T) {
+	NewWithT(t).Expect(ValidatePattern(enums.STRATEGY_MATCH_MODE__EXACT, "")).To(BeNil())
+	NewWithT(t).Expect(ValidatePattern(enums.STRATEGY_MATCH_MODE__PREFIX, "sensor.")).To(BeNil())
+	NewWithT(t).Expect(ValidatePattern(enums.STRATEGY_MATCH_MODE__PREFIX, "")).NotTo(BeNil())
+	NewWithT(t).Expect(ValidatePattern(enums.STRATEGY_MATCH_MODE__REGEX, `^sensor\.[a-z]+\.critical$`)).To(BeNil())
+	NewWithT(t).Expect(ValidatePattern(enums.STRATEGY_MATCH_MODE__REGEX, `(a)\1`)).NotTo(BeNil())
+	NewWithT(t).Expect(ValidatePattern(enums.STRATEGY_MATCH_MODE__REGEX, `((a{50}){50})`)).NotTo(BeNil())
+}
+
+func TestMatchPatterns(t *testing.T) {
+	data := []*types.StrategyResult{
+		{Handler: "any", EventType: `^sensor\..*`, MatchMode: enums.STRATEGY_MATCH_MODE__REGEX},
+		{Handler: "short", EventType: "sensor.", MatchMode: enums.STRATEGY_MATCH_MODE__PREFIX},
+		{Handler: "long", EventType: "sensor.temperature.", MatchMode: enums.STRATEGY_MATCH_MODE__PREFIX},
+		{Handler: "other", EventType: "device.", MatchMode: enums.STRATEGY_MATCH_MODE__PREFIX},
+		{Handler: "bad", EventType: `(a)\1`, MatchMode: enums.STRATEGY_MATCH_MODE__REGEX},
+	}
+
+	handlers := make([]string, 0)
+	for _, v := range matchPatterns(data, "sensor.temperature.critical") {
+		handlers = append(handlers, v.Handler)
+	}
+	NewWithT(t).Expect(handlers).To(Equal([]string{"long", "short", "any"}))
+	NewWithT(t).Expect(matchPatterns(data, "gateway.online")).To(BeEmpty())
+}
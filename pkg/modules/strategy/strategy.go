@@ -14,6 +14,10 @@ import (
 )
 
 func Update(ctx context.Context, id types.SFID, r *UpdateReq) (err error) {
+	if err = checkMatchMode(&r.StrategyInfo); err != nil {
+		return err
+	}
+
 	var m *models.Strategy
 
 	return sqlx.NewTasks(types.MustMgrDBExecutorFromContext(ctx)).With(
@@ -109,6 +113,7 @@ func ListDetailByCond(ctx context.Context, r *CondArgs, adds ...builder.Addition
 		builder.Alias(sty.ColHandler(), "f_hdl"),
 		builder.Alias(sty.ColEventType(), "f_evt"),
 		builder.Alias(sty.ColAutoCollectMetric(), "f_auto_collect"),
+		builder.Alias(sty.ColMatchMode(), "f_match_mode"),
 		builder.Alias(sty.ColUpdatedAt(), "f_updated_at"),
 		builder.Alias(sty.ColCreatedAt(), "f_created_at"),
 	)).From(
@@ -168,6 +173,10 @@ func Remove(ctx context.Context, r *CondArgs) error {
 }
 
 func Create(ctx context.Context, r *CreateReq) (*models.Strategy, error) {
+	if err := checkMatchMode(&r.StrategyInfo); err != nil {
+		return nil, err
+	}
+
 	var (
 		idg = confid.MustNewSFIDGenerator()
 		app *models.Applet
@@ -220,6 +229,11 @@ func BatchCreate(ctx context.Context, sty []models.Strategy) error {
 	if len(sty) == 0 {
 		return nil
 	}
+	for i := range sty {
+		if err := checkMatchMode(&sty[i].StrategyInfo); err != nil {
+			return err
+		}
+	}
 
 	return sqlx.NewTasks(types.MustMgrDBExecutorFromContext(ctx)).With(
 		func(d sqlx.DBExecutor) error {
@@ -242,27 +256,62 @@ func BatchCreate(ctx context.Context, sty []models.Strategy) error {
 	).Do()
 }
 
+// FilterByProjectAndEvent finds the strategies of project routing event type
+// tpe in order of specificity: the exact matched strategies, then the prefix
+// and regex matched strategies, and the default strategies at last
 func FilterByProjectAndEvent(ctx context.Context, id types.SFID, tpe string) ([]*types.StrategyResult, error) {
 	data, err := ListDetailByCond(ctx, &CondArgs{
-		ProjectID: id, EventTypes: []string{tpe}},
+		ProjectID: id, EventTypes: []string{tpe}, MatchModes: exactModes},
 	)
 	if err != nil {
 		return nil, err
 	}
-
-	if len(data) == 0 {
-		data, err = ListDetailByCond(ctx, &CondArgs{
-			ProjectID: id, EventTypes: []string{enums.EVENTTYPEDEFAULT}},
-		)
-		if err != nil {
-			return nil, err
-		}
+	results := make([]*types.StrategyResult, 0, len(data))
+	for i := range data {
+		results = append(results, &data[i].StrategyResult)
+	}
+	if len(results) > 0 {
+		return results, nil
 	}
 
-	results := make([]*types.StrategyResult, 0, len(data))
+	data, err = ListDetailByCond(ctx, &CondArgs{
+		ProjectID: id, MatchModes: patternModes},
+	)
+	if err != nil {
+		return nil, err
+	}
 	for i := range data {
 		results = append(results, &data[i].StrategyResult)
 	}
+	if results = matchPatterns(results, tpe); len(results) > 0 {
+		return results, nil
+	}
 
+	data, err = ListDetailByCond(ctx, &CondArgs{
+		ProjectID: id, EventTypes: []string{enums.EVENTTYPEDEFAULT}, MatchModes: exactModes},
+	)
+	if err != nil {
+		return nil, err
+	}
+	for i := range data {
+		results = append(results, &data[i].StrategyResult)
+	}
 	return results, nil
 }
+
+var (
+	exactModes   = []enums.StrategyMatchMode{enums.STRATEGY_MATCH_MODE_UNKNOWN, enums.STRATEGY_MATCH_MODE__EXACT}
+	patternModes = []enums.StrategyMatchMode{enums.STRATEGY_MATCH_MODE__PREFIX, enums.STRATEGY_MATCH_MODE__REGEX}
+)
+
+// checkMatchMode validates the event type pattern and defaults match mode to
+// exact
+func checkMatchMode(info *models.StrategyInfo) error {
+	if info.MatchMode == enums.STRATEGY_MATCH_MODE_UNKNOWN {
+		info.MatchMode = enums.STRATEGY_MATCH_MODE__EXACT
+	}
+	if err := ValidatePattern(info.MatchMode, info.EventType); err != nil {
+		return status.BadRequest.StatusErr().WithDesc(err.Error())
+	}
+	return nil
+}
@@ -3,16 +3,18 @@ package strategy
 import (
 	"github.com/machinefi/w3bstream/pkg/depends/kit/sqlx/builder"
 	"github.com/machinefi/w3bstream/pkg/depends/kit/sqlx/datatypes"
+	"github.com/machinefi/w3bstream/pkg/enums"
 	"github.com/machinefi/w3bstream/pkg/models"
 	"github.com/machinefi/w3bstream/pkg/types"
 )
 
 type CondArgs struct {
-	ProjectID   types.SFID   `name:"-"`
-	AppletIDs   []types.SFID `in:"query" name:"appletID,omitempty"`
-	StrategyIDs []types.SFID `in:"query" name:"strategyID,omitempty"`
-	EventTypes  []string     `in:"query" name:"eventType,omitempty"`
-	Handlers    []string     `in:"query" name:"handler,omitempty"`
+	ProjectID   types.SFID                `name:"-"`
+	AppletIDs   []types.SFID              `in:"query" name:"appletID,omitempty"`
+	StrategyIDs []types.SFID              `in:"query" name:"strategyID,omitempty"`
+	EventTypes  []string                  `in:"query" name:"eventType,omitempty"`
+	Handlers    []string                  `in:"query" name:"handler,omitempty"`
+	MatchModes  []enums.StrategyMatchMode `in:"query" name:"matchMode,omitempty"`
 }
 
 func (r *CondArgs) Condition() builder.SqlCondition {
@@ -36,6 +38,9 @@ func (r *CondArgs) Condition() builder.SqlCondition {
 	if len(r.Handlers) > 0 {
 		cs = append(cs, m.ColHandler().In(r.Handlers))
 	}
+	if len(r.MatchModes) > 0 {
+		cs = append(cs, m.ColMatchMode().In(r.MatchModes))
+	}
 	cs = append(cs, m.ColDeletedAt().Eq(0))
 
 	return builder.And(cs...)
@@ -2,95 +2,171 @@ package vm
 
 import (
 	"context"
+	"io"
+	"strings"
+	"sync"
 
 	"github.com/pkg/errors"
 
 	"github.com/machinefi/w3bstream/pkg/depends/kit/logr"
-	"github.com/machinefi/w3bstream/pkg/depends/x/mapx"
 	"github.com/machinefi/w3bstream/pkg/enums"
 	"github.com/machinefi/w3bstream/pkg/types"
 	"github.com/machinefi/w3bstream/pkg/types/wasm"
 )
 
-var instances = mapx.New[types.SFID, wasm.Instance]()
+// managedInstance wasm instance with its lifecycle state
+type managedInstance struct {
+	*statsInstance
+	state enums.InstanceState
+}
+
+var (
+	// mtx guards instances and the state of each instance
+	mtx       sync.RWMutex
+	instances = make(map[types.SFID]*managedInstance)
+)
 
 var (
-	ErrNotFound = errors.New("instance not found")
+	ErrNotFound               = errors.New("instance not found")
+	ErrInvalidStateTransition = errors.New("invalid instance state transition")
 )
 
+// transitions the allowed target states of each state. a created instance can
+// be stopped without started, eg: deployed with hung up state
+var transitions = map[enums.InstanceState][]enums.InstanceState{
+	enums.INSTANCE_STATE__CREATED: {enums.INSTANCE_STATE__STARTED, enums.INSTANCE_STATE__STOPPED},
+	enums.INSTANCE_STATE__STARTED: {enums.INSTANCE_STATE__STOPPED},
+	enums.INSTANCE_STATE__STOPPED: {enums.INSTANCE_STATE__STARTED},
+}
+
+func checkTransition(from, to enums.InstanceState) error {
+	for _, s := range transitions[from] {
+		if s == to {
+			return nil
+		}
+	}
+	return errors.Wrapf(ErrInvalidStateTransition, "%s -> %s", from, to)
+}
+
+func loadInstance(id types.SFID) (*managedInstance, bool) {
+	mtx.RLock()
+	defer mtx.RUnlock()
+
+	i, ok := instances[id]
+	return i, ok
+}
+
 func AddInstanceByID(ctx context.Context, id types.SFID, i wasm.Instance) {
 	ctx, l := logr.Start(ctx, "modules.vm.AddInstanceByID")
 	defer l.End()
 
-	instances.Store(id, i)
+	mtx.Lock()
+	prev, replaced := instances[id]
+	instances[id] = &managedInstance{
+		statsInstance: newStatsInstance(i),
+		state:         enums.INSTANCE_STATE__CREATED,
+	}
+	mtx.Unlock()
+	if replaced {
+		closeInstance(l, prev)
+	}
 	l.WithValues("instance", id).Info("created")
 }
 
-func DelInstance(ctx context.Context, id types.SFID) error {
-	ctx, l := logr.Start(ctx, "modules.vm.DelInstance")
-	defer l.End()
-
-	i, _ := instances.LoadAndRemove(id)
-	if i == nil {
-		return ErrNotFound
+// closeInstance releases the resources held by instance if it is closable
+func closeInstance(l logr.Logger, i *managedInstance) {
+	if c, ok := i.Instance.(io.Closer); ok {
+		if err := c.Close(); err != nil {
+			l.Warn(errors.Wrap(err, "close instance"))
+		}
 	}
-	return i.Stop(ctx)
 }
 
-func StartInstance(ctx context.Context, id types.SFID) error {
-	ctx, l := logr.Start(ctx, "modules.vm.StartInstance")
+// DelInstance removes the instance, the instance must be stopped before removal
+func DelInstance(ctx context.Context, id types.SFID) error {
+	ctx, l := logr.Start(ctx, "modules.vm.DelInstance")
 	defer l.End()
 
-	l = l.WithValues("instance", id)
+	mtx.Lock()
+	defer mtx.Unlock()
 
-	i, ok := instances.Load(id)
+	i, ok := instances[id]
 	if !ok {
-		l.Error(ErrNotFound)
 		return ErrNotFound
 	}
-
-	if i.State() == enums.INSTANCE_STATE__STARTED {
-		return nil
-	}
-
-	if err := i.Start(ctx); err != nil {
-		l.Error(err)
+	if i.state != enums.INSTANCE_STATE__STOPPED {
+		err := errors.Wrapf(ErrInvalidStateTransition, "delete %s instance", i.state)
+		l.WithValues("instance", id).Error(err)
 		return err
 	}
-	l.Info("started")
+	delete(instances, id)
+	closeInstance(l, i)
+	l.WithValues("instance", id).Info("deleted")
 	return nil
 }
 
+func StartInstance(ctx context.Context, id types.SFID) error {
+	ctx, l := logr.Start(ctx, "modules.vm.StartInstance")
+	defer l.End()
+
+	return transitInstance(ctx, l.WithValues("instance", id), id, enums.INSTANCE_STATE__STARTED)
+}
+
 func StopInstance(ctx context.Context, id types.SFID) error {
 	ctx, l := logr.Start(ctx, "modules.vm.StopInstance")
 	defer l.End()
 
-	l = l.WithValues("instance", id)
+	return transitInstance(ctx, l.WithValues("instance", id), id, enums.INSTANCE_STATE__STOPPED)
+}
+
+// transitInstance starts or stops the instance, it does nothing if instance
+// is in state `to` already
+func transitInstance(ctx context.Context, l logr.Logger, id types.SFID, to enums.InstanceState) error {
+	mtx.Lock()
+	defer mtx.Unlock()
 
-	i, ok := instances.Load(id)
+	i, ok := instances[id]
 	if !ok {
 		l.Warn(ErrNotFound)
 		return ErrNotFound
 	}
-	if err := i.Stop(ctx); err != nil {
+	if i.state == to {
+		return nil
+	}
+	if err := checkTransition(i.state, to); err != nil {
 		l.Error(err)
 		return err
 	}
-	l.Info("stopped")
+
+	var err error
+	if to == enums.INSTANCE_STATE__STARTED {
+		err = i.Start(ctx)
+	} else {
+		err = i.Stop(ctx)
+	}
+	if err != nil {
+		l.Error(err)
+		return err
+	}
+	i.state = to
+	l.Info(strings.ToLower(to.String()))
 	return nil
 }
 
 func GetInstanceState(id types.SFID) (enums.InstanceState, bool) {
-	i, ok := instances.Load(id)
+	mtx.RLock()
+	defer mtx.RUnlock()
+
+	i, ok := instances[id]
 	if !ok {
 		return enums.INSTANCE_STATE_UNKNOWN, false
 	}
-	return i.State(), true
+	return i.state, true
 }
 
 func GetConsumer(id types.SFID) wasm.Instance {
-	i, ok := instances.Load(id)
-	if !ok || i == nil {
+	i, ok := loadInstance(id)
+	if !ok {
 		return nil
 	}
 	return i
@@ -18,5 +18,12 @@ func NewInstance(ctx context.Context, code []byte, id types.SFID, state enums.In
 		return err
 	}
 	AddInstanceByID(ctx, id, ins)
+
+	switch state {
+	case enums.INSTANCE_STATE__STARTED:
+		return StartInstance(ctx, id)
+	case enums.INSTANCE_STATE__STOPPED:
+		return StopInstance(ctx, id)
+	}
 	return nil
 }
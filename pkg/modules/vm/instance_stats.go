@@ -0,0 +1,118 @@
+package vm
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/machinefi/w3bstream/pkg/types"
+	"github.com/machinefi/w3bstream/pkg/types/wasm"
+)
+
+// InstanceStats runtime statistics of instance
+type InstanceStats struct {
+	// TotalEvents events handled by instance
+	TotalEvents int64 `json:"totalEvents"`
+	// TotalErrors events handled failed
+	TotalErrors int64 `json:"totalErrors"`
+	// LastActiveAt the last time instance handled event
+	LastActiveAt types.Timestamp `json:"lastActiveAt"`
+	// StartedAt the last time instance started
+	StartedAt types.Timestamp `json:"startedAt"`
+}
+
+// statsInstance wraps wasm.Instance to collect statistics
+type statsInstance struct {
+	wasm.Instance
+	events     atomic.Int64
+	errors     atomic.Int64
+	lastActive atomic.Int64 // unix nano
+	started    atomic.Int64 // unix nano
+}
+
+func newStatsInstance(i wasm.Instance) *statsInstance {
+	s := &statsInstance{Instance: i}
+	s.started.Store(time.Now().UnixNano())
+	return s
+}
+
+func (s *statsInstance) Start(ctx context.Context) error {
+	if err := s.Instance.Start(ctx); err != nil {
+		return err
+	}
+	s.started.Store(time.Now().UnixNano())
+	return nil
+}
+
+func (s *statsInstance) HandleEvent(ctx context.Context, handler, eventType string, payload []byte) *wasm.EventHandleResult {
+	ret := s.Instance.HandleEvent(ctx, handler, eventType, payload)
+	s.events.Add(1)
+	if ret == nil || ret.Code != wasm.ResultStatusCode_OK {
+		s.errors.Add(1)
+	}
+	s.lastActive.Store(time.Now().UnixNano())
+	return ret
+}
+
+func (s *statsInstance) Stats() *InstanceStats {
+	ret := &InstanceStats{
+		TotalEvents: s.events.Load(),
+		TotalErrors: s.errors.Load(),
+	}
+	if ts := s.lastActive.Load(); ts > 0 {
+		ret.LastActiveAt = types.Timestamp{Time: time.Unix(0, ts)}
+	}
+	if ts := s.started.Load(); ts > 0 {
+		ret.StartedAt = types.Timestamp{Time: time.Unix(0, ts)}
+	}
+	return ret
+}
+
+func GetInstanceStats(id types.SFID) (*InstanceStats, bool) {
+	i, ok := loadInstance(id)
+	if !ok {
+		return nil, false
+	}
+	return i.Stats(), true
+}
+
+var (
+	instanceEventsDesc = prometheus.NewDesc(
+		"w3b_instance_events_total", "events handled by wasm instance.", []string{"instance"}, nil,
+	)
+	instanceErrorsDesc = prometheus.NewDesc(
+		"w3b_instance_errors_total", "events handled failed by wasm instance.", []string{"instance"}, nil,
+	)
+	instanceLastActiveDesc = prometheus.NewDesc(
+		"w3b_instance_last_active_seconds", "unix time of wasm instance last handled event.", []string{"instance"}, nil,
+	)
+)
+
+// statsCollector exports statistics of all instances when scraping
+type statsCollector struct{}
+
+func (statsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- instanceEventsDesc
+	ch <- instanceErrorsDesc
+	ch <- instanceLastActiveDesc
+}
+
+func (statsCollector) Collect(ch chan<- prometheus.Metric) {
+	mtx.RLock()
+	defer mtx.RUnlock()
+
+	for id, i := range instances {
+		stats, label := i.Stats(), id.String()
+		ch <- prometheus.MustNewConstMetric(instanceEventsDesc, prometheus.CounterValue, float64(stats.TotalEvents), label)
+		ch <- prometheus.MustNewConstMetric(instanceErrorsDesc, prometheus.CounterValue, float64(stats.TotalErrors), label)
+		if !stats.LastActiveAt.IsZero() {
+			ch <- prometheus.MustNewConstMetric(instanceLastActiveDesc, prometheus.GaugeValue, float64(stats.LastActiveAt.Unix()), label)
+		}
+	}
+}
+
+func init() {
+	prometheus.MustRegister(statsCollector{})
+}
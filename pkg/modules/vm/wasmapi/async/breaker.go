@@ -0,0 +1,117 @@
+package async
+
+import (
+	"net/url"
+	"sync"
+	"time"
+)
+
+var (
+	// breakerFailureThreshold consecutive failures to open the breaker
+	breakerFailureThreshold = 5
+	// breakerOpenTimeout the duration of open state before half-open
+	breakerOpenTimeout = 30 * time.Second
+	// breakerTrialTimeout the duration of waiting for the result of trial
+	// call, another trial is allowed after it
+	breakerTrialTimeout = 30 * time.Second
+)
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// circuitBreaker stops calling an endpoint after consecutive failures, and
+// allows a trial call after breakerOpenTimeout. the breaker is closed if the
+// trial succeeds, otherwise it is opened again. if the result of trial is not
+// reported in breakerTrialTimeout, another trial is allowed
+type circuitBreaker struct {
+	state    breakerState
+	failures int
+	openedAt time.Time
+	trialAt  time.Time
+}
+
+// allow reports if endpoint can be called at now
+func (b *circuitBreaker) allow(now time.Time) bool {
+	switch b.state {
+	case breakerOpen:
+		if now.Sub(b.openedAt) < breakerOpenTimeout {
+			return false
+		}
+		b.state, b.trialAt = breakerHalfOpen, now
+		return true
+	case breakerHalfOpen:
+		if now.Sub(b.trialAt) < breakerTrialTimeout {
+			return false // trial call is in flight
+		}
+		b.trialAt = now
+		return true
+	default:
+		return true
+	}
+}
+
+// report records the result of call
+func (b *circuitBreaker) report(success bool, now time.Time) {
+	if success {
+		b.state, b.failures = breakerClosed, 0
+		return
+	}
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= breakerFailureThreshold {
+		b.state, b.openedAt = breakerOpen, now
+	}
+}
+
+// circuitBreakers breakers keyed by project and endpoint url without query,
+// so the failures of one project do not stop the calls of others. the breaker
+// is removed once a call succeeds, only the failing endpoints are kept
+type circuitBreakers struct {
+	mtx      sync.Mutex
+	breakers map[string]*circuitBreaker
+}
+
+var breakers = &circuitBreakers{breakers: make(map[string]*circuitBreaker)}
+
+func (bs *circuitBreakers) Allow(key string, now time.Time) bool {
+	bs.mtx.Lock()
+	defer bs.mtx.Unlock()
+
+	b, ok := bs.breakers[key]
+	if !ok {
+		return true
+	}
+	return b.allow(now)
+}
+
+func (bs *circuitBreakers) Report(key string, success bool, now time.Time) {
+	bs.mtx.Lock()
+	defer bs.mtx.Unlock()
+
+	b, ok := bs.breakers[key]
+	if !ok {
+		if success {
+			return
+		}
+		b = &circuitBreaker{}
+		bs.breakers[key] = b
+	}
+	b.report(success, now)
+	if success {
+		delete(bs.breakers, key)
+	}
+}
+
+// breakerEndpoint the endpoint of breaker, query and fragment are excluded
+func breakerEndpoint(u *url.URL) string {
+	return (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path}).String()
+}
+
+// breakerKey the key of breaker of project calling endpoint
+func breakerKey(project, endpoint string) string {
+	return project + "|" + endpoint
+}
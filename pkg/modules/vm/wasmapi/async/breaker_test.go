@@ -0,0 +1,69 @@
+package async
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	b := &circuitBreaker{}
+	now := time.Now()
+
+	for i := 0; i < breakerFailureThreshold; i++ {
+		NewWithT(t).Expect(b.allow(now)).To(BeTrue())
+		b.report(false, now)
+	}
+	NewWithT(t).Expect(b.allow(now)).To(BeFalse())
+
+	// half-open, only one trial is allowed and failure opens breaker again
+	now = now.Add(breakerOpenTimeout)
+	NewWithT(t).Expect(b.allow(now)).To(BeTrue())
+	NewWithT(t).Expect(b.allow(now)).To(BeFalse())
+	b.report(false, now)
+	NewWithT(t).Expect(b.allow(now)).To(BeFalse())
+
+	// trial succeeded, breaker is closed
+	now = now.Add(breakerOpenTimeout)
+	NewWithT(t).Expect(b.allow(now)).To(BeTrue())
+	b.report(true, now)
+	NewWithT(t).Expect(b.allow(now)).To(BeTrue())
+	NewWithT(t).Expect(b.failures).To(Equal(0))
+}
+
+func TestCircuitBreaker_TrialTimeout(t *testing.T) {
+	b := &circuitBreaker{}
+	now := time.Now()
+
+	for i := 0; i < breakerFailureThreshold; i++ {
+		b.report(false, now)
+	}
+	now = now.Add(breakerOpenTimeout)
+	NewWithT(t).Expect(b.allow(now)).To(BeTrue())
+
+	// the result of trial is lost, another trial is allowed after timeout
+	NewWithT(t).Expect(b.allow(now.Add(breakerTrialTimeout / 2))).To(BeFalse())
+	NewWithT(t).Expect(b.allow(now.Add(breakerTrialTimeout))).To(BeTrue())
+}
+
+func TestCircuitBreakers(t *testing.T) {
+	bs := &circuitBreakers{breakers: make(map[string]*circuitBreaker)}
+	now := time.Now()
+	k1 := breakerKey("prj1", "http://w3bstream.com/system/send_tx")
+	k2 := breakerKey("prj2", "http://w3bstream.com/system/send_tx")
+
+	for i := 0; i < breakerFailureThreshold; i++ {
+		bs.Report(k1, false, now)
+	}
+	NewWithT(t).Expect(bs.Allow(k1, now)).To(BeFalse())
+	NewWithT(t).Expect(bs.Allow(k2, now)).To(BeTrue())
+
+	// closed breaker is removed
+	bs.Report(k2, true, now)
+	NewWithT(t).Expect(bs.breakers).To(HaveLen(1))
+	now = now.Add(breakerOpenTimeout)
+	NewWithT(t).Expect(bs.Allow(k1, now)).To(BeTrue())
+	bs.Report(k1, true, now)
+	NewWithT(t).Expect(bs.breakers).To(BeEmpty())
+}
@@ -8,6 +8,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/hibiken/asynq"
@@ -61,40 +62,37 @@ func (p *ApiCallProcessor) ProcessTask(ctx context.Context, t *asynq.Task) error
 		types.WithLoggerContext(p.l),
 	)(ctx))
 
-	respRecorder := httptest.NewRecorder()
-	p.router.ServeHTTP(respRecorder, req)
-
 	projectName := payload.Project.ProjectName.Name
 	_, l := p.l.Start(ctx, "wasmapi.ProcessTaskApiCall")
 	defer l.End()
 	l = l.WithValues("ProjectName", projectName)
+	if payload.TraceID != "" {
+		l = l.WithValues("trace_id", payload.TraceID)
+	}
 
-	resp := respRecorder.Result()
-	var body []byte
-	if resp.Body != nil {
-		body, err = io.ReadAll(resp.Body)
+	var apiResp *apitypes.HttpResponse
+	endpoint := breakerEndpoint(req.URL)
+	key := breakerKey(projectName, endpoint)
+	if breakers.Allow(key, time.Now()) {
+		apiResp, err = p.serve(req, apiReq.Header)
 		if err != nil {
+			breakers.Report(key, false, time.Now())
 			l.Error(errors.Wrap(err, "encode http response failed"))
 			return fmt.Errorf("encode http response failed: %v: %w", err, asynq.SkipRetry)
 		}
-	}
-
-	respHeader := resp.Header
-	for k, v := range apiReq.Header {
-		if k == "Content-Type" {
-			continue
+		breakers.Report(key, apiResp.StatusCode < http.StatusInternalServerError, time.Now())
+	} else {
+		l.Warn(errors.Errorf("circuit breaker of %s is open", endpoint))
+		apiResp = &apitypes.HttpResponse{
+			Status:     fmt.Sprintf("%d %s", http.StatusServiceUnavailable, http.StatusText(http.StatusServiceUnavailable)),
+			StatusCode: http.StatusServiceUnavailable,
+			Proto:      "HTTP/1.1",
+			Header:     http.Header{},
+			Body:       []byte("circuit breaker is open"),
 		}
-		respHeader[k] = v
 	}
 
-	apiResp := apitypes.HttpResponse{
-		Status:     resp.Status,
-		StatusCode: resp.StatusCode,
-		Proto:      resp.Proto,
-		Header:     respHeader,
-		Body:       body,
-	}
-	apiRespJson, err := json.Marshal(&apiResp)
+	apiRespJson, err := json.Marshal(apiResp)
 	if err != nil {
 		l.Error(errors.Wrap(err, "encode http response failed"))
 		return fmt.Errorf("encode http response failed: %v: %w", err, asynq.SkipRetry)
@@ -106,7 +104,7 @@ func (p *ApiCallProcessor) ProcessTask(ctx context.Context, t *asynq.Task) error
 		return fmt.Errorf("miss eventType, projectName %v: %w", projectName, asynq.SkipRetry)
 	}
 
-	task, err := newApiResultTask(projectName, eventType, apiRespJson)
+	task, err := newApiResultTask(projectName, eventType, apiRespJson, payload.TraceID)
 	if err != nil {
 		l.Error(errors.Wrap(err, "new api result task failed"))
 		return fmt.Errorf("new api result task failed: %v: %w", err, asynq.SkipRetry)
@@ -119,6 +117,41 @@ func (p *ApiCallProcessor) ProcessTask(ctx context.Context, t *asynq.Task) error
 	return nil
 }
 
+// serve calls the api router and merges request header except Content-Type
+// into response header
+func (p *ApiCallProcessor) serve(req *http.Request, reqHeader http.Header) (*apitypes.HttpResponse, error) {
+	respRecorder := httptest.NewRecorder()
+	p.router.ServeHTTP(respRecorder, req)
+
+	resp := respRecorder.Result()
+	var (
+		body []byte
+		err  error
+	)
+	if resp.Body != nil {
+		body, err = io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	respHeader := resp.Header
+	for k, v := range reqHeader {
+		if k == "Content-Type" {
+			continue
+		}
+		respHeader[k] = v
+	}
+
+	return &apitypes.HttpResponse{
+		Status:     resp.Status,
+		StatusCode: resp.StatusCode,
+		Proto:      resp.Proto,
+		Header:     respHeader,
+		Body:       body,
+	}, nil
+}
+
 type ApiResultProcessor struct {
 	l     log.Logger
 	mgrDB sqlx.DBExecutor
@@ -154,9 +187,20 @@ func (p *ApiResultProcessor) ProcessTask(ctx context.Context, t *asynq.Task) err
 		),
 	)(ctx)
 
+	if payload.TraceID != "" {
+		ctx = types.WithTraceID(ctx, payload.TraceID)
+	}
+
 	_, l := p.l.Start(ctx, "wasmapi.ProcessTaskApiResult")
 	defer l.End()
 
+	sem := apiResultSemaphore()
+	if err := sem.acquire(ctx, apiResultAcquireTimeout); err != nil {
+		l.Warn(errors.Wrap(err, "dispatch api result"))
+		return fmt.Errorf("dispatch api result, projectName %v: %w", payload.ProjectName, err)
+	}
+	defer sem.release()
+
 	if _, err := event.HandleEvent(ctx, payload.EventType, payload.Data); err != nil {
 		l.Error(errors.Wrap(err, "send event failed"))
 		return err
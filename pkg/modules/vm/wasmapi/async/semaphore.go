@@ -0,0 +1,65 @@
+package async
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultMaxConcurrentApiResults the default max api results dispatched
+	// concurrently
+	DefaultMaxConcurrentApiResults = 50
+	// apiResultAcquireTimeout the max duration of waiting for a dispatch slot,
+	// the task is retried by asynq after timeout
+	apiResultAcquireTimeout = 5 * time.Second
+)
+
+var errAcquireTimeout = errors.New("acquire dispatch slot timeout")
+
+// semaphore limits the concurrent holders by a buffered channel
+type semaphore chan struct{}
+
+// acquire waits a slot until timeout or ctx done
+func (s semaphore) acquire(ctx context.Context, timeout time.Duration) error {
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+
+	select {
+	case s <- struct{}{}:
+		return nil
+	case <-t.C:
+		return errAcquireTimeout
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s semaphore) release() { <-s }
+
+var (
+	apiResultSemMtx sync.RWMutex
+	// apiResultSem back-pressure of ApiResultProcessor, it prevents workers
+	// dispatching events in parallel from saturating the db connection pool
+	apiResultSem = make(semaphore, DefaultMaxConcurrentApiResults)
+)
+
+// SetMaxConcurrentApiResults resets the max api results dispatched
+// concurrently, not positive means DefaultMaxConcurrentApiResults. it should
+// be called before the api server started
+func SetMaxConcurrentApiResults(n int) {
+	if n <= 0 {
+		n = DefaultMaxConcurrentApiResults
+	}
+	apiResultSemMtx.Lock()
+	defer apiResultSemMtx.Unlock()
+	apiResultSem = make(semaphore, n)
+}
+
+func apiResultSemaphore() semaphore {
+	apiResultSemMtx.RLock()
+	defer apiResultSemMtx.RUnlock()
+	return apiResultSem
+}
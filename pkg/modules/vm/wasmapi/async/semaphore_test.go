@@ -0,0 +1,28 @@
+package async
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestSemaphore(t *testing.T) {
+	s := make(semaphore, 2)
+	ctx := context.Background()
+
+	NewWithT(t).Expect(s.acquire(ctx, time.Millisecond)).To(BeNil())
+	NewWithT(t).Expect(s.acquire(ctx, time.Millisecond)).To(BeNil())
+	NewWithT(t).Expect(s.acquire(ctx, 10*time.Millisecond)).To(Equal(errAcquireTimeout))
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		s.release()
+	}()
+	NewWithT(t).Expect(s.acquire(ctx, time.Second)).To(BeNil())
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	NewWithT(t).Expect(s.acquire(canceled, time.Second)).To(Equal(context.Canceled))
+}
@@ -18,13 +18,16 @@ type apiCallPayload struct {
 	Project     *models.Project
 	ChainClient *wasm.ChainClient
 	Data        []byte
+	// TraceID trace id of the event which issued the api call
+	TraceID string
 }
 
-func NewApiCallTask(prj *models.Project, chainCli *wasm.ChainClient, data []byte) (*asynq.Task, error) {
+func NewApiCallTask(prj *models.Project, chainCli *wasm.ChainClient, data []byte, traceID string) (*asynq.Task, error) {
 	payload, err := json.Marshal(apiCallPayload{
 		Project:     prj,
 		ChainClient: chainCli,
 		Data:        data,
+		TraceID:     traceID,
 	})
 	if err != nil {
 		return nil, err
@@ -36,13 +39,16 @@ type apiResultPayload struct {
 	ProjectName string
 	EventType   string
 	Data        []byte
+	// TraceID trace id of the event which issued the api call
+	TraceID string
 }
 
-func newApiResultTask(projectName, eventType string, data []byte) (*asynq.Task, error) {
+func newApiResultTask(projectName, eventType string, data []byte, traceID string) (*asynq.Task, error) {
 	payload, err := json.Marshal(apiResultPayload{
 		ProjectName: projectName,
 		EventType:   eventType,
 		Data:        data,
+		TraceID:     traceID,
 	})
 	if err != nil {
 		return nil, err
@@ -35,7 +35,8 @@ func (s *Server) Call(ctx context.Context, data []byte) *apitypes.HttpResponse {
 
 	prj := types.MustProjectFromContext(ctx)
 	chainCli := wasm.MustChainClientFromContext(ctx)
-	task, err := async.NewApiCallTask(prj, chainCli, data)
+	traceID, _ := types.TraceIDFromContext(ctx)
+	task, err := async.NewApiCallTask(prj, chainCli, data, traceID)
 	if err != nil {
 		l.Error(errors.Wrap(err, "new api call task failed"))
 		return &apitypes.HttpResponse{
@@ -0,0 +1,54 @@
+package wasmtime
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/machinefi/w3bstream/pkg/types/wasm"
+)
+
+// valueResultFuncs host functions return values rather than status codes, the
+// PermissionDenied status returned by stub would be taken as a valid value, so
+// they are always linked. they have no side effect out of instance
+var valueResultFuncs = map[string]bool{
+	"ws_get_data_size":          true,
+	"ws_get_event_payload_size": true,
+	"ws_get_random_int":         true,
+	"ws_time_since_epoch":       true,
+	"ws_regex_match":            true,
+}
+
+// capabilityAllowed checks if host function can be linked by capabilities,
+// functions without `ws_` prefix are runtime builtins and the functions in
+// valueResultFuncs are always allowed
+func capabilityAllowed(name string, capabilities []string) bool {
+	if len(capabilities) == 0 || !strings.HasPrefix(name, "ws_") || valueResultFuncs[name] {
+		return true
+	}
+	for _, c := range capabilities {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+// permissionDeniedStub makes a function with the same signature of fn, which
+// calls denied and returns ResultStatusCode_PermissionDenied for integer
+// results and zero values for the others
+func permissionDeniedStub(fn interface{}, denied func()) interface{} {
+	ft := reflect.TypeOf(fn)
+	return reflect.MakeFunc(ft, func([]reflect.Value) []reflect.Value {
+		denied()
+		results := make([]reflect.Value, ft.NumOut())
+		for i := range results {
+			v := reflect.New(ft.Out(i)).Elem()
+			switch v.Kind() {
+			case reflect.Int32, reflect.Int64:
+				v.SetInt(int64(wasm.ResultStatusCode_PermissionDenied))
+			}
+			results[i] = v
+		}
+		return results
+	}).Interface()
+}
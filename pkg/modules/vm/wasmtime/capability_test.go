@@ -0,0 +1,30 @@
+package wasmtime
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/machinefi/w3bstream/pkg/types/wasm"
+)
+
+func TestCapability(t *testing.T) {
+	NewWithT(t).Expect(capabilityAllowed("ws_send_tx", nil)).To(BeTrue())
+	NewWithT(t).Expect(capabilityAllowed("ws_send_tx", []string{"ws_log"})).To(BeFalse())
+	NewWithT(t).Expect(capabilityAllowed("ws_log", []string{"ws_log"})).To(BeTrue())
+	NewWithT(t).Expect(capabilityAllowed("abort", []string{"ws_log"})).To(BeTrue())
+	NewWithT(t).Expect(capabilityAllowed("ws_get_data_size", []string{"ws_log"})).To(BeTrue())
+	NewWithT(t).Expect(capabilityAllowed("ws_time_since_epoch", []string{"ws_log"})).To(BeTrue())
+
+	called := 0
+	denied := func() { called++ }
+
+	f32 := permissionDeniedStub(func(a, b int32) int32 { return 0 }, denied).(func(int32, int32) int32)
+	NewWithT(t).Expect(f32(1, 2)).To(Equal(int32(wasm.ResultStatusCode_PermissionDenied)))
+
+	f64 := permissionDeniedStub(func(a int64) int64 { return 0 }, denied).(func(int64) int64)
+	NewWithT(t).Expect(f64(1)).To(Equal(int64(wasm.ResultStatusCode_PermissionDenied)))
+
+	permissionDeniedStub(func(int32) {}, denied).(func(int32))(1)
+	NewWithT(t).Expect(called).To(Equal(3))
+}
@@ -0,0 +1,248 @@
+package wasmtime
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/url"
+	"reflect"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+	"github.com/tidwall/gjson"
+)
+
+// compression algorithms supported by ws_compress and ws_decompress
+const (
+	compressAlgoGzip int32 = iota
+	compressAlgoZstd
+)
+
+// base64 variants supported by ws_base64_encode_variant
+const (
+	base64VariantStd int32 = iota
+	base64VariantURL
+)
+
+var ErrDecompressedSizeExceeded = errors.New("decompressed size exceeded")
+
+func compress(algo int32, src []byte) ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+
+	var w io.WriteCloser
+	switch algo {
+	case compressAlgoGzip:
+		w = gzip.NewWriter(buf)
+	case compressAlgoZstd:
+		zw, err := zstd.NewWriter(buf)
+		if err != nil {
+			return nil, err
+		}
+		w = zw
+	default:
+		return nil, errors.Errorf("unsupported compress algorithm: %d", algo)
+	}
+
+	if _, err := w.Write(src); err != nil {
+		_ = w.Close()
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decompress decodes src and fails with ErrDecompressedSizeExceeded when the
+// output is larger than limit bytes
+func decompress(algo int32, src []byte, limit int64) ([]byte, error) {
+	var r io.Reader
+	switch algo {
+	case compressAlgoGzip:
+		gr, err := gzip.NewReader(bytes.NewReader(src))
+		if err != nil {
+			return nil, err
+		}
+		defer gr.Close()
+		r = gr
+	case compressAlgoZstd:
+		zr, err := zstd.NewReader(bytes.NewReader(src))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		r = zr
+	default:
+		return nil, errors.Errorf("unsupported compress algorithm: %d", algo)
+	}
+
+	// read one more byte than limit to detect oversize output
+	dst, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(dst)) > limit {
+		return nil, ErrDecompressedSizeExceeded
+	}
+	return dst, nil
+}
+
+func base64Encoding(variant int32) (*base64.Encoding, error) {
+	switch variant {
+	case base64VariantStd:
+		return base64.StdEncoding, nil
+	case base64VariantURL:
+		return base64.URLEncoding, nil
+	default:
+		return nil, errors.Errorf("unsupported base64 variant: %d", variant)
+	}
+}
+
+func base64Encode(variant int32, src []byte) ([]byte, error) {
+	enc, err := base64Encoding(variant)
+	if err != nil {
+		return nil, err
+	}
+	dst := make([]byte, enc.EncodedLen(len(src)))
+	enc.Encode(dst, src)
+	return dst, nil
+}
+
+func base64Decode(variant int32, src []byte) ([]byte, error) {
+	enc, err := base64Encoding(variant)
+	if err != nil {
+		return nil, err
+	}
+	dst := make([]byte, enc.DecodedLen(len(src)))
+	n, err := enc.Decode(dst, src)
+	if err != nil {
+		return nil, err
+	}
+	return dst[:n], nil
+}
+
+// hexEncode encodes src as lower case hex string, with `0x` prefix if prefixed
+func hexEncode(src []byte, prefixed bool) []byte {
+	offset := 0
+	if prefixed {
+		offset = 2
+	}
+	dst := make([]byte, offset+hex.EncodedLen(len(src)))
+	copy(dst, "0x")
+	hex.Encode(dst[offset:], src)
+	return dst
+}
+
+// hexDecode decodes hex string, the `0x` or `0X` prefix is optional
+func hexDecode(src []byte) ([]byte, error) {
+	if len(src) >= 2 && src[0] == '0' && (src[1] == 'x' || src[1] == 'X') {
+		src = src[2:]
+	}
+	if len(src)%2 != 0 {
+		return nil, errors.Errorf("odd length hex string: %d", len(src))
+	}
+	dst := make([]byte, hex.DecodedLen(len(src)))
+	if _, err := hex.Decode(dst, src); err != nil {
+		return nil, errors.Wrap(err, "invalid hex string")
+	}
+	return dst, nil
+}
+
+// parsedURL components returned by ws_url_parse
+type parsedURL struct {
+	Scheme   string     `json:"scheme"`
+	Host     string     `json:"host"`
+	Path     string     `json:"path"`
+	Query    url.Values `json:"query"`
+	Fragment string     `json:"fragment"`
+}
+
+func urlParse(raw []byte) ([]byte, error) {
+	u, err := url.Parse(string(raw))
+	if err != nil {
+		return nil, err
+	}
+	query, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&parsedURL{
+		Scheme:   u.Scheme,
+		Host:     u.Host,
+		Path:     u.Path,
+		Query:    query,
+		Fragment: u.Fragment,
+	})
+}
+
+// urlEncode encodes json object as query string, array value is encoded as
+// multiple values of the key
+func urlEncode(payload []byte) ([]byte, error) {
+	if !gjson.ValidBytes(payload) {
+		return nil, errors.New("invalid json")
+	}
+	obj := gjson.ParseBytes(payload)
+	if !obj.IsObject() {
+		return nil, errors.New("payload should be a json object")
+	}
+	values := url.Values{}
+	obj.ForEach(func(k, v gjson.Result) bool {
+		if v.IsArray() {
+			for _, elem := range v.Array() {
+				values.Add(k.String(), elem.String())
+			}
+		} else {
+			values.Add(k.String(), v.String())
+		}
+		return true
+	})
+	return []byte(values.Encode()), nil
+}
+
+// timeFormat formats unix nanoseconds in UTC by layout, empty layout means
+// time.RFC3339Nano
+func timeFormat(unixNanos int64, layout string) string {
+	if layout == "" {
+		layout = time.RFC3339Nano
+	}
+	return time.Unix(0, unixNanos).UTC().Format(layout)
+}
+
+// timeParse parses value by layout and returns unix nanoseconds, empty layout
+// means time.RFC3339Nano
+func timeParse(layout, value string) (int64, error) {
+	if layout == "" {
+		layout = time.RFC3339Nano
+	}
+	t, err := time.Parse(layout, value)
+	if err != nil {
+		return 0, err
+	}
+	return t.UnixNano(), nil
+}
+
+// maxCBORSize the max input size of ws_decode_cbor
+const maxCBORSize = 64 * 1024
+
+var cborDecMode, _ = cbor.DecOptions{
+	DefaultMapType:  reflect.TypeOf(map[string]interface{}(nil)),
+	MaxNestedLevels: 32,
+}.DecMode()
+
+// decodeCBOR decodes CBOR data and encodes the value as json, map keys should
+// be text strings
+func decodeCBOR(src []byte) ([]byte, error) {
+	if len(src) > maxCBORSize {
+		return nil, errors.Errorf("cbor data exceeds %d bytes", maxCBORSize)
+	}
+	var v interface{}
+	if err := cborDecMode.Unmarshal(src, &v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
@@ -0,0 +1,127 @@
+package wasmtime
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestCompress(t *testing.T) {
+	src := bytes.Repeat([]byte("w3bstream"), 1024)
+
+	for _, algo := range []int32{compressAlgoGzip, compressAlgoZstd} {
+		compressed, err := compress(algo, src)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(len(compressed) < len(src)).To(BeTrue())
+
+		decompressed, err := decompress(algo, compressed, int64(len(src)))
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(decompressed).To(Equal(src))
+
+		_, err = decompress(algo, compressed, int64(len(src)-1))
+		NewWithT(t).Expect(err).To(Equal(ErrDecompressedSizeExceeded))
+	}
+
+	_, err := compress(2, src)
+	NewWithT(t).Expect(err).NotTo(BeNil())
+}
+
+func TestBase64(t *testing.T) {
+	src := []byte{0xfb, 0xff, 0x77, 0x33}
+
+	encoded, err := base64Encode(base64VariantStd, src)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(string(encoded)).To(Equal("+/93Mw=="))
+
+	encoded, err = base64Encode(base64VariantURL, src)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(string(encoded)).To(Equal("-_93Mw=="))
+
+	decoded, err := base64Decode(base64VariantStd, []byte("+/93Mw=="))
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(decoded).To(Equal(src))
+
+	_, err = base64Decode(base64VariantStd, []byte("-_93Mw=="))
+	NewWithT(t).Expect(err).NotTo(BeNil())
+
+	_, err = base64Encode(2, src)
+	NewWithT(t).Expect(err).NotTo(BeNil())
+}
+
+func TestHex(t *testing.T) {
+	src := []byte{0xde, 0xad, 0xbe, 0xef}
+
+	NewWithT(t).Expect(string(hexEncode(src, false))).To(Equal("deadbeef"))
+	NewWithT(t).Expect(string(hexEncode(src, true))).To(Equal("0xdeadbeef"))
+
+	for _, s := range []string{"deadbeef", "0xdeadbeef", "0XDEADBEEF"} {
+		dst, err := hexDecode([]byte(s))
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(dst).To(Equal(src))
+	}
+
+	_, err := hexDecode([]byte("0xabc"))
+	NewWithT(t).Expect(err).NotTo(BeNil())
+	_, err = hexDecode([]byte("zz"))
+	NewWithT(t).Expect(err).NotTo(BeNil())
+}
+
+func TestURL(t *testing.T) {
+	data, err := urlParse([]byte("https://w3bstream.com:8888/api/v1?a=1&a=2&b=x#top"))
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(string(data)).To(MatchJSON(`{
+		"scheme": "https",
+		"host": "w3bstream.com:8888",
+		"path": "/api/v1",
+		"query": {"a": ["1", "2"], "b": ["x"]},
+		"fragment": "top"
+	}`))
+
+	_, err = urlParse([]byte("http://[::1"))
+	NewWithT(t).Expect(err).NotTo(BeNil())
+
+	data, err = urlEncode([]byte(`{"b": "x y", "a": [1, true], "c": 1.5}`))
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(string(data)).To(Equal("a=1&a=true&b=x+y&c=1.5"))
+
+	_, err = urlEncode([]byte(`["a"]`))
+	NewWithT(t).Expect(err).NotTo(BeNil())
+}
+
+func TestTime(t *testing.T) {
+	nanos := int64(1700000000123456789)
+
+	NewWithT(t).Expect(timeFormat(nanos, "")).To(Equal("2023-11-14T22:13:20.123456789Z"))
+	NewWithT(t).Expect(timeFormat(nanos, "2006-01-02")).To(Equal("2023-11-14"))
+
+	parsed, err := timeParse("", "2023-11-14T22:13:20.123456789Z")
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(parsed).To(Equal(nanos))
+
+	parsed, err = timeParse("2006-01-02 15:04:05 -0700", "2023-11-15 06:13:20 +0800")
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(parsed).To(Equal(int64(1700000000000000000)))
+
+	_, err = timeParse("", "2023-11-14")
+	NewWithT(t).Expect(err).NotTo(BeNil())
+}
+
+func TestDecodeCBOR(t *testing.T) {
+	// {"temp": 21.5, "hum": 40, "ok": true}
+	src := []byte{
+		0xa3,
+		0x64, 't', 'e', 'm', 'p', 0xf9, 0x4d, 0x60,
+		0x63, 'h', 'u', 'm', 0x18, 0x28,
+		0x62, 'o', 'k', 0xf5,
+	}
+	data, err := decodeCBOR(src)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(string(data)).To(MatchJSON(`{"temp": 21.5, "hum": 40, "ok": true}`))
+
+	_, err = decodeCBOR([]byte{0xa1, 0x64, 't'})
+	NewWithT(t).Expect(err).NotTo(BeNil())
+
+	_, err = decodeCBOR(make([]byte, maxCBORSize+1))
+	NewWithT(t).Expect(err).NotTo(BeNil())
+}
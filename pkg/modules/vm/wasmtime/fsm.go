@@ -0,0 +1,59 @@
+package wasmtime
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"github.com/tidwall/gjson"
+)
+
+// fsmKeyPrefix prefix of the kv key storing the state of state machine
+const fsmKeyPrefix = "__fsm__:"
+
+// StateMachineTransitionReq the payload of ws_state_machine_transition
+type StateMachineTransitionReq struct {
+	// MachineID state machine id, the state is stored in kv by key
+	// `__fsm__:{MachineID}`
+	MachineID string `json:"machineID"`
+	// CurrentState the state expected stored, it is the initial state if the
+	// machine state is not stored yet
+	CurrentState string `json:"currentState"`
+	// Event the event triggers transition
+	Event string `json:"event"`
+	// Transitions the transition table, the first matched one is applied
+	Transitions []StateTransition `json:"transitions"`
+	// Data the json which guards are evaluated against
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+type StateTransition struct {
+	From  string `json:"from"`
+	Event string `json:"event"`
+	To    string `json:"to"`
+	// Guard gjson path evaluated against Data, the transition is matched only
+	// if the result is true. empty means no guard
+	Guard string `json:"guard,omitempty"`
+}
+
+var errNoTransition = errors.New("no transition matched")
+
+// selectTransition returns the target state of the first transition from
+// CurrentState on Event which guard passed
+func selectTransition(r *StateMachineTransitionReq) (string, error) {
+	if r.MachineID == "" || r.CurrentState == "" || r.Event == "" {
+		return "", errors.New("machineID, currentState and event are required")
+	}
+	if len(r.Data) > 0 && !gjson.ValidBytes(r.Data) {
+		return "", errors.New("invalid guard data")
+	}
+	for _, t := range r.Transitions {
+		if t.From != r.CurrentState || t.Event != r.Event || t.To == "" {
+			continue
+		}
+		if t.Guard != "" && !gjson.GetBytes(r.Data, t.Guard).Bool() {
+			continue
+		}
+		return t.To, nil
+	}
+	return "", errNoTransition
+}
@@ -0,0 +1,34 @@
+package wasmtime
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestSelectTransition(t *testing.T) {
+	r := &StateMachineTransitionReq{
+		MachineID:    "device_1",
+		CurrentState: "idle",
+		Event:        "start",
+		Transitions: []StateTransition{
+			{From: "idle", Event: "start", To: "charging", Guard: "battery.low"},
+			{From: "idle", Event: "start", To: "running"},
+			{From: "running", Event: "stop", To: "idle"},
+		},
+		Data: []byte(`{"battery":{"low":false}}`),
+	}
+
+	to, err := selectTransition(r)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(to).To(Equal("running"))
+
+	r.Data = []byte(`{"battery":{"low":true}}`)
+	to, err = selectTransition(r)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(to).To(Equal("charging"))
+
+	r.Event = "stop"
+	_, err = selectTransition(r)
+	NewWithT(t).Expect(err).To(Equal(errNoTransition))
+}
@@ -0,0 +1,141 @@
+package wasmtime
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+// grpcCallTimeout the max duration of a unary call made by ws_grpc_call
+const grpcCallTimeout = 10 * time.Second
+
+// GrpcCallReq the payload of ws_grpc_call
+type GrpcCallReq struct {
+	// Endpoint target address as host:port
+	Endpoint string `json:"endpoint"`
+	// Service full qualified service name, eg: helloworld.Greeter
+	Service string `json:"service"`
+	// Method method name of the service
+	Method string `json:"method"`
+	// Headers outgoing metadata of the call
+	Headers map[string]string `json:"headers,omitempty"`
+	// Body proto encoded request message, base64 encoded in json
+	Body []byte `json:"body"`
+	// TLSServerName enables TLS and verifies server certificate with this name
+	TLSServerName string `json:"tlsServerName,omitempty"`
+}
+
+// rawCodec passes proto encoded message bytes through, so the call can be made
+// without message descriptors. it names as proto to keep the content type
+// `application/grpc+proto` which servers accept
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return nil, errors.Errorf("unexpected message type %T", v)
+	}
+	return *b, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return errors.Errorf("unexpected message type %T", v)
+	}
+	*b = append((*b)[:0], data...)
+	return nil
+}
+
+func (rawCodec) Name() string { return "proto" }
+
+// grpcEndpointAllowed checks if host of endpoint is one of domains or their
+// subdomains
+func grpcEndpointAllowed(endpoint string, domains []string) bool {
+	host, _, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		host = endpoint
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, d := range domains {
+		d = strings.ToLower(strings.TrimSuffix(d, "."))
+		if d != "" && (host == d || strings.HasSuffix(host, "."+d)) {
+			return true
+		}
+	}
+	return false
+}
+
+// grpcDialer dials the endpoints resolved to public addresses only
+var grpcDialer = &guardedDialer{Dialer: net.Dialer{Timeout: 5 * time.Second}, allow: publicIP}
+
+// validGrpcEndpoint checks if endpoint is a plain host:port. the targets with
+// scheme such as `unix:` and `dns:` are rejected, the addresses of host are
+// checked by grpcDialer when dialing
+func validGrpcEndpoint(endpoint string) error {
+	if strings.Contains(endpoint, "/") {
+		return errors.Errorf("invalid endpoint %s: host:port expected", endpoint)
+	}
+	host, port, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return errors.Wrapf(err, "invalid endpoint %s", endpoint)
+	}
+	if host == "" || strings.Contains(host, ":") && net.ParseIP(host) == nil {
+		return errors.Errorf("invalid endpoint %s: host:port expected", endpoint)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n <= 0 || n > 65535 {
+		return errors.Errorf("invalid endpoint %s: bad port", endpoint)
+	}
+	return nil
+}
+
+// grpcCall makes an unary call described by r and returns the proto encoded
+// response message
+func grpcCall(ctx context.Context, r *GrpcCallReq) ([]byte, error) {
+	if r.Endpoint == "" || r.Service == "" || r.Method == "" {
+		return nil, errors.New("endpoint, service and method are required")
+	}
+	if err := validGrpcEndpoint(r.Endpoint); err != nil {
+		return nil, err
+	}
+
+	creds := insecure.NewCredentials()
+	if r.TLSServerName != "" {
+		creds = credentials.NewTLS(&tls.Config{ServerName: r.TLSServerName})
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, grpcCallTimeout)
+	defer cancel()
+
+	// passthrough resolver hands endpoint to grpcDialer as is
+	conn, err := grpc.DialContext(ctx, "passthrough:///"+r.Endpoint,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			return grpcDialer.DialContext(ctx, "tcp", addr)
+		}),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "dial")
+	}
+	defer conn.Close()
+
+	if len(r.Headers) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(r.Headers))
+	}
+
+	req, rsp := r.Body, make([]byte, 0)
+	method := "/" + r.Service + "/" + r.Method
+	if err = conn.Invoke(ctx, method, &req, &rsp, grpc.ForceCodec(rawCodec{})); err != nil {
+		return nil, errors.Wrap(err, "invoke "+method)
+	}
+	return rsp, nil
+}
@@ -0,0 +1,83 @@
+package wasmtime
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestGrpcEndpointAllowed(t *testing.T) {
+	domains := []string{"example.com", "grpc.iotex.io."}
+
+	NewWithT(t).Expect(grpcEndpointAllowed("example.com:443", domains)).To(BeTrue())
+	NewWithT(t).Expect(grpcEndpointAllowed("api.Example.com:443", domains)).To(BeTrue())
+	NewWithT(t).Expect(grpcEndpointAllowed("grpc.iotex.io", domains)).To(BeTrue())
+	NewWithT(t).Expect(grpcEndpointAllowed("badexample.com:443", domains)).To(BeFalse())
+	NewWithT(t).Expect(grpcEndpointAllowed("example.com.evil.io:443", domains)).To(BeFalse())
+	NewWithT(t).Expect(grpcEndpointAllowed("example.com:443", nil)).To(BeFalse())
+}
+
+func TestValidGrpcEndpoint(t *testing.T) {
+	for _, endpoint := range []string{"example.com:443", "1.2.3.4:50051", "[2001:db8::1]:443"} {
+		NewWithT(t).Expect(validGrpcEndpoint(endpoint)).To(BeNil())
+	}
+	for _, endpoint := range []string{
+		"unix:/var/run/docker.sock",
+		"unix:///var/run/docker.sock",
+		"dns:///example.com:443",
+		"passthrough:///example.com:443",
+		"example.com",
+		"example.com:0",
+		"example.com:http",
+		":443",
+	} {
+		NewWithT(t).Expect(validGrpcEndpoint(endpoint)).NotTo(BeNil())
+	}
+}
+
+func TestGrpcCall(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	NewWithT(t).Expect(err).To(BeNil())
+
+	srv := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(srv, health.NewServer())
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Stop()
+
+	// loopback is not allowed by default
+	_, err = grpcCall(context.Background(), &GrpcCallReq{
+		Endpoint: lis.Addr().String(),
+		Service:  "grpc.health.v1.Health",
+		Method:   "Check",
+	})
+	NewWithT(t).Expect(err).NotTo(BeNil())
+	NewWithT(t).Expect(err.Error()).To(ContainSubstring(errForbiddenAddr.Error()))
+
+	defer func(d *guardedDialer) { grpcDialer = d }(grpcDialer)
+	grpcDialer = &guardedDialer{allow: func(net.IP) bool { return true }}
+
+	rsp, err := grpcCall(context.Background(), &GrpcCallReq{
+		Endpoint: lis.Addr().String(),
+		Service:  "grpc.health.v1.Health",
+		Method:   "Check",
+		Headers:  map[string]string{"x-project": "demo"},
+	})
+	NewWithT(t).Expect(err).To(BeNil())
+
+	ret := &grpc_health_v1.HealthCheckResponse{}
+	NewWithT(t).Expect(proto.Unmarshal(rsp, ret)).To(BeNil())
+	NewWithT(t).Expect(ret.Status).To(Equal(grpc_health_v1.HealthCheckResponse_SERVING))
+
+	_, err = grpcCall(context.Background(), &GrpcCallReq{
+		Endpoint: lis.Addr().String(),
+		Service:  "grpc.health.v1.Health",
+		Method:   "Unknown",
+	})
+	NewWithT(t).Expect(err).NotTo(BeNil())
+}
@@ -0,0 +1,23 @@
+package wasmtime
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// maxHexDumpSize the max bytes dumped by ws_log_hex_dump
+const maxHexDumpSize = 1024
+
+// hexDump formats data as `hexdump -C` does, 16 bytes per line with ASCII
+// representation. size is the length of the original data, the dump is
+// suffixed with `... (truncated)` if data is shorter than size
+func hexDump(data []byte, size int) string {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "hex dump of %d bytes:\n", size)
+	b.WriteString(hex.Dump(data))
+	if len(data) < size {
+		b.WriteString("... (truncated)")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
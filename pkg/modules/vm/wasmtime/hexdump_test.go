@@ -0,0 +1,20 @@
+package wasmtime
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestHexDump(t *testing.T) {
+	dump := hexDump([]byte("w3bstream\x00\x01\x02device_id"), 21)
+	NewWithT(t).Expect(dump).To(Equal("hex dump of 21 bytes:\n" +
+		"00000000  77 33 62 73 74 72 65 61  6d 00 01 02 64 65 76 69  |w3bstream...devi|\n" +
+		"00000010  63 65 5f 69 64                                    |ce_id|"))
+
+	data := make([]byte, maxHexDumpSize)
+	dump = hexDump(data, 2*maxHexDumpSize)
+	NewWithT(t).Expect(strings.HasSuffix(dump, "... (truncated)")).To(BeTrue())
+	NewWithT(t).Expect(strings.Count(dump, "\n")).To(Equal(maxHexDumpSize/16 + 1))
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -39,6 +40,7 @@ type Instance struct {
 	ctx         context.Context
 	id          types.SFID
 	rt          *Runtime
+	ef          *ExportFuncs
 	state       *atomic.Uint32
 	res         *mapx.Map[uint32, []byte]
 	evs         *mapx.Map[uint32, []byte]
@@ -52,6 +54,17 @@ type Instance struct {
 	windOps     []wasm.Operator
 	windOpMap   map[string]string
 	sink        wasm.Sink
+	// linkedMtx guards idle linkers of invocations
+	linkedMtx sync.Mutex
+	linked    []*linked
+}
+
+// linked a linker whose host functions are bound to ef, it is used by one
+// invocation at a time and ef is replaced by the fork of the invocation, so
+// the linker is created once and reused by the following invocations
+type linked struct {
+	ef     *ExportFuncs
+	linker *wasmtime.Linker
 }
 
 func NewInstanceByCode(ctx context.Context, id types.SFID, code []byte, st enums.InstanceState) (i *Instance, err error) {
@@ -60,15 +73,19 @@ func NewInstanceByCode(ctx context.Context, id types.SFID, code []byte, st enums
 
 	res := mapx.New[uint32, []byte]()
 	evs := mapx.New[uint32, []byte]()
-	rt := NewRuntime()
-	lk, err := NewExportFuncs(contextx.WithContextCompose(
+	maxTicks := uint64(0)
+	if env, ok := wasm.EnvFromContext(ctx); ok && env != nil {
+		maxTicks = env.MaxExecutionTicks
+	}
+	rt := NewRuntime(maxTicks)
+	ef, err := NewExportFuncs(contextx.WithContextCompose(
 		wasm.WithRuntimeResourceContext(res),
 		wasm.WithRuntimeEventTypesContext(evs),
 	)(ctx), rt)
 	if err != nil {
 		return nil, err
 	}
-	if err := rt.Link(lk, code); err != nil {
+	if err := rt.Link(ef, code); err != nil {
 		return nil, err
 	}
 	state := &atomic.Uint32{}
@@ -76,6 +93,7 @@ func NewInstanceByCode(ctx context.Context, id types.SFID, code []byte, st enums
 
 	ins := &Instance{
 		rt:       rt,
+		ef:       ef,
 		id:       id,
 		state:    state,
 		res:      res,
@@ -86,6 +104,8 @@ func NewInstanceByCode(ctx context.Context, id types.SFID, code []byte, st enums
 		ch:       make(chan rxgo.Item),
 	}
 
+	ef.dispatch = ins.HandleEvent
+
 	flow, ok := wasm.FlowFromContext(ctx)
 	if ok {
 		ins.source = flow.Source.Strategies
@@ -120,6 +140,19 @@ func (i *Instance) Stop(ctx context.Context) error {
 	defer l.End()
 
 	i.state.Store(uint32(enums.INSTANCE_STATE__STOPPED))
+	i.ef.watches.CancelAll()
+	i.ef.subs.CancelAll()
+	i.ef.heads.CancelAll()
+	i.ef.cdcs.CancelAll()
+	return nil
+}
+
+// Close releases the connections of database held by instance, the instance
+// must be stopped and never used after closed
+func (i *Instance) Close() error {
+	if i.ef.db != nil {
+		return i.ef.db.Close()
+	}
 	return nil
 }
 
@@ -146,6 +179,13 @@ func (i *Instance) HandleEvent(ctx context.Context, fn, eventType string, data [
 		vm:        i,
 		retrieve:  make(chan *wasm.EventHandleResult),
 	}
+	if pub, ok := types.PublisherFromContext(ctx); ok && pub != nil {
+		task.PublisherKey = pub.Key
+	}
+	if traceID, ok := types.TraceIDFromContext(ctx); ok {
+		task.TraceID = traceID
+	}
+	task.EmitDepth, _ = types.EmitDepthFromContext(ctx)
 
 	job.Dispatch(ctx, task)
 	return task.Wait()
@@ -511,42 +551,139 @@ func (i *Instance) handle(ctx context.Context, task *Task) *wasm.EventHandleResu
 	)
 	defer l.End()
 
+	if task.TraceID != "" {
+		l = l.WithValues("trace_id", task.TraceID)
+	}
+
 	l.Info("start processing task")
-	rid := i.AddResource([]byte(task.EventType), task.Payload)
-	defer i.RmvResource(rid)
+	// resources are scoped to this invocation to avoid leaking between
+	// concurrent handlers
+	res := mapx.New[uint32, []byte]()
+	evs := mapx.New[uint32, []byte]()
+	rid := newResourceID()
+	res.Store(rid, task.Payload)
+	evs.Store(rid, []byte(task.EventType))
 
-	if err := i.rt.Instantiate(ctx); err != nil {
+	rt, ef, lk, err := i.fork(res, evs)
+	if err != nil {
 		return &wasm.EventHandleResult{
 			InstanceID: i.id.String(),
 			ErrMsg:     err.Error(),
 			Code:       wasm.ResultStatusCode_Failed,
+			TraceID:    task.TraceID,
 		}
 	}
-	defer i.rt.Deinstantiate(ctx)
+	defer i.releaseLinked(lk)
+	ef.deviceID = task.PublisherKey
+	ef.traceID = task.TraceID
+	ef.depth = task.EmitDepth
+	if task.TraceID != "" {
+		ef.log = ef.log.WithValues("trace_id", task.TraceID)
+	}
 
-	// TODO support wasm return data(not only code) for HTTP responding
-	result, err := i.rt.Call(ctx, task.Handler, int32(rid))
+	if err := rt.Instantiate(ctx); err != nil {
+		return &wasm.EventHandleResult{
+			InstanceID: i.id.String(),
+			ErrMsg:     err.Error(),
+			Code:       wasm.ResultStatusCode_Failed,
+			TraceID:    task.TraceID,
+		}
+	}
+	defer rt.Deinstantiate(ctx)
+
+	result, err := rt.Call(ctx, task.Handler, int32(rid))
 	l.Debug("call wasm runtime completed.")
+	if err == ErrExecutionTicksExceeded {
+		prj := ""
+		if v, ok := types.ProjectFromContext(ef.ctx); ok {
+			prj = v.Name
+		}
+		l.WithValues("project", prj, "handler", task.Handler).Warn(err)
+		return &wasm.EventHandleResult{
+			InstanceID: i.id.String(),
+			ErrMsg:     err.Error(),
+			Code:       wasm.ResultStatusCode_ExecutionTicksExceeded,
+			TraceID:    task.TraceID,
+		}
+	}
 	if err != nil {
 		l.Error(err)
 		return &wasm.EventHandleResult{
 			InstanceID: i.id.String(),
 			ErrMsg:     err.Error(),
 			Code:       wasm.ResultStatusCode_Failed,
+			TraceID:    task.TraceID,
 		}
 	}
 
+	code := wasm.ResultStatusCode(result.(int32))
+	if code == wasm.ResultStatusCode_OK {
+		ef.latest.Store(task.EventType, task.EventID)
+	}
 	return &wasm.EventHandleResult{
 		InstanceID: i.id.String(),
-		Code:       wasm.ResultStatusCode(result.(int32)),
+		Code:       code,
+		Output:     ef.output,
+		TraceID:    task.TraceID,
+	}
+}
+
+// fork returns a runtime for a single invocation, its host functions use res
+// and evs instead of the instance level resources. the linked returned should
+// be released after invocation
+func (i *Instance) fork(res, evs *mapx.Map[uint32, []byte]) (*Runtime, *ExportFuncs, *linked, error) {
+	lk, err := i.acquireLinked()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	*lk.ef = *i.ef.fork(res, evs)
+	rt, err := i.rt.Fork(lk.linker)
+	if err != nil {
+		i.releaseLinked(lk)
+		return nil, nil, nil, err
 	}
+	lk.ef.rt = rt
+	return rt, lk.ef, lk, nil
+}
+
+// acquireLinked returns an idle linker of instance, a new one is created if
+// all linkers are in use by concurrent invocations
+func (i *Instance) acquireLinked() (*linked, error) {
+	i.linkedMtx.Lock()
+	if n := len(i.linked); n > 0 {
+		lk := i.linked[n-1]
+		i.linked = i.linked[:n-1]
+		i.linkedMtx.Unlock()
+		return lk, nil
+	}
+	i.linkedMtx.Unlock()
+
+	ef := i.ef.fork(nil, nil)
+	linker, err := newLinker(ef)
+	if err != nil {
+		return nil, err
+	}
+	return &linked{ef: ef, linker: linker}, nil
+}
+
+// releaseLinked clears the invocation state of lk and makes it idle
+func (i *Instance) releaseLinked(lk *linked) {
+	*lk.ef = ExportFuncs{}
+
+	i.linkedMtx.Lock()
+	defer i.linkedMtx.Unlock()
+	i.linked = append(i.linked, lk)
+}
+
+func newResourceID() uint32 {
+	return uuid.New().ID() % uint32(maxInt)
 }
 
 func (i *Instance) AddResource(eventType, data []byte) uint32 {
-	var id = int32(uuid.New().ID() % uint32(maxInt))
-	i.res.Store(uint32(id), data)
-	i.evs.Store(uint32(id), eventType)
-	return uint32(id)
+	id := newResourceID()
+	i.res.Store(id, data)
+	i.evs.Store(id, eventType)
+	return id
 }
 
 func (i *Instance) GetResource(id uint32) ([]byte, bool) {
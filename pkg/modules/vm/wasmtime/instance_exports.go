@@ -2,27 +2,36 @@ package wasmtime
 
 import (
 	"context"
-	"encoding/binary"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"math/rand"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	"github.com/tidwall/gjson"
-	"golang.org/x/text/encoding/unicode"
 
+	confipfs "github.com/machinefi/w3bstream/pkg/depends/conf/ipfs"
 	conflog "github.com/machinefi/w3bstream/pkg/depends/conf/log"
 	confmqtt "github.com/machinefi/w3bstream/pkg/depends/conf/mqtt"
+	confnats "github.com/machinefi/w3bstream/pkg/depends/conf/nats"
+	confsecret "github.com/machinefi/w3bstream/pkg/depends/conf/secret"
+	"github.com/machinefi/w3bstream/pkg/depends/kit/sqlx"
 	"github.com/machinefi/w3bstream/pkg/depends/x/mapx"
+	"github.com/machinefi/w3bstream/pkg/enums"
+	"github.com/machinefi/w3bstream/pkg/errors/status"
+	"github.com/machinefi/w3bstream/pkg/models"
 	"github.com/machinefi/w3bstream/pkg/modules/job"
 	"github.com/machinefi/w3bstream/pkg/modules/metrics"
 	optypes "github.com/machinefi/w3bstream/pkg/modules/operator/pool/types"
 	wasmapi "github.com/machinefi/w3bstream/pkg/modules/vm/wasmapi/types"
 	"github.com/machinefi/w3bstream/pkg/types"
 	"github.com/machinefi/w3bstream/pkg/types/wasm"
+	"github.com/machinefi/w3bstream/pkg/types/wasm/kvdb"
 	"github.com/machinefi/w3bstream/pkg/types/wasm/sql_util"
 )
 
@@ -45,9 +54,34 @@ type (
 		cf      *types.ChainConfig
 		ctx     context.Context
 		mq      *confmqtt.Client
+		nats    *confnats.NATSConfig      // nil if nats is not configured
+		ipfs    *confipfs.IPFSConfig      // nil if ipfs is not configured
+		secrets confsecret.SecretProvider // nil if secret provider is not configured
 		metrics metrics.CustomMetrics
 		srv     wasmapi.Server
 		opPool  optypes.Pool
+		rtc     *types.WasmRuntimeConfig
+		evc     *wasm.EventCounter
+		watches *kvWatches     // kv watches of instance, shared by forks
+		subs    *subscriptions // pubsub subscriptions of instance, shared by forks
+		heads   *subscriptions // chain new head subscriptions of instance, shared by forks
+		cdcs    *subscriptions // sql table change subscriptions of instance, shared by forks
+		// latest the id of last event handled successfully of each event type,
+		// shared by forks. it is in memory only and lost when instance stopped
+		latest *mapx.Map[string, string]
+		// dispatch handles event by instance, it is set by instance
+		dispatch func(ctx context.Context, handler, eventType string, payload []byte) *wasm.EventHandleResult
+		// output handler output set by ws_set_output, it is per invocation
+		output []byte
+		// deviceID publisher key of the event being handled, it is per invocation
+		deviceID string
+		// traceID trace id of the event being handled, it is per invocation
+		traceID string
+		// depth nested depth of ws_emit_event of the event being handled, it is
+		// per invocation
+		depth int
+		// StringEncoding layout of strings passed to env.abort and env.trace
+		StringEncoding StringEncoding
 	}
 )
 
@@ -65,13 +99,77 @@ func NewExportFuncs(ctx context.Context, rt *Runtime) (*ExportFuncs, error) {
 		env:     wasm.MustEnvFromContext(ctx),
 		mq:      wasm.MustMQTTClientFromContext(ctx),
 		metrics: wasm.MustCustomMetricsFromContext(ctx),
+		rtc:     types.MustWasmRuntimeConfigFromContext(ctx),
+		evc:     wasm.MustEventCounterFromContext(ctx),
 		rt:      rt,
 		ctx:     ctx,
+		watches: newKVWatches(),
+		subs:    newSubscriptions(maxPubSubSubscriptions),
+		heads:   newSubscriptions(0),
+		cdcs:    newSubscriptions(0),
+		latest:  mapx.New[string, string](),
+	}
+	ef.nats, _ = types.NATSFromContext(ctx)
+	ef.ipfs, _ = types.IPFSFromContext(ctx)
+	if secrets, _ := types.SecretProviderFromContext(ctx); secrets != nil {
+		ef.secrets = secrets
 	}
 
 	return ef, nil
 }
 
+// fork returns a shallow copy of ef for a single invocation, the resource and
+// event type maps are replaced by res and evs, and the clients are shared. the
+// runtime should be assigned by caller
+func (ef *ExportFuncs) fork(res, evs *mapx.Map[uint32, []byte]) *ExportFuncs {
+	return &ExportFuncs{
+		res:     res,
+		evs:     evs,
+		env:     ef.env,
+		kvs:     ef.kvs,
+		db:      ef.db,
+		log:     ef.log,
+		cl:      ef.cl,
+		cf:      ef.cf,
+		ctx:     ef.ctx,
+		mq:      ef.mq,
+		nats:    ef.nats,
+		ipfs:    ef.ipfs,
+		secrets: ef.secrets,
+		metrics: ef.metrics,
+		srv:     ef.srv,
+		opPool:  ef.opPool,
+		rtc:     ef.rtc,
+		evc:     ef.evc,
+		watches: ef.watches,
+		subs:    ef.subs,
+		heads:   ef.heads,
+		cdcs:    ef.cdcs,
+		latest:  ef.latest,
+
+		dispatch: ef.dispatch,
+
+		StringEncoding: ef.StringEncoding,
+	}
+}
+
+// maxEmitEventDepth limits the nested depth of events emitted by wasm handlers
+const maxEmitEventDepth = 5
+
+// eventEmitter handles events emitted by wasm, it is set by event module
+var eventEmitter func(ctx context.Context, eventType string, payload []byte) (interface{}, error)
+
+// SetEventEmitter sets the handler of ws_emit_event
+func SetEventEmitter(f func(ctx context.Context, eventType string, payload []byte) (interface{}, error)) {
+	eventEmitter = f
+}
+
+// waitForTxInterval the interval of polling transaction receipt
+const waitForTxInterval = 2 * time.Second
+
+// maxJSONPathIterationLen limits the length of json path with array iteration(#)
+const maxJSONPathIterationLen = 256
+
 var (
 	_       wasm.ABI = (*ExportFuncs)(nil)
 	_rand            = rand.New(rand.NewSource(time.Now().UnixNano()))
@@ -81,24 +179,96 @@ var (
 
 func (ef *ExportFuncs) LinkABI(impt Import) error {
 	for name, ff := range map[string]interface{}{
-		"abort":                    ef.Abort,
-		"trace":                    ef.Trace,
-		"seed":                     ef.Seed,
-		"ws_log":                   ef.Log,
-		"ws_get_data":              ef.GetData,
-		"ws_set_data":              ef.SetData,
-		"ws_get_db":                ef.GetDB,
-		"ws_set_db":                ef.SetDB,
-		"ws_send_tx":               ef.SendTX,
-		"ws_send_tx_with_operator": ef.SendTXWithOperator,
-		"ws_call_contract":         ef.CallContract,
-		"ws_set_sql_db":            ef.SetSQLDB,
-		"ws_get_sql_db":            ef.GetSQLDB,
-		"ws_get_env":               ef.GetEnv,
-		"ws_send_mqtt_msg":         ef.SendMqttMsg,
-		"ws_api_call":              ef.ApiCall,
+		"abort":                            ef.Abort,
+		"trace":                            ef.Trace,
+		"seed":                             ef.Seed,
+		"ws_get_random_int":                ef.GetRandomInt,
+		"ws_log":                           ef.Log,
+		"ws_log_hex_dump":                  ef.LogHexDump,
+		"ws_get_data":                      ef.GetData,
+		"ws_get_device_id":                 ef.GetDeviceID,
+		"ws_get_latest_event_id":           ef.GetLatestEventID,
+		"ws_get_data_size":                 ef.GetDataSize,
+		"ws_get_event_payload_size":        ef.GetDataSize,
+		"ws_set_output":                    ef.SetOutput,
+		"ws_set_data":                      ef.SetData,
+		"ws_get_db":                        ef.GetDB,
+		"ws_set_db":                        ef.SetDB,
+		"ws_get_db_ttl":                    ef.GetDBTTL,
+		"ws_set_db_pipeline":               ef.SetDBPipeline,
+		"ws_state_machine_transition":      ef.StateMachineTransition,
+		"ws_get_db_batch_delete":           ef.BatchDeleteDB,
+		"ws_get_db_watch":                  ef.WatchDB,
+		"ws_pubsub_subscribe":              ef.PubSubSubscribe,
+		"ws_pubsub_unsubscribe":            ef.PubSubUnsubscribe,
+		"ws_pubsub_publish":                ef.PubSubPublish,
+		"ws_get_db_namespace_stats":        ef.GetDBNamespaceStats,
+		"ws_get_db_scan_prefix":            ef.ScanDBPrefix,
+		"ws_send_tx":                       ef.SendTX,
+		"ws_send_tx_relay":                 ef.SendTXRelay,
+		"ws_send_tx_with_operator":         ef.SendTXWithOperator,
+		"ws_list_operators":                ef.ListOperators,
+		"ws_send_tx_estimate_gas":          ef.SendTXEstimateGas,
+		"ws_wait_for_tx":                   ef.WaitForTx,
+		"ws_call_contract":                 ef.CallContract,
+		"ws_call_contract_multicall":       ef.CallContractMulticall,
+		"ws_get_contract_events_since":     ef.GetContractEventsSince,
+		"ws_get_block_by_number":           ef.GetBlockByNumber,
+		"ws_subscribe_blocks":              ef.SubscribeBlocks,
+		"ws_get_chain_gas_price":           ef.GetChainGasPrice,
+		"ws_set_sql_db":                    ef.SetSQLDB,
+		"ws_get_sql_db":                    ef.GetSQLDB,
+		"ws_get_sql_db_count":              ef.GetSQLDBCount,
+		"ws_get_sql_db_paginate":           ef.GetSQLDBPaginate,
+		"ws_get_sql_db_tx":                 ef.GetSQLDBTx,
+		"ws_get_sql_db_batch":              ef.GetSQLDBBatch,
+		"ws_get_sql_db_schema_version":     ef.GetSQLDBSchemaVersion,
+		"ws_get_sql_db_explain":            ef.GetSQLDBExplain,
+		"ws_get_sql_db_changes":            ef.GetSQLDBChanges,
+		"ws_get_sql_db_indexes":            ef.GetSQLDBIndexes,
+		"ws_soft_delete":                   ef.SoftDelete,
+		"ws_get_storage_proof":             ef.GetStorageProof,
+		"ws_get_token_metadata":            ef.GetTokenMetadata,
+		"ws_get_operator_balance":          ef.GetOperatorBalance,
+		"ws_get_operator_pending_tx_count": ef.GetOperatorPendingTxCount,
+		"ws_sign_eth_message":              ef.SignEthMessage,
+		"ws_get_sql_db_upsert":             ef.GetSQLDBUpsert,
+		"ws_db_migrate":                    ef.DBMigrate,
+		"ws_get_env":                       ef.GetEnv,
+		"ws_get_env_secret":                ef.GetEnvSecret,
+		"ws_jsonpath_query":                ef.JSONPathQuery,
+		"ws_get_event_count":               ef.GetEventCount,
+		"ws_emit_event":                    ef.EmitEvent,
+		"ws_send_mqtt_msg":                 ef.SendMqttMsg,
+		"ws_nats_publish":                  ef.PublishNATS,
+		"ws_ipfs_add":                      ef.AddIPFS,
+		"ws_ipfs_cat":                      ef.CatIPFS,
+		"ws_grpc_call":                     ef.GrpcCall,
+		"ws_send_slack_message":            ef.SendSlackMessage,
+		"ws_api_call":                      ef.ApiCall,
+		"ws_compress":                      ef.Compress,
+		"ws_decompress":                    ef.Decompress,
+		"ws_base64_encode":                 ef.Base64Encode,
+		"ws_base64_encode_variant":         ef.Base64EncodeVariant,
+		"ws_base64_decode":                 ef.Base64Decode,
+		"ws_encode_hex":                    ef.EncodeHex,
+		"ws_encode_protobuf":               ef.EncodeProtobuf,
+		"ws_format_string":                 ef.FormatString,
+		"ws_encode_hex_variant":            ef.EncodeHexVariant,
+		"ws_decode_hex":                    ef.DecodeHex,
+		"ws_decode_cbor":                   ef.DecodeCBOR,
+		"ws_decode_msgpack":                ef.DecodeMsgpack,
+		"ws_encode_msgpack":                ef.EncodeMsgpack,
+		"ws_url_parse":                     ef.URLParse,
+		"ws_url_encode":                    ef.URLEncode,
+		"ws_time_format":                   ef.TimeFormat,
+		"ws_time_parse":                    ef.TimeParse,
+		"ws_time_since_epoch":              ef.TimeSinceEpoch,
+		"ws_regex_match":                   ef.RegexMatch,
+		"ws_verify_merkle_proof":           ef.VerifyMerkleProof,
+		"ws_check_schema":                  ef.CheckSchema,
 	} {
-		if err := impt("env", name, ff); err != nil {
+		if err := impt("env", name, ef.withCapability(name, ff)); err != nil {
 			return err
 		}
 	}
@@ -106,7 +276,7 @@ func (ef *ExportFuncs) LinkABI(impt Import) error {
 	for name, ff := range map[string]interface{}{
 		"ws_submit_metrics": ef.StatSubmit,
 	} {
-		if err := impt("stat", name, ff); err != nil {
+		if err := impt("stat", name, ef.withCapability(name, ff)); err != nil {
 			return err
 		}
 	}
@@ -114,7 +284,28 @@ func (ef *ExportFuncs) LinkABI(impt Import) error {
 	return nil
 }
 
+// withCapability returns host function ff if name is allowed by capabilities
+// of project env, otherwise a stub returns ResultStatusCode_PermissionDenied
+// instead. the stub is linked rather than skipped to give wasm a useful error
+// instead of a `missing import` trap
+func (ef *ExportFuncs) withCapability(name string, ff interface{}) interface{} {
+	if ef.env == nil || capabilityAllowed(name, ef.env.Capabilities) {
+		return ff
+	}
+	return permissionDeniedStub(ff, func() {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, fmt.Sprintf("permission denied: %s is not in capabilities", name))
+	})
+}
+
+// logEnabled checks if logs of logLevel should be output by project's log level
+func (ef *ExportFuncs) logEnabled(logLevel conflog.Level) bool {
+	return ef.env == nil || logLevel <= ef.env.LogLevel()
+}
+
 func (ef *ExportFuncs) logAndPersistToDB(logLevel conflog.Level, logSrc, msg string) {
+	if !ef.logEnabled(logLevel) {
+		return
+	}
 	ef.log.Debug(fmt.Sprintf("start invoke logAndPersistToDB with %s and %s", logLevel.String(), msg))
 	if len(logSrc) == 0 {
 		logSrc = efSrc
@@ -139,6 +330,9 @@ func (ef *ExportFuncs) logAndPersistToDB(logLevel conflog.Level, logSrc, msg str
 
 func (ef *ExportFuncs) Log(logLevel, ptr, size int32) int32 {
 	ef.log.Debug("start invoke log")
+	if !ef.logEnabled(conflog.Level(logLevel)) {
+		return int32(wasm.ResultStatusCode_OK)
+	}
 	buf, err := ef.rt.Read(ptr, size)
 	if err != nil {
 		ef.logAndPersistToDB(conflog.ErrorLevel, codeSrc, err.Error())
@@ -148,6 +342,32 @@ func (ef *ExportFuncs) Log(logLevel, ptr, size int32) int32 {
 	return int32(wasm.ResultStatusCode_OK)
 }
 
+// LogHexDump logs the hex dump of data at debug level, at most maxHexDumpSize
+// bytes are dumped. it is a no-op unless debug mode of project env is enabled,
+// and the dump is logged regardless of the minimum log level of project
+func (ef *ExportFuncs) LogHexDump(dataAddr, dataSize int32) int32 {
+	if ef.env == nil || !ef.env.DebugMode {
+		return int32(wasm.ResultStatusCode_OK)
+	}
+	if dataSize < 0 {
+		return int32(wasm.ResultStatusCode_ParamIllegal)
+	}
+	size := dataSize
+	if size > maxHexDumpSize {
+		size = maxHexDumpSize
+	}
+	data, err := ef.rt.Read(dataAddr, size)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, codeSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+
+	msg := hexDump(data, int(dataSize))
+	ef.log.WithValues("@src", codeSrc).Debug(msg)
+	job.Dispatch(ef.ctx, job.NewWasmLogTask(ef.ctx, conflog.DebugLevel.String(), codeSrc, msg))
+	return int32(wasm.ResultStatusCode_OK)
+}
+
 func (ef *ExportFuncs) ApiCall(kAddr, kSize, vmAddrPtr, vmSizePtr int32) int32 {
 	buf, err := ef.rt.Read(kAddr, kSize)
 	if err != nil {
@@ -155,7 +375,11 @@ func (ef *ExportFuncs) ApiCall(kAddr, kSize, vmAddrPtr, vmSizePtr int32) int32 {
 		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
 	}
 
-	resp := ef.srv.Call(ef.ctx, buf)
+	ctx := ef.ctx
+	if ef.traceID != "" {
+		ctx = types.WithTraceID(ctx, ef.traceID)
+	}
+	resp := ef.srv.Call(ctx, buf)
 
 	respJson, err := json.Marshal(resp)
 	if err != nil {
@@ -187,26 +411,7 @@ func (ef *ExportFuncs) Abort(msgPtr int32, fileNamePtr int32, line int32, col in
 }
 
 func (ef *ExportFuncs) readString(ptr int32) (string, error) {
-	if ptr < 4 {
-		return "", errors.Errorf("the pointer address %d is invalid", ptr)
-	}
-
-	decoder := unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM).NewDecoder()
-
-	lenData, err := ef.rt.Read(ptr-4, 4) // sizeof(uint32) is 4
-	if err != nil {
-		return "", err
-	}
-	len := binary.LittleEndian.Uint32(lenData)
-	data, err := ef.rt.Read(ptr, int32(len))
-	if err != nil {
-		return "", err
-	}
-	utf8bytes, err := decoder.Bytes(data)
-	if err != nil {
-		return "", err
-	}
-	return string(utf8bytes), nil
+	return readString(ef.rt.Read, ptr, ef.StringEncoding)
 }
 
 // Trace is reserved for imported func env.trace() which is auto-generated by assemblyScript
@@ -229,6 +434,17 @@ func (ef *ExportFuncs) Seed() float64 {
 	return _rand.Float64() * float64(time.Now().UnixNano())
 }
 
+// GetRandomInt returns a uniform random integer in [minVal, maxVal] generated by
+// algo, ResultStatusCode_Failed is returned if minVal > maxVal
+func (ef *ExportFuncs) GetRandomInt(algo, minVal, maxVal int32) int32 {
+	v, err := randomInt(algo, minVal, maxVal)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	return v
+}
+
 func (ef *ExportFuncs) GetData(rid, vmAddrPtr, vmSizePtr int32) int32 {
 	data, ok := ef.res.Load(uint32(rid))
 	if !ok {
@@ -243,6 +459,65 @@ func (ef *ExportFuncs) GetData(rid, vmAddrPtr, vmSizePtr int32) int32 {
 	return int32(wasm.ResultStatusCode_OK)
 }
 
+// GetDeviceID writes the publisher key of the event being handled, the event
+// without publisher is treated as resource not found
+func (ef *ExportFuncs) GetDeviceID(vmAddrPtr, vmSizePtr int32) int32 {
+	if ef.deviceID == "" {
+		return int32(wasm.ResultStatusCode_ResourceNotFound)
+	}
+	if err := ef.rt.Copy([]byte(ef.deviceID), vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// GetLatestEventID writes the id of the last event of eventType handled by the
+// instance successfully, it returns ResourceNotFound if no such event handled
+// since the instance started. it is best-effort: the ids are kept in memory of
+// the instance only, so they are lost when the instance is restarted or
+// redeployed and aren't shared with the instances of other processes. the
+// handlers need a durable checkpoint should store it by ws_set_db
+func (ef *ExportFuncs) GetLatestEventID(eventTypeAddr, eventTypeSize int32, vmAddrPtr, vmSizePtr int32) int32 {
+	eventType, err := ef.rt.Read(eventTypeAddr, eventTypeSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+	id, ok := ef.latest.Load(string(eventType))
+	if !ok {
+		return int32(wasm.ResultStatusCode_ResourceNotFound)
+	}
+	if err = ef.rt.Copy([]byte(id), vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// GetDataSize returns the byte length of resource, -1 if rid not found. it is
+// also exported as ws_get_event_payload_size, the event payload is stored as
+// the resource of rid, so handler can check the size before reading it
+func (ef *ExportFuncs) GetDataSize(rid int32) int32 {
+	data, ok := ef.res.Load(uint32(rid))
+	if !ok {
+		return -1
+	}
+	return int32(len(data))
+}
+
+// SetOutput sets the output of current handler, it is returned in the result
+// of event handling
+func (ef *ExportFuncs) SetOutput(payloadAddr, payloadSize int32) int32 {
+	buf, err := ef.rt.Read(payloadAddr, payloadSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+	ef.output = buf
+	return int32(wasm.ResultStatusCode_OK)
+}
+
 // TODO SetData if rid not exist, should be assigned by wasm?
 func (ef *ExportFuncs) SetData(rid, addr, size int32) int32 {
 	buf, err := ef.rt.Read(addr, size)
@@ -290,7 +565,22 @@ func (ef *ExportFuncs) SetDB(kAddr, kSize, vAddr, vSize int32) int32 {
 
 	ef.logAndPersistToDB(conflog.InfoLevel, efSrc, fmt.Sprintf("host.SetDB %s:%s", string(key), strconv.Quote(string(val))))
 
-	err = ef.kvs.Set(string(key), val)
+	if ef.env != nil && ef.env.MaxKVValueBytes > 0 && len(val) > ef.env.MaxKVValueBytes {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, fmt.Sprintf("value size %d exceeds limit %d", len(val), ef.env.MaxKVValueBytes))
+		return int32(wasm.ResultStatusCode_StorageQuotaExceeded)
+	}
+
+	if kvs, ok := ef.kvs.(interface {
+		SetWithQuota(key string, value []byte, limit int64) error
+	}); ok && ef.env != nil && ef.env.MaxKVTotalBytes > 0 {
+		err = kvs.SetWithQuota(string(key), val, ef.env.MaxKVTotalBytes)
+	} else {
+		err = ef.kvs.Set(string(key), val)
+	}
+	if errors.Is(err, kvdb.ErrQuotaExceeded) {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_StorageQuotaExceeded)
+	}
 	if err != nil {
 		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
 		return int32(wasm.ResultStatusCode_Failed)
@@ -298,147 +588,306 @@ func (ef *ExportFuncs) SetDB(kAddr, kSize, vAddr, vSize int32) int32 {
 	return int32(wasm.ResultStatusCode_OK)
 }
 
-func (ef *ExportFuncs) SetSQLDB(addr, size int32) int32 {
-	if ef.db == nil {
-		return int32(wasm.ResultStatusCode_NoDBContext)
-	}
-	data, err := ef.rt.Read(addr, size)
+// SetDBPipeline sets the kv entries of json array in payload
+// `[{"key":"k","value":"v","ttl":1000}]` in one round trip, ttl is in
+// milliseconds and zero means no expiration. it writes the counts of entries
+// `{"applied":n,"failed":m}`
+func (ef *ExportFuncs) SetDBPipeline(payloadAddr, payloadSize int32, vmAddrPtr, vmSizePtr int32) int32 {
+	payload, err := ef.rt.Read(payloadAddr, payloadSize)
 	if err != nil {
 		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
-		return int32(wasm.ResultStatusCode_ResourceNotFound)
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
 	}
 
-	prestate, params, err := sql_util.ParseQuery(data)
-	if err != nil {
+	var entries []struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+		TTL   int64  `json:"ttl"`
+	}
+	if err = json.Unmarshal(payload, &entries); err != nil {
 		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
-		return wasm.ResultStatusCode_Failed
+		return int32(wasm.ResultStatusCode_ParamIllegal)
+	}
+	cmds := make([]wasm.KVCommand, 0, len(entries))
+	for _, e := range entries {
+		if ef.env != nil && ef.env.MaxKVValueBytes > 0 && len(e.Value) > ef.env.MaxKVValueBytes {
+			ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, fmt.Sprintf("value size %d of key %s exceeds limit %d", len(e.Value), e.Key, ef.env.MaxKVValueBytes))
+			return int32(wasm.ResultStatusCode_StorageQuotaExceeded)
+		}
+		cmds = append(cmds, wasm.KVCommand{
+			Key:   e.Key,
+			Value: []byte(e.Value),
+			TTL:   time.Duration(e.TTL) * time.Millisecond,
+		})
 	}
 
-	db, err := ef.db.WithDefaultSchema()
+	if kvs, ok := ef.kvs.(interface {
+		PipelineWithQuota(cmds []wasm.KVCommand, limit int64) error
+	}); ok && ef.env != nil && ef.env.MaxKVTotalBytes > 0 {
+		err = kvs.PipelineWithQuota(cmds, ef.env.MaxKVTotalBytes)
+	} else {
+		err = ef.kvs.Pipeline(cmds)
+	}
+	failed := 0
 	if err != nil {
-		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
-		return wasm.ResultStatusCode_Failed
+		perr := &kvdb.PipelineError{}
+		if !errors.As(err, &perr) {
+			ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+			return wasm.ResultStatusCode_Failed
+		}
+		failed = len(perr.Errors)
+		ef.logAndPersistToDB(conflog.WarnLevel, efSrc, err.Error())
 	}
-	_, err = db.ExecContext(context.Background(), prestate, params...)
+
+	data, err := json.Marshal(&struct {
+		Applied int `json:"applied"`
+		Failed  int `json:"failed"`
+	}{len(cmds) - failed, failed})
 	if err != nil {
 		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
-		return wasm.ResultStatusCode_Failed
+		return int32(wasm.ResultStatusCode_HostInternal)
+	}
+	if err = ef.rt.Copy(data, vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
 	}
-
 	return int32(wasm.ResultStatusCode_OK)
 }
 
-func (ef *ExportFuncs) GetSQLDB(addr, size int32, vmAddrPtr, vmSizePtr int32) int32 {
-	if ef.db == nil {
-		return int32(wasm.ResultStatusCode_NoDBContext)
+// StateMachineTransition applies the transition of state machine matched by
+// the stored state and event, and writes the new state. the state is swapped
+// atomically, it returns CASFailed if the stored state is not currentState
+func (ef *ExportFuncs) StateMachineTransition(payloadAddr, payloadSize int32, vmAddrPtr, vmSizePtr int32) int32 {
+	kvs, ok := ef.kvs.(interface {
+		CompareAndSwap(key string, old, value []byte) (bool, error)
+	})
+	if !ok {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, "kv store does not support compare and swap")
+		return wasm.ResultStatusCode_Failed
 	}
-	data, err := ef.rt.Read(addr, size)
+	payload, err := ef.rt.Read(payloadAddr, payloadSize)
 	if err != nil {
 		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
-		return int32(wasm.ResultStatusCode_ResourceNotFound)
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+	req := &StateMachineTransitionReq{}
+	if err = json.Unmarshal(payload, req); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_ParamIllegal)
+	}
+	to, err := selectTransition(req)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, fmt.Sprintf("machine %s: %v", req.MachineID, err))
+		return int32(wasm.ResultStatusCode_ParamIllegal)
 	}
 
-	prestate, params, err := sql_util.ParseQuery(data)
+	key := fsmKeyPrefix + req.MachineID
+	swapped, err := kvs.CompareAndSwap(key, []byte(req.CurrentState), []byte(to))
+	if err == nil && !swapped {
+		// the machine state is not stored, currentState is the initial state
+		swapped, err = kvs.CompareAndSwap(key, nil, []byte(to))
+	}
 	if err != nil {
 		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
 		return wasm.ResultStatusCode_Failed
 	}
+	if !swapped {
+		ef.logAndPersistToDB(conflog.WarnLevel, efSrc, fmt.Sprintf("machine %s: state is not %s", req.MachineID, req.CurrentState))
+		return int32(wasm.ResultStatusCode_CASFailed)
+	}
 
-	db, err := ef.db.WithDefaultSchema()
-	if err != nil {
+	if err = ef.rt.Copy([]byte(to), vmAddrPtr, vmSizePtr); err != nil {
 		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
-		return wasm.ResultStatusCode_Failed
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
 	}
-	rows, err := db.QueryContext(context.Background(), prestate, params...)
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// GetDBTTL writes the remaining ttl of key in milliseconds, `-1` means the key
+// has no expiration and `-2` means the key is not exists
+func (ef *ExportFuncs) GetDBTTL(kAddr, kSize int32, vmAddrPtr, vmSizePtr int32) int32 {
+	key, err := ef.rt.Read(kAddr, kSize)
 	if err != nil {
 		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
-		return wasm.ResultStatusCode_Failed
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
 	}
 
-	ret, err := sql_util.JsonifyRows(rows)
-	if err != nil {
+	var ms int64
+	ttl, err := ef.kvs.TTL(string(key))
+	switch {
+	case errors.Is(err, kvdb.ErrKeyNotFound):
+		ms = -2
+	case err != nil:
 		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
 		return wasm.ResultStatusCode_Failed
+	case ttl == kvdb.NoExpiration:
+		ms = -1
+	default:
+		ms = ttl.Milliseconds()
 	}
 
-	if err := ef.rt.Copy(ret, vmAddrPtr, vmSizePtr); err != nil {
+	if err = ef.rt.Copy([]byte(strconv.FormatInt(ms, 10)), vmAddrPtr, vmSizePtr); err != nil {
 		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
 		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
 	}
-
 	return int32(wasm.ResultStatusCode_OK)
 }
 
-// TODO: make sendTX async, and add callback if possible
-func (ef *ExportFuncs) SendTX(chainID int32, offset, size, vmAddrPtr, vmSizePtr int32) int32 {
-	if ef.cl == nil {
-		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, errors.New("eth client doesn't exist").Error())
-		return wasm.ResultStatusCode_Failed
-	}
-	buf, err := ef.rt.Read(offset, size)
+// BatchDeleteDB deletes the keys of json array in payload atomically, and
+// writes the count of keys deleted
+func (ef *ExportFuncs) BatchDeleteDB(payloadAddr, payloadSize int32, vmAddrPtr, vmSizePtr int32) int32 {
+	payload, err := ef.rt.Read(payloadAddr, payloadSize)
 	if err != nil {
 		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
-		return wasm.ResultStatusCode_Failed
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
 	}
-	ret := gjson.Parse(string(buf))
-	txHash, err := ef.cl.SendTX(ef.cf, uint64(chainID), "", ret.Get("to").String(), ret.Get("value").String(), ret.Get("data").String(), ef.opPool, types.MustProjectFromContext(ef.ctx))
+
+	var keys []string
+	if err = json.Unmarshal(payload, &keys); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_ParamIllegal)
+	}
+
+	deleted, err := ef.kvs.BatchDelete(keys)
 	if err != nil {
 		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
 		return wasm.ResultStatusCode_Failed
 	}
-	if err := ef.rt.Copy([]byte(txHash), vmAddrPtr, vmSizePtr); err != nil {
+
+	if err = ef.rt.Copy([]byte(strconv.Itoa(deleted)), vmAddrPtr, vmSizePtr); err != nil {
 		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
-		return wasm.ResultStatusCode_Failed
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
 	}
 	return int32(wasm.ResultStatusCode_OK)
 }
 
-func (ef *ExportFuncs) SendTXWithOperator(chainID int32, offset, size, vmAddrPtr, vmSizePtr int32) int32 {
-	if ef.cl == nil {
-		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, errors.New("eth client doesn't exist").Error())
-		return wasm.ResultStatusCode_Failed
+// ScanDBPrefix writes the keys with prefix from cursor as json
+// `{"keys":[...],"nextCursor":"..."}`, zero cursor address or size means
+// scanning from the beginning and empty nextCursor means scanning is done
+func (ef *ExportFuncs) ScanDBPrefix(prefixAddr, prefixSize, cursorAddr, cursorSize, count int32, vmAddrPtr, vmSizePtr int32) int32 {
+	prefix, err := ef.rt.Read(prefixAddr, prefixSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
 	}
-	buf, err := ef.rt.Read(offset, size)
+	var cursor []byte
+	if cursorAddr != 0 && cursorSize != 0 {
+		if cursor, err = ef.rt.Read(cursorAddr, cursorSize); err != nil {
+			ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+			return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+		}
+	}
+
+	keys, next, err := ef.kvs.ScanPrefix(string(prefix), string(cursor), int(count))
 	if err != nil {
 		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
 		return wasm.ResultStatusCode_Failed
 	}
-	ret := gjson.Parse(string(buf))
-	txHash, err := ef.cl.SendTXWithOperator(ef.cf, uint64(chainID), "", ret.Get("to").String(), ret.Get("value").String(), ret.Get("data").String(), ret.Get("operatorName").String(), ef.opPool, types.MustProjectFromContext(ef.ctx))
+	if keys == nil {
+		keys = []string{}
+	}
+
+	data, err := json.Marshal(&struct {
+		Keys       []string `json:"keys"`
+		NextCursor string   `json:"nextCursor"`
+	}{keys, next})
 	if err != nil {
 		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
-		return wasm.ResultStatusCode_Failed
+		return int32(wasm.ResultStatusCode_HostInternal)
 	}
-	if err := ef.rt.Copy([]byte(txHash), vmAddrPtr, vmSizePtr); err != nil {
+	if err = ef.rt.Copy(data, vmAddrPtr, vmSizePtr); err != nil {
 		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
-		return wasm.ResultStatusCode_Failed
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
 	}
 	return int32(wasm.ResultStatusCode_OK)
 }
 
-func (ef *ExportFuncs) SendMqttMsg(topicAddr, topicSize, msgAddr, msgSize int32) int32 {
-	if ef.mq == nil {
-		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, errors.New("mq client doesn't exist").Error())
+// WatchDB watches the kv key, handler is invoked with __kv_changed__ event when
+// the value of key changed. it requires redis keyspace notification enabled
+func (ef *ExportFuncs) WatchDB(kAddr, kSize, handlerAddr, handlerSize int32) int32 {
+	key, err := ef.rt.Read(kAddr, kSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+	handler, err := ef.rt.Read(handlerAddr, handlerSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+
+	kvs, ok := ef.kvs.(interface {
+		Watch(ctx context.Context, key string, fn func(value []byte), onEnd func(err error)) error
+	})
+	if !ok || ef.dispatch == nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, "kv watching is not supported")
 		return wasm.ResultStatusCode_Failed
 	}
 
-	var (
-		topicBuf []byte
-		msgBuf   []byte
-		err      error
-	)
+	// the callback outlives the invocation, whose ExportFuncs is reused by
+	// the following invocations
+	sub := ef.fork(nil, nil)
 
-	topicBuf, err = ef.rt.Read(topicAddr, topicSize)
+	k, h := string(key), string(handler)
+	err = ef.watches.Add(k, h, func(ctx context.Context) error {
+		return kvs.Watch(ctx, k, func(value []byte) {
+			payload, _ := json.Marshal(newKVChangedEvent(k, value))
+			ctx := types.WithEventID(sub.ctx, uuid.NewString()+"_kv_changed")
+			if rsp := sub.dispatch(ctx, h, eventTypeKVChanged, payload); rsp.ErrMsg != "" {
+				sub.logAndPersistToDB(conflog.ErrorLevel, efSrc, rsp.ErrMsg)
+			}
+		}, func(err error) {
+			// the watch is ended, removes it to allow watching again
+			sub.watches.Remove(k, h)
+			sub.logAndPersistToDB(conflog.ErrorLevel, efSrc, errors.Wrapf(err, "watch kv key %s", k).Error())
+		})
+	})
 	if err != nil {
 		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		if errors.Is(err, errTooManyKVWatches) {
+			return int32(wasm.ResultStatusCode_RateLimited)
+		}
 		return wasm.ResultStatusCode_Failed
 	}
-	msgBuf, err = ef.rt.Read(msgAddr, msgSize)
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// PubSubSubscribe subscribes the channel of project, handler is invoked with
+// __pubsub_message__ event when message received
+func (ef *ExportFuncs) PubSubSubscribe(chanAddr, chanSize, handlerAddr, handlerSize int32) int32 {
+	channel, err := ef.rt.Read(chanAddr, chanSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+	handler, err := ef.rt.Read(handlerAddr, handlerSize)
 	if err != nil {
 		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+
+	kvs, ok := ef.kvs.(interface {
+		Subscribe(ctx context.Context, channel string, fn func(msg []byte)) error
+	})
+	if !ok || ef.dispatch == nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, "pubsub is not supported")
 		return wasm.ResultStatusCode_Failed
 	}
-	err = ef.mq.WithTopic(string(topicBuf)).Publish(string(msgBuf))
+
+	// the callback outlives the invocation, whose ExportFuncs is reused by
+	// the following invocations
+	sub := ef.fork(nil, nil)
+
+	ch, h := string(channel), string(handler)
+	err = ef.subs.Add(ch, func(ctx context.Context) error {
+		return kvs.Subscribe(ctx, ch, func(msg []byte) {
+			payload, _ := json.Marshal(&PubSubMessageEvent{Channel: ch, Message: string(msg)})
+			ctx := types.WithEventID(sub.ctx, uuid.NewString()+"_pubsub_message")
+			if rsp := sub.dispatch(ctx, h, eventTypePubSubMessage, payload); rsp.ErrMsg != "" {
+				sub.logAndPersistToDB(conflog.ErrorLevel, efSrc, rsp.ErrMsg)
+			}
+		})
+	})
 	if err != nil {
 		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
 		return wasm.ResultStatusCode_Failed
@@ -446,48 +895,2163 @@ func (ef *ExportFuncs) SendMqttMsg(topicAddr, topicSize, msgAddr, msgSize int32)
 	return int32(wasm.ResultStatusCode_OK)
 }
 
-func (ef *ExportFuncs) CallContract(chainID int32, offset, size int32, vmAddrPtr, vmSizePtr int32) int32 {
-	if ef.cl == nil {
-		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, errors.New("eth client doesn't exist").Error())
-		return wasm.ResultStatusCode_Failed
+// PubSubUnsubscribe cancels the subscription of channel
+func (ef *ExportFuncs) PubSubUnsubscribe(chanAddr, chanSize int32) int32 {
+	channel, err := ef.rt.Read(chanAddr, chanSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
 	}
-	buf, err := ef.rt.Read(offset, size)
+	if !ef.subs.Remove(string(channel)) {
+		return int32(wasm.ResultStatusCode_ResourceNotFound)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// PubSubPublish publishes message to the channel of project, the message is
+// dropped if the channel has no subscriber
+func (ef *ExportFuncs) PubSubPublish(chanAddr, chanSize, msgAddr, msgSize int32) int32 {
+	channel, err := ef.rt.Read(chanAddr, chanSize)
 	if err != nil {
 		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
-		return wasm.ResultStatusCode_Failed
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
 	}
-	ret := gjson.Parse(string(buf))
-	data, err := ef.cl.CallContract(ef.cf, uint64(chainID), "", ret.Get("to").String(), ret.Get("data").String())
+	msg, err := ef.rt.Read(msgAddr, msgSize)
 	if err != nil {
 		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+
+	kvs, ok := ef.kvs.(interface {
+		Publish(channel string, msg []byte) (int, error)
+	})
+	if !ok {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, "pubsub is not supported")
 		return wasm.ResultStatusCode_Failed
 	}
-	if err = ef.rt.Copy(data, vmAddrPtr, vmSizePtr); err != nil {
+	if _, err = kvs.Publish(string(channel), msg); err != nil {
 		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
 		return wasm.ResultStatusCode_Failed
 	}
 	return int32(wasm.ResultStatusCode_OK)
 }
 
-func (ef *ExportFuncs) GetEnv(kAddr, kSize int32, vmAddrPtr, vmSizePtr int32) int32 {
-	if ef.env == nil {
-		return int32(wasm.ResultStatusCode_EnvKeyNotFound)
+// GetDBNamespaceStats writes the key count and storage usage of kv namespace,
+// it is only allowed for the projects owned by admin
+func (ef *ExportFuncs) GetDBNamespaceStats(vmAddrPtr, vmSizePtr int32) int32 {
+	if err := ef.checkAdminProject(); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
 	}
-	key, err := ef.rt.Read(kAddr, kSize)
+	kvs, ok := ef.kvs.(interface {
+		Stats() (*kvdb.NamespaceStats, error)
+	})
+	if !ok {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, "kv store stats unsupported")
+		return wasm.ResultStatusCode_Failed
+	}
+	stats, err := kvs.Stats()
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_HostInternal)
+	}
+	data, err := json.Marshal(stats)
 	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_HostInternal)
+	}
+	if err = ef.rt.Copy(data, vmAddrPtr, vmSizePtr); err != nil {
 		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
 		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
 	}
+	return int32(wasm.ResultStatusCode_OK)
+}
 
-	val, ok := ef.env.Get(string(key))
+// checkAdminProject checks if the project of instance is owned by admin
+func (ef *ExportFuncs) checkAdminProject() error {
+	prj, ok := types.ProjectFromContext(ef.ctx)
 	if !ok {
-		return int32(wasm.ResultStatusCode_EnvKeyNotFound)
+		return errors.New("project not found in context")
+	}
+	d, ok := types.MgrDBExecutorFromContext(ef.ctx)
+	if !ok {
+		return errors.New("database not found in context")
 	}
+	acc := &models.Account{RelAccount: models.RelAccount{AccountID: prj.AccountID}}
+	if err := acc.FetchByAccountID(d); err != nil {
+		return err
+	}
+	if acc.Role != enums.ACCOUNT_ROLE__ADMIN {
+		return errors.New("admin permission required")
+	}
+	return nil
+}
 
-	if err = ef.rt.Copy([]byte(val), vmAddrPtr, vmSizePtr); err != nil {
+func (ef *ExportFuncs) SetSQLDB(addr, size int32) int32 {
+	if ef.db == nil {
+		return int32(wasm.ResultStatusCode_NoDBContext)
+	}
+	data, err := ef.rt.Read(addr, size)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_ResourceNotFound)
+	}
+
+	prestate, params, err := sql_util.ParseQuery(data)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+
+	db, err := ef.db.WithDefaultSchema()
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	err = ef.withStatementTimeout(db, func(db sqlx.DBExecutor) error {
+		_, err := db.ExecContext(context.Background(), prestate, params...)
+		return err
+	})
+	if err != nil {
+		ef.logSQLError(err)
+		return wasm.ResultStatusCode_Failed
+	}
+
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// withStatementTimeout runs fn in a transaction of db which statement_timeout
+// is limited to MaxQueryExecutionMs of wasm db config
+func (ef *ExportFuncs) withStatementTimeout(db sqlx.DBExecutor, fn func(db sqlx.DBExecutor) error) error {
+	cfg, ok := types.WasmDBConfigFromContext(ef.ctx)
+	if !ok || cfg == nil || cfg.MaxQueryExecutionMs <= 0 {
+		return fn(db)
+	}
+	return sqlx.NewTasks(db).With(func(db sqlx.DBExecutor) error {
+		stmt := fmt.Sprintf("SET LOCAL statement_timeout = %d", cfg.MaxQueryExecutionMs)
+		if _, err := db.ExecContext(context.Background(), stmt); err != nil {
+			return err
+		}
+		return fn(db)
+	}).Do()
+}
+
+// logSQLError logs err of sql executed by wasm, the statement timeout is logged
+// as query timeout
+func (ef *ExportFuncs) logSQLError(err error) {
+	if sql_util.IsStatementTimeout(err) {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, fmt.Sprintf("query timeout: %v", err))
+		return
+	}
+	ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+}
+
+// SoftDelete flags the rows matching condition of soft delete table as
+// deleted. condition is formatted as the query of ws_set_sql_db, eg:
+// `{"statement":"f_id = $1","params":[{"int64":1}]}`
+func (ef *ExportFuncs) SoftDelete(tableNameAddr, tableNameSize, conditionAddr, conditionSize int32) int32 {
+	if ef.db == nil {
+		return int32(wasm.ResultStatusCode_NoDBContext)
+	}
+	table, err := ef.rt.Read(tableNameAddr, tableNameSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+	data, err := ef.rt.Read(conditionAddr, conditionSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+
+	found := false
+	for _, name := range ef.db.SoftDeleteTables("public") {
+		found = found || name == string(table)
+	}
+	if !found {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, fmt.Sprintf("table %s is not a soft delete table", table))
+		return int32(wasm.ResultStatusCode_ParamIllegal)
+	}
+
+	cond, params, err := sql_util.ParseQuery(data)
+	if err != nil || strings.Contains(cond, ";") {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, fmt.Sprintf("invalid condition: %s", data))
+		return int32(wasm.ResultStatusCode_ParamIllegal)
+	}
+	if strings.TrimSpace(cond) == "" {
+		cond = "TRUE"
+	}
+
+	db, err := ef.db.WithDefaultSchema()
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	prestate := fmt.Sprintf(`UPDATE "%s" SET %s = TRUE, %s = %d WHERE %s = FALSE AND (%s)`,
+		table, wasm.SoftDeleteColumn, wasm.SoftDeleteAtColumn, time.Now().UnixMilli(), wasm.SoftDeleteColumn, cond)
+	if _, err = db.ExecContext(context.Background(), prestate, params...); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// GetSQLDBUpsert inserts a row into table, the row conflicted on conflictCols
+// is updated with the other columns. the input is formatted as:
+// `{"table":"t_device","cols":[{"name":"f_id","value":{"int64":1}}],"conflictCols":["f_id"]}`
+// all column names must be defined in the table schema
+func (ef *ExportFuncs) GetSQLDBUpsert(addr, size int32) int32 {
+	if ef.db == nil {
+		return int32(wasm.ResultStatusCode_NoDBContext)
+	}
+	data, err := ef.rt.Read(addr, size)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+	if !gjson.ValidBytes(data) {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, fmt.Sprintf("invalid upsert: %s", data))
+		return int32(wasm.ResultStatusCode_ParamIllegal)
+	}
+
+	req := gjson.ParseBytes(data)
+	table := req.Get("table").String()
+	defined, ok := ef.db.TableColumns("public", table)
+	if !ok {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, fmt.Sprintf("table %s is not defined", table))
+		return int32(wasm.ResultStatusCode_ParamIllegal)
+	}
+	exists := make(map[string]bool, len(defined))
+	for _, c := range defined {
+		exists[c] = true
+	}
+
+	var (
+		cols, conflictCols []string
+		params             []interface{}
+	)
+	for _, c := range req.Get("cols").Array() {
+		name := c.Get("name").String()
+		if !exists[name] {
+			ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, fmt.Sprintf("column %s is not defined in table %s", name, table))
+			return int32(wasm.ResultStatusCode_ParamIllegal)
+		}
+		value := c.Get("value")
+		param, err := sql_util.DecodeQueryParam(&value)
+		if err != nil {
+			ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+			return int32(wasm.ResultStatusCode_ParamIllegal)
+		}
+		cols = append(cols, name)
+		params = append(params, param)
+	}
+	for _, c := range req.Get("conflictCols").Array() {
+		if !exists[c.String()] {
+			ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, fmt.Sprintf("column %s is not defined in table %s", c.String(), table))
+			return int32(wasm.ResultStatusCode_ParamIllegal)
+		}
+		conflictCols = append(conflictCols, c.String())
+	}
+
+	prestate, err := sql_util.UpsertStatement(table, cols, conflictCols)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_ParamIllegal)
+	}
+
+	db, err := ef.db.WithDefaultSchema()
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	if _, err = db.ExecContext(context.Background(), prestate, params...); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// GetSQLDBTx executes queries in a transaction, all statements are committed or
+// rolled back together. the affected rows of each statement are returned as a
+// json array if committed, otherwise the error message is returned
+func (ef *ExportFuncs) GetSQLDBTx(addr, size int32, vmAddrPtr, vmSizePtr int32) int32 {
+	if ef.db == nil {
+		return int32(wasm.ResultStatusCode_NoDBContext)
+	}
+	data, err := ef.rt.Read(addr, size)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_ResourceNotFound)
+	}
+
+	prestates, params, err := sql_util.ParseQueries(data)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_ParamIllegal)
+	}
+
+	db, err := ef.db.WithDefaultSchema()
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+
+	affected := make([]int64, len(prestates))
+	tasks := sqlx.NewTasks(db)
+	for i := range prestates {
+		i := i
+		tasks = tasks.With(func(d sqlx.DBExecutor) error {
+			ret, err := d.ExecContext(context.Background(), prestates[i], params[i]...)
+			if err != nil {
+				return errors.Wrapf(err, "statement %d", i)
+			}
+			affected[i], err = ret.RowsAffected()
+			return err
+		})
+	}
+
+	var (
+		ret  []byte
+		code = int32(wasm.ResultStatusCode_OK)
+	)
+	if err = tasks.Do(); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		ret, code = []byte(err.Error()), wasm.ResultStatusCode_Failed
+	} else {
+		ret, _ = json.Marshal(affected)
+	}
+
+	if err = ef.rt.Copy(ret, vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return code
+}
+
+// GetSQLDBBatch executes SELECT queries in one read only transaction, queries
+// are formatted as the input of ws_get_sql_db_tx. the transaction is
+// REPEATABLE READ so all queries read the same snapshot. it writes a json array
+// of the result rows of each query
+func (ef *ExportFuncs) GetSQLDBBatch(addr, size int32, vmAddrPtr, vmSizePtr int32) int32 {
+	if ef.db == nil {
+		return int32(wasm.ResultStatusCode_NoDBContext)
+	}
+	data, err := ef.rt.Read(addr, size)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_ResourceNotFound)
+	}
+
+	prestates, params, err := sql_util.ParseQueries(data)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_ParamIllegal)
+	}
+	softDeletes := ef.db.SoftDeleteTables("public")
+	for i := range prestates {
+		if !sql_util.IsSelectStatement(prestates[i]) {
+			ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, fmt.Sprintf("statement %d is not a SELECT statement", i))
+			return int32(wasm.ResultStatusCode_ParamIllegal)
+		}
+		prestates[i] = sql_util.ShadowTables(prestates[i], "public", softDeletes, wasm.SoftDeleteColumn+" = FALSE")
+	}
+
+	db, err := ef.db.WithDefaultSchema()
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+
+	results := make([]json.RawMessage, len(prestates))
+	err = sqlx.NewTasks(db).With(func(db sqlx.DBExecutor) error {
+		// must be the first statement of transaction
+		if _, err := db.ExecContext(context.Background(), "SET TRANSACTION READ ONLY, ISOLATION LEVEL REPEATABLE READ"); err != nil {
+			return err
+		}
+		return ef.withStatementTimeout(db, func(db sqlx.DBExecutor) error {
+			for i := range prestates {
+				rows, err := db.QueryContext(context.Background(), prestates[i], params[i]...)
+				if err != nil {
+					return errors.Wrapf(err, "statement %d", i)
+				}
+				results[i], err = sql_util.JsonifyRows(rows)
+				rows.Close()
+				if err != nil {
+					return errors.Wrapf(err, "statement %d", i)
+				}
+			}
+			return nil
+		})
+	}).Do()
+	if err != nil {
+		ef.logSQLError(err)
+		return wasm.ResultStatusCode_Failed
+	}
+
+	ret, err := json.Marshal(results)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_HostInternal)
+	}
+	if err = ef.rt.Copy(ret, vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+func (ef *ExportFuncs) GetSQLDB(addr, size int32, vmAddrPtr, vmSizePtr int32) int32 {
+	if ef.db == nil {
+		return int32(wasm.ResultStatusCode_NoDBContext)
+	}
+	data, err := ef.rt.Read(addr, size)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_ResourceNotFound)
+	}
+
+	prestate, params, err := sql_util.ParseQuery(data)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	prestate = sql_util.ShadowTables(prestate, "public", ef.db.SoftDeleteTables("public"), wasm.SoftDeleteColumn+" = FALSE")
+
+	db, err := ef.db.WithDefaultSchema()
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	var ret []byte
+	err = ef.withStatementTimeout(db, func(db sqlx.DBExecutor) error {
+		rows, err := db.QueryContext(context.Background(), prestate, params...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		ret, err = sql_util.JsonifyRows(rows)
+		return err
+	})
+	if err != nil {
+		ef.logSQLError(err)
+		return wasm.ResultStatusCode_Failed
+	}
+
+	if err := ef.rt.Copy(ret, vmAddrPtr, vmSizePtr); err != nil {
 		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
 		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
 	}
+
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+const (
+	defaultPaginatePageSize = 100
+	maxPaginatePageSize     = 1000
+)
+
+// GetSQLDBPaginate queries a page of table rows by keyset pagination, the
+// request is like
+// `{"table":"t_event","orderCol":"f_id","lastValue":{"int64":10},"pageSize":20,"filters":[{"col":"f_device","op":"=","val":{"string":"d1"}}]}`
+// lastValue is omitted for the first page. orderCol must be the only column of
+// a unique key of table. it writes
+// `{"rows":[...],"lastValue":<typed orderCol of the last row, null if no rows>}`
+// eg: `{"rows":[...],"lastValue":{"int64":30}}`
+func (ef *ExportFuncs) GetSQLDBPaginate(addr, size int32, vmAddrPtr, vmSizePtr int32) int32 {
+	if ef.db == nil {
+		return int32(wasm.ResultStatusCode_NoDBContext)
+	}
+	data, err := ef.rt.Read(addr, size)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_ResourceNotFound)
+	}
+	if !gjson.ValidBytes(data) {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, fmt.Sprintf("invalid paginate: %s", data))
+		return int32(wasm.ResultStatusCode_ParamIllegal)
+	}
+
+	req := gjson.ParseBytes(data)
+	table, orderCol := req.Get("table").String(), req.Get("orderCol").String()
+	defined, ok := ef.db.TableColumns("public", table)
+	if !ok {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, fmt.Sprintf("table %s is not defined", table))
+		return int32(wasm.ResultStatusCode_ParamIllegal)
+	}
+	exists := make(map[string]bool, len(defined))
+	for _, c := range defined {
+		exists[c] = true
+	}
+	// rows with the same cursor value are skipped or repeated across pages
+	datatype, ok := ef.db.UniqueColumn("public", table, orderCol)
+	if !ok {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, fmt.Sprintf("order column %s is not the only column of a unique key of table %s", orderCol, table))
+		return int32(wasm.ResultStatusCode_ParamIllegal)
+	}
+
+	var (
+		filters []sql_util.PaginateFilter
+		params  []interface{}
+	)
+	cols := []string{orderCol}
+	if last := req.Get("lastValue"); last.Exists() {
+		param, err := sql_util.DecodeQueryParam(&last)
+		if err != nil {
+			ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+			return int32(wasm.ResultStatusCode_ParamIllegal)
+		}
+		params = append(params, param)
+	}
+	for _, f := range req.Get("filters").Array() {
+		val := f.Get("val")
+		param, err := sql_util.DecodeQueryParam(&val)
+		if err != nil {
+			ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+			return int32(wasm.ResultStatusCode_ParamIllegal)
+		}
+		filters = append(filters, sql_util.PaginateFilter{Col: f.Get("col").String(), Op: f.Get("op").String()})
+		cols = append(cols, f.Get("col").String())
+		params = append(params, param)
+	}
+	for _, c := range cols {
+		if !exists[c] {
+			ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, fmt.Sprintf("column %s is not defined in table %s", c, table))
+			return int32(wasm.ResultStatusCode_ParamIllegal)
+		}
+	}
+
+	pageSize := int(req.Get("pageSize").Int())
+	if pageSize <= 0 {
+		pageSize = defaultPaginatePageSize
+	}
+	if pageSize > maxPaginatePageSize {
+		pageSize = maxPaginatePageSize
+	}
+
+	prestate, err := sql_util.PaginateStatement(table, orderCol, req.Get("lastValue").Exists(), filters, pageSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_ParamIllegal)
+	}
+	prestate = sql_util.ShadowTables(prestate, "public", ef.db.SoftDeleteTables("public"), wasm.SoftDeleteColumn+" = FALSE")
+
+	db, err := ef.db.WithDefaultSchema()
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	var rows []byte
+	err = ef.withStatementTimeout(db, func(db sqlx.DBExecutor) error {
+		rs, err := db.QueryContext(context.Background(), prestate, params...)
+		if err != nil {
+			return err
+		}
+		defer rs.Close()
+		rows, err = sql_util.JsonifyRows(rs)
+		return err
+	})
+	if err != nil {
+		ef.logSQLError(err)
+		return wasm.ResultStatusCode_Failed
+	}
+
+	ret, err := paginatePage(rows, orderCol, datatype)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+
+	if err = ef.rt.Copy(ret, vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// paginatePage builds the page result of jsonified rows, lastValue is the
+// orderCol value of the last row encoded as typed query param, so it can be
+// passed as the lastValue of next page request as is
+func paginatePage(rows []byte, orderCol string, datatype enums.WasmDBDatatype) ([]byte, error) {
+	// JsonifyRows returns an empty result if no rows and an object if one row
+	entries := make([]json.RawMessage, 0)
+	switch res := gjson.ParseBytes(rows); {
+	case len(rows) == 0:
+	case res.IsObject():
+		entries = append(entries, rows)
+	default:
+		if err := json.Unmarshal(rows, &entries); err != nil {
+			return nil, err
+		}
+	}
+
+	page := struct {
+		Rows      []json.RawMessage `json:"rows"`
+		LastValue json.RawMessage   `json:"lastValue"`
+	}{Rows: entries, LastValue: json.RawMessage("null")}
+	if len(entries) > 0 {
+		row := make(map[string]json.RawMessage)
+		if err := json.Unmarshal(entries[len(entries)-1], &row); err != nil {
+			return nil, err
+		}
+		if last := gjson.ParseBytes(row[orderCol]); last.Exists() && last.Type != gjson.Null {
+			v, err := sql_util.EncodeQueryParam(datatype, last)
+			if err != nil {
+				return nil, err
+			}
+			page.LastValue = v
+		}
+	}
+	return json.Marshal(page)
+}
+
+// GetSQLDBExplain executes the SELECT query with EXPLAIN and writes the json
+// query plan. it is available only if debug mode of project env is enabled
+func (ef *ExportFuncs) GetSQLDBExplain(addr, size int32, vmAddrPtr, vmSizePtr int32) int32 {
+	if ef.env == nil || !ef.env.DebugMode {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, "ws_get_sql_db_explain is only available in debug mode")
+		return wasm.ResultStatusCode_Failed
+	}
+	if ef.db == nil {
+		return int32(wasm.ResultStatusCode_NoDBContext)
+	}
+	data, err := ef.rt.Read(addr, size)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_ResourceNotFound)
+	}
+
+	prestate, params, err := sql_util.ParseQuery(data)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	prestate, err = sql_util.ExplainStatement(prestate)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_ParamIllegal)
+	}
+
+	db, err := ef.db.WithDefaultSchema()
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+
+	var plan []byte
+	rows, err := db.QueryContext(context.Background(), prestate, params...)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	defer rows.Close()
+	if rows.Next() {
+		err = rows.Scan(&plan)
+	} else {
+		err = rows.Err()
+	}
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+
+	if err = ef.rt.Copy(plan, vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+func (ef *ExportFuncs) GetSQLDBCount(addr, size int32, vmAddrPtr, vmSizePtr int32) int32 {
+	if ef.db == nil {
+		return int32(wasm.ResultStatusCode_NoDBContext)
+	}
+	data, err := ef.rt.Read(addr, size)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_ResourceNotFound)
+	}
+
+	prestate, params, err := sql_util.ParseQuery(data)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	prestate = sql_util.ShadowTables(prestate, "public", ef.db.SoftDeleteTables("public"), wasm.SoftDeleteColumn+" = FALSE")
+	prestate, err = sql_util.CountStatement(prestate)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_ParamIllegal)
+	}
+
+	db, err := ef.db.WithDefaultSchema()
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+
+	var count int64
+	rows, err := db.QueryContext(context.Background(), prestate, params...)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	defer rows.Close()
+	if rows.Next() {
+		if err = rows.Scan(&count); err != nil {
+			ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+			return wasm.ResultStatusCode_Failed
+		}
+	}
+
+	if err := ef.rt.Copy([]byte(strconv.FormatInt(count, 10)), vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// GetSQLDBSchemaVersion returns the latest migration version of default schema
+func (ef *ExportFuncs) GetSQLDBSchemaVersion(vmAddrPtr, vmSizePtr int32) int32 {
+	if ef.db == nil {
+		return int32(wasm.ResultStatusCode_NoDBContext)
+	}
+
+	version, err := ef.db.SchemaVersion(context.Background(), "")
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		if errors.Is(err, wasm.ErrNoMigrationHistory) {
+			return int32(wasm.ResultStatusCode_NoDBContext)
+		}
+		return wasm.ResultStatusCode_Failed
+	}
+
+	if err = ef.rt.Copy([]byte(strconv.FormatInt(version, 10)), vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// GetSQLDBChanges subscribes the row changes of table, which is `table` or
+// `schema.table`. handler is invoked with __db_change__ event of each change
+// until the instance stopped. it requires logical replication enabled
+func (ef *ExportFuncs) GetSQLDBChanges(tableAddr, tableSize, handlerAddr, handlerSize int32) int32 {
+	if ef.db == nil {
+		return int32(wasm.ResultStatusCode_NoDBContext)
+	}
+	table, err := ef.rt.Read(tableAddr, tableSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+	handler, err := ef.rt.Read(handlerAddr, handlerSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+	if ef.dispatch == nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, "table change subscription is not supported")
+		return wasm.ResultStatusCode_Failed
+	}
+
+	schema, tbl := "", string(table)
+	if idx := strings.Index(tbl, "."); idx >= 0 {
+		schema, tbl = tbl[:idx], tbl[idx+1:]
+	}
+
+	// the callback outlives the invocation, whose ExportFuncs is reused by
+	// the following invocations
+	sub := ef.fork(nil, nil)
+
+	h := string(handler)
+	key := string(table) + ":" + h
+	err = ef.cdcs.Add(key, func(ctx context.Context) error {
+		return sub.db.WatchTableChanges(ctx, schema, tbl, func(c *sql_util.TableChange) {
+			payload, _ := json.Marshal(c)
+			ctx := types.WithEventID(sub.ctx, uuid.NewString()+"_db_change")
+			if rsp := sub.dispatch(ctx, h, eventTypeDBChange, payload); rsp.ErrMsg != "" {
+				sub.logAndPersistToDB(conflog.ErrorLevel, efSrc, rsp.ErrMsg)
+			}
+		}, func(err error) {
+			// the watch is ended, removes it to allow subscribing again
+			sub.cdcs.Remove(key)
+			sub.logAndPersistToDB(conflog.ErrorLevel, efSrc, errors.Wrapf(err, "watch changes of %s", table).Error())
+		})
+	})
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		if errors.Is(err, wasm.ErrTooManyTableChangeWatches) {
+			return int32(wasm.ResultStatusCode_RateLimited)
+		}
+		return wasm.ResultStatusCode_Failed
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// GetSQLDBIndexes writes the json array of indexes of table, which is `table`
+// or `schema.table`, as `[{"indexname":"","indexdef":"","tablespace":""}]`
+func (ef *ExportFuncs) GetSQLDBIndexes(tableAddr, tableSize int32, vmAddrPtr, vmSizePtr int32) int32 {
+	if ef.db == nil {
+		return int32(wasm.ResultStatusCode_NoDBContext)
+	}
+	table, err := ef.rt.Read(tableAddr, tableSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+
+	schema, tbl := "", string(table)
+	if idx := strings.Index(tbl, "."); idx >= 0 {
+		schema, tbl = tbl[:idx], tbl[idx+1:]
+	}
+
+	indexes, err := ef.db.TableIndexes(context.Background(), schema, tbl)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	data, _ := json.Marshal(indexes)
+	if err = ef.rt.Copy(data, vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// DBMigrate migrates the new schemas, tables, columns and keys defined in the
+// database config fragment, it is allowed only if AllowRuntimeMigration is set.
+// the merged definition is persisted as project database config
+func (ef *ExportFuncs) DBMigrate(payloadAddr, payloadSize int32) int32 {
+	if ef.db == nil {
+		return int32(wasm.ResultStatusCode_NoDBContext)
+	}
+	if !ef.db.AllowRuntimeMigration {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, "runtime migration is not allowed")
+		return wasm.ResultStatusCode_Failed
+	}
+	payload, err := ef.rt.Read(payloadAddr, payloadSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+
+	fragment := &wasm.Database{}
+	if err = json.Unmarshal(payload, fragment); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_ParamIllegal)
+	}
+	if len(fragment.Schemas) == 0 {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, "schemas is required")
+		return int32(wasm.ResultStatusCode_ParamIllegal)
+	}
+
+	if err = ef.db.AddSchema(context.Background(), fragment.Schemas...); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	if err = ef.persistDatabaseConfig(); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, errors.Wrap(err, "persist database config").Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// persistDatabaseConfig writes the database definition merged at runtime to
+// the project database config, so the schemas migrated are still defined
+// after the project redeployed
+func (ef *ExportFuncs) persistDatabaseConfig() error {
+	d, ok := types.MgrDBExecutorFromContext(ef.ctx)
+	if !ok {
+		return errors.New("mgr database not found")
+	}
+	raw, err := ef.db.Definition()
+	if err != nil {
+		return err
+	}
+	m := &models.Config{ConfigBase: models.ConfigBase{
+		RelID: types.MustProjectFromContext(ef.ctx).ProjectID,
+		Type:  enums.CONFIG_TYPE__PROJECT_DATABASE,
+		Value: raw,
+	}}
+	return m.UpdateByRelIDAndType(d)
+}
+
+// TODO: make sendTX async, and add callback if possible
+func (ef *ExportFuncs) SendTX(chainID int32, offset, size, vmAddrPtr, vmSizePtr int32) int32 {
+	if ef.cl == nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, errors.New("eth client doesn't exist").Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	buf, err := ef.rt.Read(offset, size)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	ret := gjson.Parse(string(buf))
+	txHash, err := ef.cl.SendTX(ef.cf, uint64(chainID), "", ret.Get("to").String(), ret.Get("value").String(), ret.Get("data").String(), ef.opPool, types.MustProjectFromContext(ef.ctx))
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	if err := ef.rt.Copy([]byte(txHash), vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// SendTXRelay sends meta-transaction through EIP-2771 relayer, the tx hash
+// responded by relayer is returned. the relayer should be listed in RelayerURLs
+// of project env
+func (ef *ExportFuncs) SendTXRelay(chainID int32, offset, size, vmAddrPtr, vmSizePtr int32) int32 {
+	if ef.cl == nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, errors.New("eth client doesn't exist").Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	buf, err := ef.rt.Read(offset, size)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+	tx := &wasm.RelayTx{}
+	if err = json.Unmarshal(buf, tx); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_ParamIllegal)
+	}
+	if ef.env == nil || !ef.env.RelayerAllowed(tx.RelayerURL) {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, fmt.Sprintf("relayer %s is not allowed", tx.RelayerURL))
+		return int32(wasm.ResultStatusCode_ParamIllegal)
+	}
+	txHash, err := ef.cl.SendTXRelay(ef.cf, uint64(chainID), tx, ef.opPool, types.MustProjectFromContext(ef.ctx))
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	if err = ef.rt.Copy([]byte(txHash), vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+func (ef *ExportFuncs) SendTXWithOperator(chainID int32, offset, size, vmAddrPtr, vmSizePtr int32) int32 {
+	if ef.cl == nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, errors.New("eth client doesn't exist").Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	buf, err := ef.rt.Read(offset, size)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	ret := gjson.Parse(string(buf))
+	txHash, err := ef.cl.SendTXWithOperator(ef.cf, uint64(chainID), "", ret.Get("to").String(), ret.Get("value").String(), ret.Get("data").String(), ret.Get("operatorName").String(), ef.opPool, types.MustProjectFromContext(ef.ctx))
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	if err := ef.rt.Copy([]byte(txHash), vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+func (ef *ExportFuncs) SendTXEstimateGas(chainID int32, offset, size, vmAddrPtr, vmSizePtr int32) int32 {
+	if ef.cl == nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, errors.New("eth client doesn't exist").Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	buf, err := ef.rt.Read(offset, size)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	ret := gjson.Parse(string(buf))
+	msg, err := wasm.NewCallMsg(ret.Get("from").String(), ret.Get("to").String(), ret.Get("value").String(), ret.Get("data").String(), ret.Get("operatorName").String(), ef.opPool, types.MustProjectFromContext(ef.ctx))
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	gas, err := ef.cl.EstimateGas(ef.cf, uint64(chainID), msg)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	if err := ef.rt.Copy([]byte(strconv.FormatUint(gas, 10)), vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+func (ef *ExportFuncs) SendMqttMsg(topicAddr, topicSize, msgAddr, msgSize int32) int32 {
+	if ef.mq == nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, errors.New("mq client doesn't exist").Error())
+		return wasm.ResultStatusCode_Failed
+	}
+
+	var (
+		topicBuf []byte
+		msgBuf   []byte
+		err      error
+	)
+
+	topicBuf, err = ef.rt.Read(topicAddr, topicSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	msgBuf, err = ef.rt.Read(msgAddr, msgSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	err = ef.mq.WithTopic(string(topicBuf)).Publish(string(msgBuf))
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// PublishNATS publishes message to subject by NATS JetStream, empty subject
+// means the default subject of nats config
+func (ef *ExportFuncs) PublishNATS(subjectAddr, subjectSize, msgAddr, msgSize int32) int32 {
+	if ef.nats == nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, "nats is not configured")
+		return wasm.ResultStatusCode_Failed
+	}
+
+	subject, err := ef.rt.Read(subjectAddr, subjectSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+	msg, err := ef.rt.Read(msgAddr, msgSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+
+	if err = ef.nats.Publish(string(subject), msg); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// AddIPFS uploads data to ipfs and writes its cid, the data size is limited to
+// confipfs.MaxDataSize
+func (ef *ExportFuncs) AddIPFS(dataAddr, dataSize int32, vmAddrPtr, vmSizePtr int32) int32 {
+	if ef.ipfs == nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, "ipfs is not configured")
+		return wasm.ResultStatusCode_Failed
+	}
+	if dataSize > confipfs.MaxDataSize {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, fmt.Sprintf("data size exceeds %d bytes", confipfs.MaxDataSize))
+		return int32(wasm.ResultStatusCode_ParamIllegal)
+	}
+
+	data, err := ef.rt.Read(dataAddr, dataSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+	cid, err := ef.ipfs.Add(data)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	if err = ef.rt.Copy([]byte(cid), vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// CatIPFS reads data of cid from ipfs, the data size is limited to
+// confipfs.MaxDataSize
+func (ef *ExportFuncs) CatIPFS(cidAddr, cidSize int32, vmAddrPtr, vmSizePtr int32) int32 {
+	if ef.ipfs == nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, "ipfs is not configured")
+		return wasm.ResultStatusCode_Failed
+	}
+
+	cid, err := ef.rt.Read(cidAddr, cidSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+	data, err := ef.ipfs.Cat(string(cid))
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	if err = ef.rt.Copy(data, vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// GrpcCall makes an outbound unary grpc call with proto encoded request body,
+// and copies base64 encoded response message to vm. the endpoint must be
+// allowed by GrpcAllowedDomains of project env
+func (ef *ExportFuncs) GrpcCall(payloadAddr, payloadSize int32, vmAddrPtr, vmSizePtr int32) int32 {
+	payload, err := ef.rt.Read(payloadAddr, payloadSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+	req := &GrpcCallReq{}
+	if err = json.Unmarshal(payload, req); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_ParamIllegal)
+	}
+	if ef.env == nil || !grpcEndpointAllowed(req.Endpoint, ef.env.GrpcAllowedDomains) {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, fmt.Sprintf("grpc endpoint %s is not allowed", req.Endpoint))
+		return int32(wasm.ResultStatusCode_ParamIllegal)
+	}
+
+	rsp, err := grpcCall(ef.ctx, req)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	if err = ef.rt.Copy([]byte(base64.StdEncoding.EncodeToString(rsp)), vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// SendSlackMessage sends message to the slack incoming webhook configured by
+// project env SlackWebhookURL, message to the same channel of project is
+// limited to one per slackMessageInterval
+func (ef *ExportFuncs) SendSlackMessage(payloadAddr, payloadSize int32) int32 {
+	if ef.env == nil || ef.env.SlackWebhookURL == "" {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, "slack webhook is not configured")
+		return int32(wasm.ResultStatusCode_EnvKeyNotFound)
+	}
+
+	payload, err := ef.rt.Read(payloadAddr, payloadSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+
+	msg, err := parseSlackMessage(payload)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_ParamIllegal)
+	}
+
+	prj := types.MustProjectFromContext(ef.ctx)
+	if !slackLimiter.Allow(prj.ProjectID.String(), msg.Channel, time.Now()) {
+		ef.logAndPersistToDB(conflog.WarnLevel, efSrc, fmt.Sprintf("slack message to channel `%s` is rate limited", msg.Channel))
+		return int32(wasm.ResultStatusCode_RateLimited)
+	}
+
+	if err = postSlackMessage(context.Background(), ef.env.SlackWebhookURL, msg); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// ListOperators writes the json array of operator names of project owner, the
+// keys and addresses of operators are not exposed
+func (ef *ExportFuncs) ListOperators(vmAddrPtr, vmSizePtr int32) int32 {
+	names, err := ef.opPool.List(types.MustProjectFromContext(ef.ctx).AccountID)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	data, err := json.Marshal(names)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_HostInternal)
+	}
+	if err = ef.rt.Copy(data, vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// GetOperatorBalance writes the balance in wei of operator as a decimal string,
+// it returns ResourceNotFound if the operator is unknown
+func (ef *ExportFuncs) GetOperatorBalance(chainID int32, nameAddr, nameSize int32, vmAddrPtr, vmSizePtr int32) int32 {
+	if ef.cl == nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, errors.New("eth client doesn't exist").Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	name, err := ef.rt.Read(nameAddr, nameSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+	op, err := ef.opPool.Get(types.MustProjectFromContext(ef.ctx).AccountID, string(name))
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		if err == status.OperatorNotFound {
+			return int32(wasm.ResultStatusCode_ResourceNotFound)
+		}
+		return wasm.ResultStatusCode_Failed
+	}
+	balance, err := ef.cl.BalanceOf(ef.cf, uint64(chainID), op)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	if err = ef.rt.Copy([]byte(balance.String()), vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// GetOperatorPendingTxCount writes the count of unconfirmed transactions of
+// operator as a decimal string, it helps wasm to avoid reusing a stale nonce
+// when sending transactions in rapid succession. it returns ResourceNotFound if
+// the operator is unknown
+func (ef *ExportFuncs) GetOperatorPendingTxCount(chainID int32, nameAddr, nameSize int32, vmAddrPtr, vmSizePtr int32) int32 {
+	if ef.cl == nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, errors.New("eth client doesn't exist").Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	name, err := ef.rt.Read(nameAddr, nameSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+	op, err := ef.opPool.Get(types.MustProjectFromContext(ef.ctx).AccountID, string(name))
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		if err == status.OperatorNotFound {
+			return int32(wasm.ResultStatusCode_ResourceNotFound)
+		}
+		return wasm.ResultStatusCode_Failed
+	}
+	count, err := ef.cl.PendingTxCount(ef.cf, uint64(chainID), op)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	if err = ef.rt.Copy([]byte(strconv.FormatUint(count, 10)), vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// SignEthMessage signs message in EIP-191 personal_sign format by the named
+// operator, and writes the 65 bytes signature as 0x prefixed hex. it returns
+// ResourceNotFound if the operator is unknown
+func (ef *ExportFuncs) SignEthMessage(nameAddr, nameSize, msgAddr, msgSize int32, vmAddrPtr, vmSizePtr int32) int32 {
+	name, err := ef.rt.Read(nameAddr, nameSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+	msg, err := ef.rt.Read(msgAddr, msgSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+	op, err := ef.opPool.Get(types.MustProjectFromContext(ef.ctx).AccountID, string(name))
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		if err == status.OperatorNotFound {
+			return int32(wasm.ResultStatusCode_ResourceNotFound)
+		}
+		return wasm.ResultStatusCode_Failed
+	}
+	key := op.Operator()
+	if key.Type != enums.OPERATOR_KEY__ECDSA {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, fmt.Sprintf("operator %s is not an ECDSA key", name))
+		return int32(wasm.ResultStatusCode_ParamIllegal)
+	}
+
+	sig, err := signEthMessage(key.PrivateKey, msg)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	if err = ef.rt.Copy([]byte(sig), vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// WaitForTx waits the transaction of txHash mined in timeoutMs milliseconds, and
+// returns the receipt json
+func (ef *ExportFuncs) WaitForTx(chainID int32, txHashAddr, txHashSize int32, timeoutMs int32, vmAddrPtr, vmSizePtr int32) int32 {
+	if ef.cl == nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, "eth client doesn't exist")
+		return wasm.ResultStatusCode_Failed
+	}
+	if timeoutMs <= 0 {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, fmt.Sprintf("invalid timeout: %d", timeoutMs))
+		return int32(wasm.ResultStatusCode_ParamIllegal)
+	}
+	txHash, err := ef.rt.Read(txHashAddr, txHashSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+
+	ctx, cancel := context.WithTimeout(ef.ctx, time.Duration(timeoutMs)*time.Millisecond)
+	defer cancel()
+
+	receipt, err := ef.cl.WaitForTx(ctx, ef.cf, uint64(chainID), string(txHash), waitForTxInterval)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		if errors.Is(err, context.DeadlineExceeded) {
+			return int32(wasm.ResultStatusCode_Timeout)
+		}
+		return wasm.ResultStatusCode_Failed
+	}
+
+	data, err := json.Marshal(receipt)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_HostInternal)
+	}
+	if err = ef.rt.Copy(data, vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+func (ef *ExportFuncs) CallContract(chainID int32, offset, size int32, vmAddrPtr, vmSizePtr int32) int32 {
+	if ef.cl == nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, errors.New("eth client doesn't exist").Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	buf, err := ef.rt.Read(offset, size)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	ret := gjson.Parse(string(buf))
+	data, err := ef.cl.CallContract(ef.cf, uint64(chainID), "", ret.Get("to").String(), ret.Get("data").String())
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	if err = ef.rt.Copy(data, vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+func (ef *ExportFuncs) CallContractMulticall(chainID int32, offset, size int32, vmAddrPtr, vmSizePtr int32) int32 {
+	if ef.cl == nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, errors.New("eth client doesn't exist").Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	buf, err := ef.rt.Read(offset, size)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	calls := make([]wasm.ContractCall, 0)
+	if err = json.Unmarshal(buf, &calls); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	results, err := ef.cl.CallContractMulticall(ef.cf, uint64(chainID), "", calls)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	data, err := json.Marshal(results)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_HostInternal)
+	}
+	if err = ef.rt.Copy(data, vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// GetContractEventsSince writes the contract events emitted after `lastBlock`
+// and the new `lastBlock` cursor, wasm should persist the cursor by itself. the
+// blocks scanned and events returned per call are capped, so wasm should query
+// again from the cursor to catch up
+func (ef *ExportFuncs) GetContractEventsSince(chainID int32, offset, size int32, vmAddrPtr, vmSizePtr int32) int32 {
+	if ef.cl == nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, errors.New("eth client doesn't exist").Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	buf, err := ef.rt.Read(offset, size)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+	q := &wasm.ContractEventsQuery{}
+	if err = json.Unmarshal(buf, q); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_ParamIllegal)
+	}
+	ret, err := ef.cl.GetContractEventsSince(ef.cf, uint64(chainID), q)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	data, err := json.Marshal(ret)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_HostInternal)
+	}
+	if err = ef.rt.Copy(data, vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// GetBlockByNumber returns the block metadata json, blockNumber -1 for the
+// latest block
+func (ef *ExportFuncs) GetBlockByNumber(chainID int32, blockNumber int64, vmAddrPtr, vmSizePtr int32) int32 {
+	if ef.cl == nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, errors.New("eth client doesn't exist").Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	if blockNumber < -1 {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, fmt.Sprintf("invalid block number: %d", blockNumber))
+		return int32(wasm.ResultStatusCode_ParamIllegal)
+	}
+	ret, err := ef.cl.GetBlock(ef.cf, uint64(chainID), blockNumber)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	data, err := json.Marshal(ret)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_HostInternal)
+	}
+	if err = ef.rt.Copy(data, vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// GetStorageProof writes the EIP-1186 proof json of account and storage slots,
+// the input is formatted as:
+// `{"address":"0x...","storageKeys":["0x0"],"blockNumber":-1}`, blockNumber is
+// optional and the latest block is used if it is omitted or negative
+func (ef *ExportFuncs) GetStorageProof(chainID int32, offset, size int32, vmAddrPtr, vmSizePtr int32) int32 {
+	if ef.cl == nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, errors.New("eth client doesn't exist").Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	buf, err := ef.rt.Read(offset, size)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+	if !gjson.ValidBytes(buf) {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, fmt.Sprintf("invalid proof query: %s", buf))
+		return int32(wasm.ResultStatusCode_ParamIllegal)
+	}
+	q := gjson.ParseBytes(buf)
+	keys := make([]string, 0)
+	for _, k := range q.Get("storageKeys").Array() {
+		keys = append(keys, k.String())
+	}
+	blockNumber := int64(-1)
+	if n := q.Get("blockNumber"); n.Exists() {
+		blockNumber = n.Int()
+	}
+
+	ret, err := ef.cl.GetProof(ef.cf, uint64(chainID), q.Get("address").String(), keys, blockNumber)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	data, err := json.Marshal(ret)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_HostInternal)
+	}
+	if err = ef.rt.Copy(data, vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// GetTokenMetadata fetches the metadata json of ERC721 or ERC1155 token by
+// `{"contractAddress":"0x...","tokenID":"1","standard":"ERC721"}`, tokenID is
+// a decimal or 0x prefixed hex string
+func (ef *ExportFuncs) GetTokenMetadata(chainID int32, payloadAddr, payloadSize int32, vmAddrPtr, vmSizePtr int32) int32 {
+	if ef.cl == nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, errors.New("eth client doesn't exist").Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	payload, err := ef.rt.Read(payloadAddr, payloadSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+	req := &struct {
+		ContractAddress string `json:"contractAddress"`
+		TokenID         string `json:"tokenID"`
+		Standard        string `json:"standard"`
+	}{}
+	if err = json.Unmarshal(payload, req); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_ParamIllegal)
+	}
+	tokenID, ok := new(big.Int).SetString(req.TokenID, 0)
+	if !ok || tokenID.Sign() < 0 {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, fmt.Sprintf("invalid token id: %s", req.TokenID))
+		return int32(wasm.ResultStatusCode_ParamIllegal)
+	}
+
+	uri, err := ef.cl.TokenURI(ef.cf, uint64(chainID), req.ContractAddress, tokenID, req.Standard)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	data, err := fetchTokenMetadata(ef.ctx, uri, tokenID, ef.ipfs, tokenMetadataTimeout)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	if err = ef.rt.Copy(data, vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// SubscribeBlocks subscribes new block headers of chain, handler is invoked
+// with __new_block__ event of each new block until the instance stopped
+func (ef *ExportFuncs) SubscribeBlocks(chainID int32, handlerAddr, handlerSize int32) int32 {
+	handler, err := ef.rt.Read(handlerAddr, handlerSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+	if ef.cl == nil || ef.dispatch == nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, "block subscription is not supported")
+		return wasm.ResultStatusCode_Failed
+	}
+
+	// the callback outlives the invocation, whose ExportFuncs is reused by
+	// the following invocations
+	sub := ef.fork(nil, nil)
+
+	h := string(handler)
+	err = ef.heads.Add(fmt.Sprintf("%d:%s", chainID, h), func(ctx context.Context) error {
+		return sub.cl.SubscribeNewHeads(ctx, sub.cf, uint64(chainID), func(header *wasm.BlockHeader) {
+			payload, _ := json.Marshal(header)
+			ctx := types.WithEventID(sub.ctx, uuid.NewString()+"_new_block")
+			if rsp := sub.dispatch(ctx, h, eventTypeNewBlock, payload); rsp.ErrMsg != "" {
+				sub.logAndPersistToDB(conflog.ErrorLevel, efSrc, rsp.ErrMsg)
+			}
+		})
+	})
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// GetChainGasPrice returns the suggested gas price json of chain
+func (ef *ExportFuncs) GetChainGasPrice(chainID int32, vmAddrPtr, vmSizePtr int32) int32 {
+	if ef.cl == nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, errors.New("eth client doesn't exist").Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	ret, err := ef.cl.GetGasPrice(ef.cf, uint64(chainID))
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	data, err := json.Marshal(ret)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_HostInternal)
+	}
+	if err = ef.rt.Copy(data, vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+func (ef *ExportFuncs) GetEnv(kAddr, kSize int32, vmAddrPtr, vmSizePtr int32) int32 {
+	if ef.env == nil {
+		return int32(wasm.ResultStatusCode_EnvKeyNotFound)
+	}
+	key, err := ef.rt.Read(kAddr, kSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+
+	val, ok := ef.env.Get(string(key))
+	if !ok {
+		return int32(wasm.ResultStatusCode_EnvKeyNotFound)
+	}
+
+	if err = ef.rt.Copy([]byte(val), vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// GetEnvSecret reads the secret of key from the secret provider configured by
+// host, eg: vault or aws ssm. unlike ws_get_env, the secrets are never
+// persisted or logged by host
+func (ef *ExportFuncs) GetEnvSecret(kAddr, kSize int32, vmAddrPtr, vmSizePtr int32) int32 {
+	if ef.secrets == nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, "secret provider is not configured")
+		return wasm.ResultStatusCode_Failed
+	}
+	key, err := ef.rt.Read(kAddr, kSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+
+	val, err := ef.secrets.Get(string(key))
+	if err != nil {
+		if errors.Is(err, confsecret.ErrSecretNotFound) {
+			return int32(wasm.ResultStatusCode_EnvKeyNotFound)
+		}
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, fmt.Sprintf("get secret %s: %v", key, err))
+		return wasm.ResultStatusCode_Failed
+	}
+
+	if err = ef.rt.Copy([]byte(val), vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// JSONPathQuery applies the gjson path to the json data and writes the raw
+// result(string, number, array or object) to vm
+func (ef *ExportFuncs) JSONPathQuery(jsonAddr, jsonSize, pathAddr, pathSize, vmAddrPtr, vmSizePtr int32) int32 {
+	data, err := ef.rt.Read(jsonAddr, jsonSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+	path, err := ef.rt.Read(pathAddr, pathSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+
+	if len(path) > maxJSONPathIterationLen && strings.Contains(string(path), "#") {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, "json path with array iteration is too long")
+		return int32(wasm.ResultStatusCode_ParamIllegal)
+	}
+	if !gjson.ValidBytes(data) {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, "invalid json data")
+		return int32(wasm.ResultStatusCode_ParamIllegal)
+	}
+
+	ret := gjson.GetBytes(data, string(path))
+	if !ret.Exists() {
+		return int32(wasm.ResultStatusCode_ResourceNotFound)
+	}
+
+	if err = ef.rt.Copy([]byte(ret.Raw), vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// GetEventCount writes the number of events processed by current project as a
+// decimal string and increases the counter
+func (ef *ExportFuncs) GetEventCount(vmAddrPtr, vmSizePtr int32) int32 {
+	n, err := ef.evc.Next()
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_HostInternal)
+	}
+
+	if err = ef.rt.Copy([]byte(strconv.FormatInt(n, 10)), vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// EmitEvent triggers an event of eventType with payload to the project
+// synchronously. nested emitting deeper than maxEmitEventDepth is rejected
+func (ef *ExportFuncs) EmitEvent(eventTypeAddr, eventTypeSize, payloadAddr, payloadSize int32) int32 {
+	if eventEmitter == nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, "event emitter not set")
+		return int32(wasm.ResultStatusCode_HostInternal)
+	}
+
+	eventType, err := ef.rt.Read(eventTypeAddr, eventTypeSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+	payload, err := ef.rt.Read(payloadAddr, payloadSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+
+	depth := ef.depth + 1
+	if depth > maxEmitEventDepth {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc,
+			fmt.Sprintf("emit event %s exceeds max depth %d", eventType, maxEmitEventDepth))
+		return int32(wasm.ResultStatusCode_RecursionLimit)
+	}
+
+	// the depth is carried by the emitted event and assigned to the
+	// invocation handling it
+	if _, err = eventEmitter(types.WithEmitDepth(ef.ctx, depth), string(eventType), payload); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+func (ef *ExportFuncs) Compress(algo, srcAddr, srcSize, vmAddrPtr, vmSizePtr int32) int32 {
+	src, err := ef.rt.Read(srcAddr, srcSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+
+	dst, err := compress(algo, src)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+
+	if err = ef.rt.Copy(dst, vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+func (ef *ExportFuncs) Decompress(algo, srcAddr, srcSize, vmAddrPtr, vmSizePtr int32) int32 {
+	src, err := ef.rt.Read(srcAddr, srcSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+
+	dst, err := decompress(algo, src, ef.rtc.MaxDecompressedBytes)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+
+	if err = ef.rt.Copy(dst, vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+func (ef *ExportFuncs) Base64Encode(srcAddr, srcSize, vmAddrPtr, vmSizePtr int32) int32 {
+	return ef.Base64EncodeVariant(base64VariantStd, srcAddr, srcSize, vmAddrPtr, vmSizePtr)
+}
+
+func (ef *ExportFuncs) Base64EncodeVariant(variant, srcAddr, srcSize, vmAddrPtr, vmSizePtr int32) int32 {
+	src, err := ef.rt.Read(srcAddr, srcSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+
+	dst, err := base64Encode(variant, src)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+
+	if err = ef.rt.Copy(dst, vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+func (ef *ExportFuncs) Base64Decode(srcAddr, srcSize, vmAddrPtr, vmSizePtr int32) int32 {
+	src, err := ef.rt.Read(srcAddr, srcSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+
+	dst, err := base64Decode(base64VariantStd, src)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+
+	if err = ef.rt.Copy(dst, vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// EncodeProtobuf marshals json object to protobuf wire format by the first
+// message defined in .proto schema
+func (ef *ExportFuncs) EncodeProtobuf(schemaAddr, schemaSize, jsonAddr, jsonSize int32, vmAddrPtr, vmSizePtr int32) int32 {
+	schema, err := ef.rt.Read(schemaAddr, schemaSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+	data, err := ef.rt.Read(jsonAddr, jsonSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+	encoded, err := encodeProtobuf(schema, data)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_ParamIllegal)
+	}
+	if err = ef.rt.Copy(encoded, vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// FormatString renders the text/template tmpl with json object values, eg:
+// tmpl `device {{.id}} is offline` and values `{"id":"d1"}`
+func (ef *ExportFuncs) FormatString(tmplAddr, tmplSize, valuesAddr, valuesSize int32, vmAddrPtr, vmSizePtr int32) int32 {
+	tmpl, err := ef.rt.Read(tmplAddr, tmplSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+	values, err := ef.rt.Read(valuesAddr, valuesSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+	out, err := formatString(string(tmpl), values, formatTimeout)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		if errors.Is(err, errTooManyFormats) {
+			return int32(wasm.ResultStatusCode_RateLimited)
+		}
+		return wasm.ResultStatusCode_Failed
+	}
+	if err = ef.rt.Copy([]byte(out), vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// EncodeHex encodes src as hex string without `0x` prefix
+func (ef *ExportFuncs) EncodeHex(srcAddr, srcSize, vmAddrPtr, vmSizePtr int32) int32 {
+	return ef.EncodeHexVariant(0, srcAddr, srcSize, vmAddrPtr, vmSizePtr)
+}
+
+// EncodeHexVariant encodes src as hex string, non-zero prefixed means with `0x`
+// prefix
+func (ef *ExportFuncs) EncodeHexVariant(prefixed, srcAddr, srcSize, vmAddrPtr, vmSizePtr int32) int32 {
+	src, err := ef.rt.Read(srcAddr, srcSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+
+	if err = ef.rt.Copy(hexEncode(src, prefixed != 0), vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// DecodeHex decodes hex string with optional `0x` prefix to bytes
+func (ef *ExportFuncs) DecodeHex(srcAddr, srcSize, vmAddrPtr, vmSizePtr int32) int32 {
+	src, err := ef.rt.Read(srcAddr, srcSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+
+	dst, err := hexDecode(src)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+
+	if err = ef.rt.Copy(dst, vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// DecodeCBOR decodes CBOR data and returns the value as json
+func (ef *ExportFuncs) DecodeCBOR(srcAddr, srcSize, vmAddrPtr, vmSizePtr int32) int32 {
+	src, err := ef.rt.Read(srcAddr, srcSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+
+	dst, err := decodeCBOR(src)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+
+	if err = ef.rt.Copy(dst, vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// DecodeMsgpack decodes MessagePack data and returns the value as json, the
+// nested depth of maps and arrays is limited to 32
+func (ef *ExportFuncs) DecodeMsgpack(srcAddr, srcSize, vmAddrPtr, vmSizePtr int32) int32 {
+	src, err := ef.rt.Read(srcAddr, srcSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+
+	dst, err := decodeMsgpack(src)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+
+	if err = ef.rt.Copy(dst, vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// EncodeMsgpack encodes json value to MessagePack data
+func (ef *ExportFuncs) EncodeMsgpack(srcAddr, srcSize, vmAddrPtr, vmSizePtr int32) int32 {
+	src, err := ef.rt.Read(srcAddr, srcSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+
+	dst, err := encodeMsgpack(src)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+
+	if err = ef.rt.Copy(dst, vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// URLParse parses raw url and writes the components as json
+func (ef *ExportFuncs) URLParse(rawAddr, rawSize, vmAddrPtr, vmSizePtr int32) int32 {
+	raw, err := ef.rt.Read(rawAddr, rawSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+
+	dst, err := urlParse(raw)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+
+	if err = ef.rt.Copy(dst, vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// URLEncode encodes the key-value pairs of json object as url query string
+func (ef *ExportFuncs) URLEncode(payloadAddr, payloadSize, vmAddrPtr, vmSizePtr int32) int32 {
+	payload, err := ef.rt.Read(payloadAddr, payloadSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+
+	dst, err := urlEncode(payload)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return wasm.ResultStatusCode_Failed
+	}
+
+	if err = ef.rt.Copy(dst, vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// readTimeLayout reads time layout from vm memory, fmtAddr 0 means RFC3339Nano
+func (ef *ExportFuncs) readTimeLayout(fmtAddr, fmtSize int32) (string, error) {
+	if fmtAddr == 0 {
+		return "", nil
+	}
+	layout, err := ef.rt.Read(fmtAddr, fmtSize)
+	if err != nil {
+		return "", err
+	}
+	return string(layout), nil
+}
+
+// TimeFormat formats unix nanoseconds by the layout of go time package
+func (ef *ExportFuncs) TimeFormat(unixNanos int64, fmtAddr, fmtSize, vmAddrPtr, vmSizePtr int32) int32 {
+	layout, err := ef.readTimeLayout(fmtAddr, fmtSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+
+	if err = ef.rt.Copy([]byte(timeFormat(unixNanos, layout)), vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// TimeParse parses time string by the layout of go time package and returns
+// unix nanoseconds as decimal string
+func (ef *ExportFuncs) TimeParse(fmtAddr, fmtSize, strAddr, strSize, vmAddrPtr, vmSizePtr int32) int32 {
+	layout, err := ef.readTimeLayout(fmtAddr, fmtSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+	value, err := ef.rt.Read(strAddr, strSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+
+	nanos, err := timeParse(layout, string(value))
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_ParamIllegal)
+	}
+
+	if err = ef.rt.Copy([]byte(strconv.FormatInt(nanos, 10)), vmAddrPtr, vmSizePtr); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataToVMFailed)
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// TimeSinceEpoch returns the nanoseconds elapsed since epochNanos directly, or
+// the current unix nanoseconds if epochNanos is 0
+func (ef *ExportFuncs) TimeSinceEpoch(epochNanos int64) int64 {
+	return time.Now().UnixNano() - epochNanos
+}
+
+// RegexMatch reports whether input matches the RE2 pattern, returns 1 if
+// matched, 0 if not and -1 if pattern failed to compile. compiled patterns are
+// cached, compilation errors are logged only and not persisted
+func (ef *ExportFuncs) RegexMatch(patternAddr, patternSize, inputAddr, inputSize int32) int32 {
+	pattern, err := ef.rt.Read(patternAddr, patternSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return -1
+	}
+	input, err := ef.rt.Read(inputAddr, inputSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return -1
+	}
+
+	re, err := regexes.Compile(string(pattern))
+	if err != nil {
+		ef.log.WithValues("@src", efSrc).Warn(errors.Wrap(err, "compile regex"))
+		return -1
+	}
+	if re.Match(input) {
+		return 1
+	}
+	return 0
+}
+
+// CheckSchema validates json data by json schema, returns ResultStatusCode_OK
+// if data is valid
+func (ef *ExportFuncs) CheckSchema(schemaAddr, schemaSize, dataAddr, dataSize int32) int32 {
+	schema, err := ef.rt.Read(schemaAddr, schemaSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+	data, err := ef.rt.Read(dataAddr, dataSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+
+	compiled, err := schemas.Compile(schema)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, errors.Wrap(err, "compile schema").Error())
+		return int32(wasm.ResultStatusCode_HostInternal)
+	}
+	if err = validateSchema(compiled, data); err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, errors.Wrap(err, "check schema").Error())
+		return wasm.ResultStatusCode_Failed
+	}
+	return int32(wasm.ResultStatusCode_OK)
+}
+
+// VerifyMerkleProof verifies the merkle-patricia trie proof in payload, returns
+// ResultStatusCode_OK if the proof is valid
+func (ef *ExportFuncs) VerifyMerkleProof(payloadAddr, payloadSize int32) int32 {
+	payload, err := ef.rt.Read(payloadAddr, payloadSize)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_TransDataFromVMFailed)
+	}
+
+	proof, err := parseMerkleProof(payload)
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_ParamIllegal)
+	}
+
+	valid, err := proof.Verify()
+	if err != nil {
+		ef.logAndPersistToDB(conflog.ErrorLevel, efSrc, err.Error())
+		return int32(wasm.ResultStatusCode_ParamIllegal)
+	}
+	if !valid {
+		return wasm.ResultStatusCode_Failed
+	}
 	return int32(wasm.ResultStatusCode_OK)
 }
 
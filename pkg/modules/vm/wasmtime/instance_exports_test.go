@@ -0,0 +1,70 @@
+package wasmtime
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/gomega"
+
+	"github.com/machinefi/w3bstream/pkg/depends/kit/sqlx"
+	"github.com/machinefi/w3bstream/pkg/enums"
+	"github.com/machinefi/w3bstream/pkg/types"
+)
+
+func TestExportFuncs_withStatementTimeout(t *testing.T) {
+	conn, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	NewWithT(t).Expect(err).To(BeNil())
+	defer conn.Close()
+
+	db := &sqlx.DB{Database: sqlx.NewDatabase("demo"), SqlExecutor: conn}
+	query := func(db sqlx.DBExecutor) error {
+		_, err := db.ExecContext(context.Background(), "DELETE FROM t_demo")
+		return err
+	}
+
+	t.Run("Configured", func(t *testing.T) {
+		ef := &ExportFuncs{ctx: types.WithWasmDBConfig(context.Background(), &types.WasmDBConfig{MaxQueryExecutionMs: 100})}
+
+		mock.ExpectBegin()
+		mock.ExpectExec("SET LOCAL statement_timeout = 100").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("DELETE FROM t_demo").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		NewWithT(t).Expect(ef.withStatementTimeout(db, query)).To(BeNil())
+		NewWithT(t).Expect(mock.ExpectationsWereMet()).To(BeNil())
+	})
+
+	t.Run("NotConfigured", func(t *testing.T) {
+		ef := &ExportFuncs{ctx: context.Background()}
+
+		mock.ExpectExec("DELETE FROM t_demo").WillReturnResult(sqlmock.NewResult(0, 1))
+		NewWithT(t).Expect(ef.withStatementTimeout(db, query)).To(BeNil())
+		NewWithT(t).Expect(mock.ExpectationsWereMet()).To(BeNil())
+	})
+}
+
+func TestPaginatePage(t *testing.T) {
+	cases := []struct {
+		name     string
+		rows     string
+		datatype enums.WasmDBDatatype
+		expect   string
+	}{
+		{"NoRows", ``, enums.WASM_DB_DATATYPE__INT64, `{"rows":[],"lastValue":null}`},
+		{"OneRow", `{"f_id":1,"f_v":"a"}`, enums.WASM_DB_DATATYPE__INT64,
+			`{"rows":[{"f_id":1,"f_v":"a"}],"lastValue":{"int64":1}}`},
+		{"Rows", `[{"f_id":1},{"f_id":30}]`, enums.WASM_DB_DATATYPE__INT64,
+			`{"rows":[{"f_id":1},{"f_id":30}],"lastValue":{"int64":30}}`},
+		{"Text", `[{"f_id":"a"},{"f_id":"b"}]`, enums.WASM_DB_DATATYPE__TEXT,
+			`{"rows":[{"f_id":"a"},{"f_id":"b"}],"lastValue":{"string":"b"}}`},
+		{"NullCursor", `[{"f_id":null}]`, enums.WASM_DB_DATATYPE__INT64,
+			`{"rows":[{"f_id":null}],"lastValue":null}`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			page, err := paginatePage([]byte(c.rows), "f_id", c.datatype)
+			NewWithT(t).Expect(err).To(BeNil())
+			NewWithT(t).Expect(string(page)).To(MatchJSON(c.expect))
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package wasmtime
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestInstance_Linked(t *testing.T) {
+	i := &Instance{ef: &ExportFuncs{}}
+
+	lk1, err := i.acquireLinked()
+	NewWithT(t).Expect(err).To(BeNil())
+	lk2, err := i.acquireLinked()
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(lk2.linker).NotTo(BeIdenticalTo(lk1.linker))
+
+	lk1.ef.traceID = "trace"
+	i.releaseLinked(lk1)
+	NewWithT(t).Expect(lk1.ef.traceID).To(BeEmpty())
+
+	lk3, err := i.acquireLinked()
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(lk3).To(BeIdenticalTo(lk1))
+}
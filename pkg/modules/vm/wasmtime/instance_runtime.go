@@ -3,6 +3,8 @@ package wasmtime
 import (
 	"context"
 	"encoding/binary"
+	"sync"
+	"time"
 
 	"github.com/bytecodealliance/wasmtime-go/v8"
 	"github.com/pkg/errors"
@@ -11,38 +13,68 @@ import (
 )
 
 var (
-	ErrNotLinked           = errors.New("not linked")
-	ErrAlreadyInstantiated = errors.New("already instantiated")
-	ErrNotInstantiated     = errors.New("not instantiated")
-	ErrFuncNotImported     = errors.New("func not imported")
-	ErrAlreadyLinked       = errors.New("already linked")
-	engine                 = wasmtime.NewEngineWithConfig(wasmtime.NewConfig())
+	ErrNotLinked              = errors.New("not linked")
+	ErrAlreadyInstantiated    = errors.New("already instantiated")
+	ErrNotInstantiated        = errors.New("not instantiated")
+	ErrFuncNotImported        = errors.New("func not imported")
+	ErrAlreadyLinked          = errors.New("already linked")
+	ErrExecutionTicksExceeded = errors.New("execution ticks exceeded")
+	engine                    = newEngine()
 )
 
+// EpochTickInterval the interval of engine epoch increased, an invocation is
+// interrupted after its epoch ticks exhausted. the ticks measure wall clock
+// time, including the time blocked in host functions
+const EpochTickInterval = 10 * time.Millisecond
+
+// unlimitedTicks the epoch deadline of runtime without ticks limitation
+const unlimitedTicks = uint64(1) << 62
+
+var startEpochTicker sync.Once
+
+func newEngine() *wasmtime.Engine {
+	cfg := wasmtime.NewConfig()
+	cfg.SetEpochInterruption(true)
+	return wasmtime.NewEngineWithConfig(cfg)
+}
+
 type (
 	Runtime struct {
 		module   *wasmtime.Module
 		linker   *wasmtime.Linker
 		store    *wasmtime.Store
 		instance *wasmtime.Instance
+		// maxTicks epoch ticks allowed per call, 0 means unlimited
+		maxTicks uint64
 	}
 )
 
-func NewRuntime() *Runtime {
-	return &Runtime{}
+// NewRuntime returns a runtime which calls are interrupted after maxTicks epoch
+// ticks, 0 means unlimited
+func NewRuntime(maxTicks uint64) *Runtime {
+	startEpochTicker.Do(func() {
+		go func() {
+			for range time.Tick(EpochTickInterval) {
+				engine.IncrementEpoch()
+			}
+		}()
+	})
+	return &Runtime{maxTicks: maxTicks}
+}
+
+func (rt *Runtime) epochDeadline() uint64 {
+	if rt.maxTicks == 0 {
+		return unlimitedTicks
+	}
+	return rt.maxTicks
 }
 
 func (rt *Runtime) Link(lk ABILinker, code []byte) error {
 	if rt.module != nil {
 		return ErrAlreadyLinked
 	}
-	linker := wasmtime.NewLinker(engine)
-	if err := lk.LinkABI(func(module, name string, fn interface{}) error {
-		return linker.FuncWrap(module, name, fn)
-	}); err != nil {
-		return err
-	}
-	if err := linker.DefineWasi(); err != nil {
+	linker, err := newLinker(lk)
+	if err != nil {
 		return err
 	}
 	rt.linker = linker
@@ -54,6 +86,28 @@ func (rt *Runtime) Link(lk ABILinker, code []byte) error {
 	return nil
 }
 
+// Fork returns a runtime which shares the compiled module with rt and is
+// instantiated by linker, it can be instantiated independently of rt
+func (rt *Runtime) Fork(linker *wasmtime.Linker) (*Runtime, error) {
+	if rt.module == nil {
+		return nil, ErrNotLinked
+	}
+	return &Runtime{module: rt.module, linker: linker, maxTicks: rt.maxTicks}, nil
+}
+
+func newLinker(lk ABILinker) (*wasmtime.Linker, error) {
+	linker := wasmtime.NewLinker(engine)
+	if err := lk.LinkABI(func(module, name string, fn interface{}) error {
+		return linker.FuncWrap(module, name, fn)
+	}); err != nil {
+		return nil, err
+	}
+	if err := linker.DefineWasi(); err != nil {
+		return nil, err
+	}
+	return linker, nil
+}
+
 func (rt *Runtime) Instantiate(ctx context.Context) error {
 	ctx, l := logr.Start(ctx, "modules.vm.wasmtime.Runtime.Instantiate")
 	defer l.End()
@@ -66,6 +120,7 @@ func (rt *Runtime) Instantiate(ctx context.Context) error {
 	}
 	store := wasmtime.NewStore(engine)
 	store.SetWasi(wasmtime.NewWasiConfig())
+	store.SetEpochDeadline(rt.epochDeadline())
 
 	instance, err := rt.linker.Instantiate(store, rt.module)
 	if err != nil {
@@ -123,7 +178,14 @@ func (rt *Runtime) Call(ctx context.Context, name string, args ...interface{}) (
 	if fn == nil {
 		return nil, ErrFuncNotImported
 	}
-	return fn.Call(rt.store, args...)
+	rt.store.SetEpochDeadline(rt.epochDeadline())
+	ret, err := fn.Call(rt.store, args...)
+	if trap, ok := err.(*wasmtime.Trap); ok {
+		if code := trap.Code(); code != nil && *code == wasmtime.Interrupt {
+			return nil, ErrExecutionTicksExceeded
+		}
+	}
+	return ret, err
 }
 
 func (rt *Runtime) Read(addr, size int32) ([]byte, error) {
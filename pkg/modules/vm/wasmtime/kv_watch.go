@@ -0,0 +1,88 @@
+package wasmtime
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// eventTypeKVChanged the event type dispatched to handler when watched key changed
+const eventTypeKVChanged = "__kv_changed__"
+
+// maxKVWatches max kv watches of an instance, each watch holds a connection of
+// kv store
+const maxKVWatches = 16
+
+var errTooManyKVWatches = errors.Errorf("kv watches exceed %d", maxKVWatches)
+
+// KVChangedEvent payload of eventTypeKVChanged, Value is nil if key deleted
+type KVChangedEvent struct {
+	Key     string  `json:"key"`
+	Value   *string `json:"value"`
+	Deleted bool    `json:"deleted"`
+}
+
+func newKVChangedEvent(key string, value []byte) *KVChangedEvent {
+	ev := &KVChangedEvent{Key: key, Deleted: value == nil}
+	if value != nil {
+		v := string(value)
+		ev.Value = &v
+	}
+	return ev
+}
+
+// kvWatches kv watches of instance keyed by watched key and handler, they are
+// canceled when instance stopped
+type kvWatches struct {
+	mtx     sync.Mutex
+	cancels map[[2]string]context.CancelFunc
+}
+
+func newKVWatches() *kvWatches {
+	return &kvWatches{cancels: make(map[[2]string]context.CancelFunc)}
+}
+
+// Add starts watching by start if key isn't watched by handler yet
+func (w *kvWatches) Add(key, handler string, start func(ctx context.Context) error) error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	k := [2]string{key, handler}
+	if _, ok := w.cancels[k]; ok {
+		return nil
+	}
+	if len(w.cancels) >= maxKVWatches {
+		return errTooManyKVWatches
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := start(ctx); err != nil {
+		cancel()
+		return err
+	}
+	w.cancels[k] = cancel
+	return nil
+}
+
+// Remove stops watching key by handler
+func (w *kvWatches) Remove(key, handler string) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	k := [2]string{key, handler}
+	if cancel, ok := w.cancels[k]; ok {
+		cancel()
+		delete(w.cancels, k)
+	}
+}
+
+// CancelAll stops all watches
+func (w *kvWatches) CancelAll() {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	for k, cancel := range w.cancels {
+		cancel()
+		delete(w.cancels, k)
+	}
+}
@@ -0,0 +1,39 @@
+package wasmtime
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestKVWatches(t *testing.T) {
+	w := newKVWatches()
+
+	started := 0
+	start := func(ctx context.Context) error {
+		started++
+		return nil
+	}
+	NewWithT(t).Expect(w.Add("key", "handler", start)).To(BeNil())
+	NewWithT(t).Expect(w.Add("key", "handler", start)).To(BeNil())
+	NewWithT(t).Expect(w.Add("key", "handler2", start)).To(BeNil())
+	NewWithT(t).Expect(started).To(Equal(2))
+
+	w.Remove("key", "handler")
+	NewWithT(t).Expect(w.Add("key", "handler", start)).To(BeNil())
+	NewWithT(t).Expect(started).To(Equal(3))
+
+	w.CancelAll()
+	for i := 0; i < maxKVWatches; i++ {
+		NewWithT(t).Expect(w.Add("key", fmt.Sprint(i), start)).To(BeNil())
+	}
+	NewWithT(t).Expect(w.Add("key", "handler", start)).To(Equal(errTooManyKVWatches))
+	w.CancelAll()
+	NewWithT(t).Expect(w.Add("key", "handler", start)).To(BeNil())
+
+	ev := newKVChangedEvent("key", nil)
+	NewWithT(t).Expect(ev.Deleted).To(BeTrue())
+	NewWithT(t).Expect(ev.Value).To(BeNil())
+}
@@ -0,0 +1,60 @@
+package wasmtime
+
+import (
+	"container/list"
+	"sync"
+)
+
+// lruCache the cache holds size entries at most, the least recently used
+// entry is evicted when it is full
+type lruCache[K comparable, V any] struct {
+	mtx   sync.Mutex
+	size  int
+	lst   *list.List
+	items map[K]*list.Element
+}
+
+type lruEntry[K comparable, V any] struct {
+	key K
+	val V
+}
+
+func newLRUCache[K comparable, V any](size int) *lruCache[K, V] {
+	return &lruCache[K, V]{
+		size:  size,
+		lst:   list.New(),
+		items: make(map[K]*list.Element),
+	}
+}
+
+// Load returns the value of key and marks it as recently used
+func (c *lruCache[K, V]) Load(key K) (v V, ok bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return v, false
+	}
+	c.lst.MoveToFront(elem)
+	return elem.Value.(*lruEntry[K, V]).val, true
+}
+
+// Store caches the value of key if key isn't cached yet and evicts the least
+// recently used entry when the cache is full. it returns the value cached
+func (c *lruCache[K, V]) Store(key K, val V) V {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		c.lst.MoveToFront(elem)
+		return elem.Value.(*lruEntry[K, V]).val
+	}
+	c.items[key] = c.lst.PushFront(&lruEntry[K, V]{key: key, val: val})
+	if c.lst.Len() > c.size {
+		oldest := c.lst.Back()
+		c.lst.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry[K, V]).key)
+	}
+	return val
+}
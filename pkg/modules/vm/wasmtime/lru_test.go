@@ -0,0 +1,29 @@
+package wasmtime
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestLRUCache(t *testing.T) {
+	c := newLRUCache[string, int](2)
+
+	NewWithT(t).Expect(c.Store("a", 1)).To(Equal(1))
+	NewWithT(t).Expect(c.Store("b", 2)).To(Equal(2))
+	// stored value is kept
+	NewWithT(t).Expect(c.Store("a", 10)).To(Equal(1))
+
+	v, ok := c.Load("a")
+	NewWithT(t).Expect(ok).To(BeTrue())
+	NewWithT(t).Expect(v).To(Equal(1))
+
+	// b is the least recently used
+	c.Store("c", 3)
+	NewWithT(t).Expect(c.lst.Len()).To(Equal(2))
+	_, ok = c.Load("b")
+	NewWithT(t).Expect(ok).To(BeFalse())
+	v, ok = c.Load("c")
+	NewWithT(t).Expect(ok).To(BeTrue())
+	NewWithT(t).Expect(v).To(Equal(3))
+}
@@ -0,0 +1,61 @@
+package wasmtime
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/pkg/errors"
+)
+
+// MerkleProof merkle-patricia trie proof of key-value, all fields are hex encoded
+type MerkleProof struct {
+	Root  string   `json:"root"`
+	Proof []string `json:"proof"`
+	Key   string   `json:"key"`
+	Value string   `json:"value"`
+}
+
+func parseMerkleProof(payload []byte) (*MerkleProof, error) {
+	p := &MerkleProof{}
+	if err := json.Unmarshal(payload, p); err != nil {
+		return nil, err
+	}
+	if p.Root == "" || p.Key == "" || len(p.Proof) == 0 {
+		return nil, errors.New("root, key and proof are required")
+	}
+	return p, nil
+}
+
+// Verify checks if the proof proves key-value in the trie of root
+func (p *MerkleProof) Verify() (bool, error) {
+	key, err := hexutil.Decode(p.Key)
+	if err != nil {
+		return false, errors.Wrap(err, "key")
+	}
+	value, err := hexutil.Decode(p.Value)
+	if err != nil {
+		return false, errors.Wrap(err, "value")
+	}
+
+	db := memorydb.New()
+	for i, n := range p.Proof {
+		node, err := hexutil.Decode(n)
+		if err != nil {
+			return false, errors.Wrapf(err, "proof[%d]", i)
+		}
+		if err = db.Put(crypto.Keccak256(node), node); err != nil {
+			return false, err
+		}
+	}
+
+	proved, err := trie.VerifyProof(common.HexToHash(p.Root), key, db)
+	if err != nil {
+		return false, nil
+	}
+	return bytes.Equal(proved, value), nil
+}
@@ -0,0 +1,51 @@
+package wasmtime
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/trie"
+	. "github.com/onsi/gomega"
+)
+
+type proofList []string
+
+func (l *proofList) Put(_ []byte, value []byte) error {
+	*l = append(*l, hexutil.Encode(value))
+	return nil
+}
+
+func (l *proofList) Delete([]byte) error { return nil }
+
+func TestMerkleProof(t *testing.T) {
+	tr := trie.NewEmpty(trie.NewDatabase(rawdb.NewMemoryDatabase()))
+	tr.Update([]byte("device_1"), []byte("online"))
+	tr.Update([]byte("device_2"), []byte("offline"))
+	tr.Update([]byte("device_3"), []byte("online"))
+
+	proof := proofList{}
+	NewWithT(t).Expect(tr.Prove([]byte("device_2"), 0, &proof)).To(BeNil())
+
+	p, err := parseMerkleProof([]byte(`{"root":"` + tr.Hash().Hex() + `"}`))
+	NewWithT(t).Expect(err).NotTo(BeNil())
+
+	p = &MerkleProof{
+		Root:  tr.Hash().Hex(),
+		Proof: proof,
+		Key:   hexutil.Encode([]byte("device_2")),
+		Value: hexutil.Encode([]byte("offline")),
+	}
+	valid, err := p.Verify()
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(valid).To(BeTrue())
+
+	p.Value = hexutil.Encode([]byte("online"))
+	valid, err = p.Verify()
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(valid).To(BeFalse())
+
+	p.Key = "not hex"
+	_, err = p.Verify()
+	NewWithT(t).Expect(err).NotTo(BeNil())
+}
@@ -0,0 +1,149 @@
+package wasmtime
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/vmihailenco/msgpack/v5"
+	"github.com/vmihailenco/msgpack/v5/msgpcode"
+)
+
+// maxMsgpackDepth max nested depth of maps and arrays of msgpack object
+const maxMsgpackDepth = 32
+
+var errMsgpackTooDeep = errors.Errorf("msgpack object exceeds max depth %d", maxMsgpackDepth)
+
+// decodeMsgpack decodes a msgpack object to json. map keys are formatted as
+// strings and bin values are encoded as base64 strings
+func decodeMsgpack(data []byte) ([]byte, error) {
+	r := bytes.NewReader(data)
+	v, err := decodeMsgpackValue(msgpack.NewDecoder(r), 0)
+	if err != nil {
+		return nil, errors.Wrap(err, "decode msgpack")
+	}
+	if r.Len() > 0 {
+		return nil, errors.Errorf("decode msgpack: %d trailing bytes", r.Len())
+	}
+	return json.Marshal(v)
+}
+
+func decodeMsgpackValue(d *msgpack.Decoder, depth int) (interface{}, error) {
+	c, err := d.PeekCode()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case msgpcode.IsFixedMap(c) || c == msgpcode.Map16 || c == msgpcode.Map32:
+		if depth >= maxMsgpackDepth {
+			return nil, errMsgpackTooDeep
+		}
+		n, err := d.DecodeMapLen()
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		m := make(map[string]interface{}) // n is untrusted, not preallocated
+		for i := 0; i < n; i++ {
+			k, err := decodeMsgpackValue(d, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			v, err := decodeMsgpackValue(d, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			if s, ok := k.(string); ok {
+				m[s] = v
+			} else {
+				m[fmt.Sprint(k)] = v
+			}
+		}
+		return m, nil
+	case msgpcode.IsFixedArray(c) || c == msgpcode.Array16 || c == msgpcode.Array32:
+		if depth >= maxMsgpackDepth {
+			return nil, errMsgpackTooDeep
+		}
+		n, err := d.DecodeArrayLen()
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		vs := make([]interface{}, 0)
+		for i := 0; i < n; i++ {
+			v, err := decodeMsgpackValue(d, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			vs = append(vs, v)
+		}
+		return vs, nil
+	case msgpcode.IsBin(c):
+		return d.DecodeBytes()
+	default:
+		return d.DecodeInterfaceLoose()
+	}
+}
+
+// encodeMsgpack encodes a json value to msgpack, integers are encoded as int
+// and other numbers as float64
+func encodeMsgpack(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, errors.Wrap(err, "parse json")
+	}
+	if dec.More() {
+		return nil, errors.New("parse json: trailing data")
+	}
+	v, err := msgpackValueFromJSON(v, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	enc := msgpack.NewEncoder(buf)
+	enc.SetSortMapKeys(true)
+	if err = enc.Encode(v); err != nil {
+		return nil, errors.Wrap(err, "encode msgpack")
+	}
+	return buf.Bytes(), nil
+}
+
+func msgpackValueFromJSON(v interface{}, depth int) (interface{}, error) {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		if depth >= maxMsgpackDepth {
+			return nil, errMsgpackTooDeep
+		}
+		for k, sub := range x {
+			sub, err := msgpackValueFromJSON(sub, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			x[k] = sub
+		}
+		return x, nil
+	case []interface{}:
+		if depth >= maxMsgpackDepth {
+			return nil, errMsgpackTooDeep
+		}
+		for i, sub := range x {
+			sub, err := msgpackValueFromJSON(sub, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			x[i] = sub
+		}
+		return x, nil
+	case json.Number:
+		if i, err := x.Int64(); err == nil {
+			return i, nil
+		}
+		return x.Float64()
+	default:
+		return v, nil
+	}
+}
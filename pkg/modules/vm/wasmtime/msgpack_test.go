@@ -0,0 +1,54 @@
+package wasmtime
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestMsgpack(t *testing.T) {
+	t.Run("RoundTrip", func(t *testing.T) {
+		src := `{"id":1,"temp":21.5,"tags":["a","b"],"ok":true,"nil":null,"nested":{"v":-3}}`
+		packed, err := encodeMsgpack([]byte(src))
+		NewWithT(t).Expect(err).To(BeNil())
+
+		data, err := decodeMsgpack(packed)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(data).To(MatchJSON(src))
+	})
+
+	t.Run("NonStringKeyAndBin", func(t *testing.T) {
+		// {1: bin(0x01 0x02)}
+		data, err := decodeMsgpack([]byte{0x81, 0x01, 0xc4, 0x02, 0x01, 0x02})
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(data).To(MatchJSON(`{"1":"AQI="}`))
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		_, err := decodeMsgpack([]byte{0x92, 0x01})
+		NewWithT(t).Expect(err).NotTo(BeNil())
+
+		_, err = decodeMsgpack([]byte{0x01, 0x02})
+		NewWithT(t).Expect(err).NotTo(BeNil())
+
+		_, err = encodeMsgpack([]byte(`{"a":`))
+		NewWithT(t).Expect(err).NotTo(BeNil())
+	})
+
+	t.Run("TooDeep", func(t *testing.T) {
+		deep := make([]byte, maxMsgpackDepth+1)
+		for i := range deep {
+			deep[i] = 0x91 // fixarray of 1
+		}
+		_, err := decodeMsgpack(append(deep, 0x01))
+		NewWithT(t).Expect(err).NotTo(BeNil())
+
+		_, err = decodeMsgpack(append(deep[1:], 0x01))
+		NewWithT(t).Expect(err).To(BeNil())
+
+		src := strings.Repeat("[", maxMsgpackDepth+1) + strings.Repeat("]", maxMsgpackDepth+1)
+		_, err = encodeMsgpack([]byte(src))
+		NewWithT(t).Expect(err).NotTo(BeNil())
+	})
+}
@@ -0,0 +1,80 @@
+package wasmtime
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var errForbiddenAddr = errors.New("forbidden address")
+
+// cgnat shared address space(RFC 6598), which is not public either
+var cgnat = &net.IPNet{IP: net.IP{100, 64, 0, 0}, Mask: net.CIDRMask(10, 32)}
+
+// publicIP reports whether ip is a public unicast address. loopback, private,
+// link-local(eg: cloud metadata service), unspecified and multicast addresses
+// are rejected to prevent wasm from reaching internal endpoints through host
+func publicIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil && ip4[0] == 0 {
+		return false // 0.0.0.0/8 "this network"
+	}
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !cgnat.Contains(ip)
+}
+
+// guardedDialer dials the address only if all ips resolved are allowed. it
+// dials the checked ip instead of the host name, so the dns answer cannot be
+// changed between checking and dialing
+type guardedDialer struct {
+	net.Dialer
+	allow func(net.IP) bool
+}
+
+func (d *guardedDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	if len(ips) == 0 {
+		return nil, errors.Errorf("no address of %s", host)
+	}
+	for _, ip := range ips {
+		if !d.allow(ip.IP) {
+			return nil, errors.Wrapf(errForbiddenAddr, "%s resolved to %s", host, ip.IP)
+		}
+	}
+	return d.Dialer.DialContext(ctx, network, net.JoinHostPort(ips[0].IP.String(), port))
+}
+
+// maxRedirects the max redirects followed by guarded http client
+const maxRedirects = 3
+
+// newGuardedHTTPClient creates http client connects to the addresses allowed
+// only, which is checked on every connection including the redirected ones.
+// proxy from environment is not used, it would bypass the check
+func newGuardedHTTPClient(allow func(net.IP) bool) *http.Client {
+	d := &guardedDialer{Dialer: net.Dialer{Timeout: 5 * time.Second}, allow: allow}
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext:         d.DialContext,
+			TLSHandshakeTimeout: 5 * time.Second,
+			MaxIdleConns:        16,
+			IdleConnTimeout:     30 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return errors.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return errors.Errorf("redirect to unsupported scheme: %s", req.URL.Scheme)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,51 @@
+package wasmtime
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestPublicIP(t *testing.T) {
+	for _, s := range []string{
+		"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254",
+		"100.64.0.1", "0.0.0.0", "0.1.2.3", "224.0.0.1", "255.255.255.255",
+		"::1", "::", "fe80::1", "fd00::1", "::ffff:127.0.0.1",
+	} {
+		NewWithT(t).Expect(publicIP(net.ParseIP(s))).To(BeFalse(), s)
+	}
+	for _, s := range []string{"8.8.8.8", "1.1.1.1", "2001:4860:4860::8888"} {
+		NewWithT(t).Expect(publicIP(net.ParseIP(s))).To(BeTrue(), s)
+	}
+}
+
+func TestGuardedHTTPClient(t *testing.T) {
+	internal := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_, _ = rw.Write([]byte("secret"))
+	}))
+	l, err := net.Listen("tcp", "127.0.0.2:0")
+	if err != nil {
+		t.Skip("127.0.0.2 is not available")
+	}
+	internal.Listener = l
+	internal.Start()
+	defer internal.Close()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		http.Redirect(rw, r, internal.URL, http.StatusFound)
+	}))
+	defer srv.Close()
+
+	// only 127.0.0.1 is treated as allowed, the redirected target is not
+	cli := newGuardedHTTPClient(func(ip net.IP) bool { return ip.Equal(net.IPv4(127, 0, 0, 1)) })
+
+	_, err = cli.Get(internal.URL)
+	NewWithT(t).Expect(errors.Is(err, errForbiddenAddr)).To(BeTrue())
+
+	_, err = cli.Get(srv.URL)
+	NewWithT(t).Expect(errors.Is(err, errForbiddenAddr)).To(BeTrue())
+}
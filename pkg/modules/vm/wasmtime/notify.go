@@ -0,0 +1,126 @@
+package wasmtime
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// slackMessageInterval the minimal interval of sending message to the same channel
+var slackMessageInterval = 5 * time.Second
+
+// maxSlackLimiterEntries the max channels tracked by slack limiter
+const maxSlackLimiterEntries = 4096
+
+// slackWebhookHost the host of slack incoming webhooks
+const slackWebhookHost = "hooks.slack.com"
+
+// slackClient posts messages to slack incoming webhooks
+var slackClient = func() *http.Client {
+	c := newGuardedHTTPClient(publicIP)
+	c.Timeout = 10 * time.Second
+	return c
+}()
+
+// SlackMessage slack incoming webhook message
+type SlackMessage struct {
+	Text        string            `json:"text"`
+	Channel     string            `json:"channel,omitempty"`
+	Attachments []json.RawMessage `json:"attachments,omitempty"`
+}
+
+func parseSlackMessage(payload []byte) (*SlackMessage, error) {
+	msg := &SlackMessage{}
+	if err := json.Unmarshal(payload, msg); err != nil {
+		return nil, err
+	}
+	if msg.Text == "" {
+		return nil, errors.New("text is required")
+	}
+	return msg, nil
+}
+
+// validSlackWebhook checks if webhook is a https slack incoming webhook
+func validSlackWebhook(webhook string) error {
+	u, err := url.Parse(webhook)
+	if err != nil {
+		return errors.Wrap(err, "parse slack webhook")
+	}
+	if u.Scheme != "https" || u.Hostname() != slackWebhookHost || u.Port() != "" {
+		return errors.Errorf("slack webhook should be https://%s/...", slackWebhookHost)
+	}
+	return nil
+}
+
+// postSlackMessage posts msg to slack incoming webhook
+func postSlackMessage(ctx context.Context, webhook string, msg *SlackMessage) error {
+	if err := validSlackWebhook(webhook); err != nil {
+		return err
+	}
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	rsp, err := slackClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(io.LimitReader(rsp.Body, 256))
+		return errors.Errorf("slack webhook responded %d: %s", rsp.StatusCode, data)
+	}
+	return nil
+}
+
+// channelLimiter limits message sending frequency of each channel of project,
+// it tracks max channels at most
+type channelLimiter struct {
+	mtx  sync.Mutex
+	max  int
+	last map[[2]string]time.Time
+}
+
+func newChannelLimiter(max int) *channelLimiter {
+	return &channelLimiter{max: max, last: make(map[[2]string]time.Time)}
+}
+
+var slackLimiter = newChannelLimiter(maxSlackLimiterEntries)
+
+// Allow reports if message to channel of project can be sent at now, and
+// records the sending time if allowed. the expired records are pruned when the
+// limiter is full, and the message is refused if it is still full
+func (l *channelLimiter) Allow(project, channel string, now time.Time) bool {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	k := [2]string{project, channel}
+	last, ok := l.last[k]
+	if ok && now.Sub(last) < slackMessageInterval {
+		return false
+	}
+	if !ok && len(l.last) >= l.max {
+		for key, at := range l.last {
+			if now.Sub(at) >= slackMessageInterval {
+				delete(l.last, key)
+			}
+		}
+		if len(l.last) >= l.max {
+			return false
+		}
+	}
+	l.last[k] = now
+	return true
+}
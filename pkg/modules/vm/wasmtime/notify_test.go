@@ -0,0 +1,45 @@
+package wasmtime
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestSlackMessage(t *testing.T) {
+	msg, err := parseSlackMessage([]byte(`{"text":"device offline","channel":"#alert","attachments":[{"color":"danger"}]}`))
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(msg.Channel).To(Equal("#alert"))
+	NewWithT(t).Expect(msg.Attachments).To(HaveLen(1))
+
+	_, err = parseSlackMessage([]byte(`{"channel":"#alert"}`))
+	NewWithT(t).Expect(err).NotTo(BeNil())
+
+	l := newChannelLimiter(3)
+	now := time.Now()
+	NewWithT(t).Expect(l.Allow("prj", "#alert", now)).To(BeTrue())
+	NewWithT(t).Expect(l.Allow("prj", "#alert", now.Add(time.Second))).To(BeFalse())
+	NewWithT(t).Expect(l.Allow("prj", "#other", now.Add(time.Second))).To(BeTrue())
+	NewWithT(t).Expect(l.Allow("prj2", "#alert", now.Add(time.Second))).To(BeTrue())
+	NewWithT(t).Expect(l.Allow("prj", "#alert", now.Add(slackMessageInterval))).To(BeTrue())
+
+	// full of records not expired
+	NewWithT(t).Expect(l.Allow("prj3", "#alert", now.Add(2*time.Second))).To(BeFalse())
+	// expired records are pruned
+	NewWithT(t).Expect(l.Allow("prj3", "#alert", now.Add(slackMessageInterval+2*time.Second))).To(BeTrue())
+	NewWithT(t).Expect(len(l.last)).To(BeNumerically("<=", 3))
+}
+
+func TestValidSlackWebhook(t *testing.T) {
+	NewWithT(t).Expect(validSlackWebhook("https://hooks.slack.com/services/T0/B0/x")).To(BeNil())
+	for _, u := range []string{
+		"http://hooks.slack.com/services/T0/B0/x",
+		"https://hooks.slack.com:8443/services/T0/B0/x",
+		"https://hooks.slack.com.evil.io/services",
+		"https://127.0.0.1/services",
+		"://",
+	} {
+		NewWithT(t).Expect(validSlackWebhook(u)).NotTo(BeNil(), u)
+	}
+}
@@ -0,0 +1,57 @@
+package wasmtime
+
+import (
+	"crypto/sha256"
+
+	"github.com/jhump/protoreflect/desc/protoparse"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// protoCacheSize the max number of parsed message descriptors cached
+const protoCacheSize = 128
+
+// protoDescriptors LRU cache of parsed message descriptors keyed by sha256 of
+// .proto schema
+var protoDescriptors = newLRUCache[[sha256.Size]byte, protoreflect.MessageDescriptor](protoCacheSize)
+
+// protoMessageDescriptor returns the descriptor of the first message defined
+// in .proto schema, the parsed descriptor is cached by schema hash
+func protoMessageDescriptor(schema []byte) (protoreflect.MessageDescriptor, error) {
+	key := sha256.Sum256(schema)
+	if md, ok := protoDescriptors.Load(key); ok {
+		return md, nil
+	}
+
+	const filename = "schema.proto"
+	p := protoparse.Parser{
+		Accessor: protoparse.FileContentsFromMap(map[string]string{filename: string(schema)}),
+	}
+	fds, err := p.ParseFiles(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse proto schema")
+	}
+	msgs := fds[0].UnwrapFile().Messages()
+	if msgs.Len() == 0 {
+		return nil, errors.New("no message defined in proto schema")
+	}
+	md := msgs.Get(0)
+	return protoDescriptors.Store(key, md), nil
+}
+
+// encodeProtobuf maps json object data onto the first message of .proto
+// schema and marshals it to protobuf wire format
+func encodeProtobuf(schema, data []byte) ([]byte, error) {
+	md, err := protoMessageDescriptor(schema)
+	if err != nil {
+		return nil, err
+	}
+	msg := dynamicpb.NewMessage(md)
+	if err = protojson.Unmarshal(data, msg); err != nil {
+		return nil, errors.Wrap(err, "unmarshal json to message")
+	}
+	return proto.Marshal(msg)
+}
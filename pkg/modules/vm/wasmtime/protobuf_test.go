@@ -0,0 +1,35 @@
+package wasmtime
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func TestEncodeProtobuf(t *testing.T) {
+	schema := []byte(`syntax = "proto3";
+message Reading {
+  string device_id = 1;
+  double temperature = 2;
+  repeated int32 samples = 3;
+}`)
+
+	data, err := encodeProtobuf(schema, []byte(`{"deviceId":"d1","temperature":23.5,"samples":[1,2]}`))
+	NewWithT(t).Expect(err).To(BeNil())
+
+	md, err := protoMessageDescriptor(schema)
+	NewWithT(t).Expect(err).To(BeNil())
+	msg := dynamicpb.NewMessage(md)
+	NewWithT(t).Expect(proto.Unmarshal(data, msg)).To(BeNil())
+	NewWithT(t).Expect(msg.Get(md.Fields().ByName("device_id")).String()).To(Equal("d1"))
+	NewWithT(t).Expect(msg.Get(md.Fields().ByName("temperature")).Float()).To(Equal(23.5))
+	NewWithT(t).Expect(msg.Get(md.Fields().ByName("samples")).List().Len()).To(Equal(2))
+
+	_, err = encodeProtobuf(schema, []byte(`{"unknown":1}`))
+	NewWithT(t).Expect(err).NotTo(BeNil())
+
+	_, err = encodeProtobuf([]byte(`syntax = "proto3"; message {`), []byte(`{}`))
+	NewWithT(t).Expect(err).NotTo(BeNil())
+}
@@ -0,0 +1,90 @@
+package wasmtime
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// maxPubSubSubscriptions max pubsub channels subscribed by an instance, each
+// subscription holds a connection of kv store
+const maxPubSubSubscriptions = 16
+
+// eventTypePubSubMessage the event type dispatched to handler when message
+// received from subscribed channel
+const eventTypePubSubMessage = "__pubsub_message__"
+
+// PubSubMessageEvent payload of eventTypePubSubMessage
+type PubSubMessageEvent struct {
+	Channel string `json:"channel"`
+	Message string `json:"message"`
+}
+
+// eventTypeNewBlock the event type dispatched to handler when new block header
+// received from subscribed chain
+const eventTypeNewBlock = "__new_block__"
+
+// eventTypeDBChange the event type dispatched to handler when row of subscribed
+// sql table changed, the payload is sql_util.TableChange
+const eventTypeDBChange = "__db_change__"
+
+// subscriptions subscriptions of instance, eg: pubsub channels and chain
+// blocks, they are canceled when unsubscribed or instance stopped
+type subscriptions struct {
+	mtx     sync.Mutex
+	max     int
+	cancels map[string]context.CancelFunc
+}
+
+// newSubscriptions creates subscriptions limited to max, max not positive
+// means unlimited
+func newSubscriptions(max int) *subscriptions {
+	return &subscriptions{max: max, cancels: make(map[string]context.CancelFunc)}
+}
+
+// Add starts subscribing by start if channel isn't subscribed yet, channel is
+// the key of subscription
+func (s *subscriptions) Add(channel string, start func(ctx context.Context) error) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if _, ok := s.cancels[channel]; ok {
+		return nil
+	}
+	if s.max > 0 && len(s.cancels) >= s.max {
+		return errors.Errorf("subscriptions exceed %d", s.max)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := start(ctx); err != nil {
+		cancel()
+		return err
+	}
+	s.cancels[channel] = cancel
+	return nil
+}
+
+// Remove stops subscribing channel, returns false if channel isn't subscribed
+func (s *subscriptions) Remove(channel string) bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	cancel, ok := s.cancels[channel]
+	if !ok {
+		return false
+	}
+	cancel()
+	delete(s.cancels, channel)
+	return true
+}
+
+// CancelAll stops all subscriptions
+func (s *subscriptions) CancelAll() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	for ch, cancel := range s.cancels {
+		cancel()
+		delete(s.cancels, ch)
+	}
+}
@@ -0,0 +1,28 @@
+package wasmtime
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestSubscriptions(t *testing.T) {
+	s := newSubscriptions(2)
+
+	var ctx context.Context
+	start := func(c context.Context) error { ctx = c; return nil }
+
+	NewWithT(t).Expect(s.Add("ch", start)).To(BeNil())
+	NewWithT(t).Expect(s.Remove("other")).To(BeFalse())
+	NewWithT(t).Expect(s.Remove("ch")).To(BeTrue())
+	NewWithT(t).Expect(ctx.Err()).To(Equal(context.Canceled))
+	NewWithT(t).Expect(s.Remove("ch")).To(BeFalse())
+
+	NewWithT(t).Expect(s.Add("ch", start)).To(BeNil())
+	NewWithT(t).Expect(s.Add("ch", start)).To(BeNil())
+	NewWithT(t).Expect(s.Add("ch2", start)).To(BeNil())
+	NewWithT(t).Expect(s.Add("ch3", start)).NotTo(BeNil())
+	s.CancelAll()
+	NewWithT(t).Expect(ctx.Err()).To(Equal(context.Canceled))
+}
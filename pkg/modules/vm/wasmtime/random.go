@@ -0,0 +1,38 @@
+package wasmtime
+
+import (
+	crand "crypto/rand"
+	"math/big"
+
+	"github.com/pkg/errors"
+)
+
+// random algorithms supported by ws_get_random_int
+const (
+	randAlgoFast   int32 = iota // math/rand, for performance-sensitive uses
+	randAlgoSecure              // crypto/rand, for security-sensitive uses
+)
+
+// randomInt returns a uniform random integer in [minVal, maxVal]
+func randomInt(algo, minVal, maxVal int32) (int32, error) {
+	if minVal > maxVal {
+		return 0, errors.Errorf("invalid range: [%d, %d]", minVal, maxVal)
+	}
+	if minVal == maxVal {
+		return minVal, nil
+	}
+
+	n := int64(maxVal) - int64(minVal) + 1
+	switch algo {
+	case randAlgoFast:
+		return int32(int64(minVal) + _rand.Int63n(n)), nil
+	case randAlgoSecure:
+		v, err := crand.Int(crand.Reader, big.NewInt(n))
+		if err != nil {
+			return 0, err
+		}
+		return int32(int64(minVal) + v.Int64()), nil
+	default:
+		return 0, errors.Errorf("unsupported random algorithm: %d", algo)
+	}
+}
@@ -0,0 +1,32 @@
+package wasmtime
+
+import (
+	"regexp"
+)
+
+// regexCacheSize the max number of compiled regex patterns cached
+const regexCacheSize = 256
+
+// regexCache LRU cache of compiled RE2 patterns keyed by pattern
+type regexCache struct {
+	*lruCache[string, *regexp.Regexp]
+}
+
+func newRegexCache(size int) *regexCache {
+	return &regexCache{newLRUCache[string, *regexp.Regexp](size)}
+}
+
+var regexes = newRegexCache(regexCacheSize)
+
+// Compile returns the compiled pattern from cache or compiles and caches it,
+// the patterns failed to compile are not cached
+func (c *regexCache) Compile(pattern string) (*regexp.Regexp, error) {
+	if re, ok := c.Load(pattern); ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return c.Store(pattern, re), nil
+}
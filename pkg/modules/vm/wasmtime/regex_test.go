@@ -0,0 +1,30 @@
+package wasmtime
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestRegexCache(t *testing.T) {
+	c := newRegexCache(2)
+
+	re, err := c.Compile(`^dev-\d+$`)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(re.MatchString("dev-42")).To(BeTrue())
+	NewWithT(t).Expect(re.MatchString("dev-x")).To(BeFalse())
+
+	cached, err := c.Compile(`^dev-\d+$`)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(cached).To(BeIdenticalTo(re))
+
+	_, err = c.Compile(`(a`)
+	NewWithT(t).Expect(err).NotTo(BeNil())
+	NewWithT(t).Expect(c.lst.Len()).To(Equal(1))
+
+	_, _ = c.Compile(`a`)
+	_, _ = c.Compile(`b`)
+	NewWithT(t).Expect(c.lst.Len()).To(Equal(2))
+	_, ok := c.items[`^dev-\d+$`]
+	NewWithT(t).Expect(ok).To(BeFalse())
+}
@@ -0,0 +1,82 @@
+package wasmtime
+
+import (
+	"crypto/sha256"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// schemaCacheSize the max number of compiled json schemas cached
+const schemaCacheSize = 128
+
+// schemaCache LRU cache of compiled json schemas keyed by sha256 of schema
+type schemaCache struct {
+	*lruCache[[sha256.Size]byte, *gojsonschema.Schema]
+}
+
+func newSchemaCache(size int) *schemaCache {
+	return &schemaCache{newLRUCache[[sha256.Size]byte, *gojsonschema.Schema](size)}
+}
+
+var schemas = newSchemaCache(schemaCacheSize)
+
+// Compile returns the compiled schema from cache or compiles and caches it
+func (c *schemaCache) Compile(schema []byte) (*gojsonschema.Schema, error) {
+	key := sha256.Sum256(schema)
+	if compiled, ok := c.Load(key); ok {
+		return compiled, nil
+	}
+	compiled, err := gojsonschema.NewSchema(localSchemaLoader{gojsonschema.NewBytesLoader(schema)})
+	if err != nil {
+		return nil, err
+	}
+	return c.Store(key, compiled), nil
+}
+
+// localSchemaLoader loads schema from bytes and refuses to load the documents
+// referred by `$ref` from file or network, only the references inside schema
+// such as `#/definitions/x` are resolved
+type localSchemaLoader struct {
+	gojsonschema.JSONLoader
+}
+
+func (localSchemaLoader) LoaderFactory() gojsonschema.JSONLoaderFactory {
+	return refusedSchemaLoaderFactory{}
+}
+
+type refusedSchemaLoaderFactory struct{}
+
+func (refusedSchemaLoaderFactory) New(source string) gojsonschema.JSONLoader {
+	return refusedSchemaLoader{gojsonschema.NewReferenceLoader(source)}
+}
+
+type refusedSchemaLoader struct {
+	gojsonschema.JSONLoader
+}
+
+func (l refusedSchemaLoader) LoadJSON() (interface{}, error) {
+	return nil, errors.Errorf("external schema reference is not allowed: %v", l.JsonSource())
+}
+
+func (refusedSchemaLoader) LoaderFactory() gojsonschema.JSONLoaderFactory {
+	return refusedSchemaLoaderFactory{}
+}
+
+// validateSchema validates data by schema, the error of invalid data lists
+// all the violations
+func validateSchema(schema *gojsonschema.Schema, data []byte) error {
+	result, err := schema.Validate(gojsonschema.NewBytesLoader(data))
+	if err != nil {
+		return err
+	}
+	if result.Valid() {
+		return nil
+	}
+	msgs := make([]string, 0, len(result.Errors()))
+	for _, e := range result.Errors() {
+		msgs = append(msgs, e.String())
+	}
+	return errors.New(strings.Join(msgs, "; "))
+}
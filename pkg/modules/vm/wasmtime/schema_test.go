@@ -0,0 +1,58 @@
+package wasmtime
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestSchemaCache(t *testing.T) {
+	c := newSchemaCache(2)
+
+	schema := []byte(`{
+		"type": "object",
+		"properties": {"temperature": {"type": "number", "maximum": 100}},
+		"required": ["temperature"]
+	}`)
+	compiled, err := c.Compile(schema)
+	NewWithT(t).Expect(err).To(BeNil())
+
+	cached, err := c.Compile(schema)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(cached).To(BeIdenticalTo(compiled))
+
+	NewWithT(t).Expect(validateSchema(compiled, []byte(`{"temperature": 36.5}`))).To(BeNil())
+	NewWithT(t).Expect(validateSchema(compiled, []byte(`{"temperature": 120}`))).NotTo(BeNil())
+	NewWithT(t).Expect(validateSchema(compiled, []byte(`{}`))).NotTo(BeNil())
+
+	_, err = c.Compile([]byte(`{"type": 1}`))
+	NewWithT(t).Expect(err).NotTo(BeNil())
+
+	_, _ = c.Compile([]byte(`{"type": "string"}`))
+	_, _ = c.Compile([]byte(`{"type": "number"}`))
+	NewWithT(t).Expect(c.lst.Len()).To(Equal(2))
+	_, ok := c.items[sha256.Sum256(schema)]
+	NewWithT(t).Expect(ok).To(BeFalse())
+}
+
+func TestSchemaCache_ExternalReference(t *testing.T) {
+	c := newSchemaCache(2)
+
+	compiled, err := c.Compile([]byte(`{
+		"definitions": {"celsius": {"type": "number", "maximum": 100}},
+		"properties": {"temperature": {"$ref": "#/definitions/celsius"}}
+	}`))
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(validateSchema(compiled, []byte(`{"temperature": 120}`))).NotTo(BeNil())
+
+	for _, ref := range []string{
+		"http://127.0.0.1:1/schema.json",
+		"file:///etc/passwd",
+		"http://127.0.0.1:1/schema.json#/definitions/x",
+	} {
+		_, err = c.Compile([]byte(`{"properties": {"x": {"$ref": "` + ref + `"}}}`))
+		NewWithT(t).Expect(err).NotTo(BeNil())
+		NewWithT(t).Expect(err.Error()).To(ContainSubstring("external schema reference is not allowed"))
+	}
+}
@@ -0,0 +1,25 @@
+package wasmtime
+
+import (
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+// signEthMessage signs msg in EIP-191 personal_sign format, which hashes
+// "\x19Ethereum Signed Message:\n" + len(msg) + msg, with the hex encoded
+// ECDSA private key. the recovery id of signature is 27 or 28
+func signEthMessage(privateKey string, msg []byte) (string, error) {
+	pk, err := crypto.ToECDSA(common.FromHex(privateKey))
+	if err != nil {
+		return "", errors.Wrap(err, "invalid operator private key")
+	}
+	sig, err := crypto.Sign(accounts.TextHash(msg), pk)
+	if err != nil {
+		return "", err
+	}
+	sig[crypto.RecoveryIDOffset] += 27
+	return hexutil.Encode(sig), nil
+}
@@ -0,0 +1,31 @@
+package wasmtime
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	. "github.com/onsi/gomega"
+)
+
+func TestSignEthMessage(t *testing.T) {
+	pk, err := crypto.GenerateKey()
+	NewWithT(t).Expect(err).To(BeNil())
+	msg := []byte("device_1 temperature 23.5")
+
+	sig, err := signEthMessage(hexutil.Encode(crypto.FromECDSA(pk)), msg)
+	NewWithT(t).Expect(err).To(BeNil())
+
+	raw := hexutil.MustDecode(sig)
+	NewWithT(t).Expect(raw).To(HaveLen(crypto.SignatureLength))
+	NewWithT(t).Expect(raw[crypto.RecoveryIDOffset]).To(BeElementOf(byte(27), byte(28)))
+
+	raw[crypto.RecoveryIDOffset] -= 27
+	pub, err := crypto.SigToPub(accounts.TextHash(msg), raw)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(crypto.PubkeyToAddress(*pub)).To(Equal(crypto.PubkeyToAddress(pk.PublicKey)))
+
+	_, err = signEthMessage("0x1234", msg)
+	NewWithT(t).Expect(err).NotTo(BeNil())
+}
@@ -0,0 +1,109 @@
+package wasmtime
+
+import (
+	"bytes"
+	"encoding/binary"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// StringEncoding the memory layout of strings passed by wasm to env.abort and
+// env.trace
+type StringEncoding int
+
+const (
+	// StringEncodingAutoDetect detects layout by the length prefix, it falls
+	// back to null-terminated UTF-8 if the prefix is not a plausible length
+	StringEncodingAutoDetect StringEncoding = iota
+	// StringEncodingUTF16 AssemblyScript layout, UTF-16LE data with a 4-byte
+	// length prefix before the pointer
+	StringEncodingUTF16
+	// StringEncodingNullTerminated TinyGo layout, null-terminated UTF-8 data
+	// started from the pointer
+	StringEncodingNullTerminated
+)
+
+// maxStringLength the max byte length of string read from wasm memory
+const maxStringLength = 65536
+
+// nullTerminatedChunk the chunk size of reading null-terminated string
+const nullTerminatedChunk = 64
+
+type memReader func(addr, size int32) ([]byte, error)
+
+func readString(read memReader, ptr int32, enc StringEncoding) (string, error) {
+	switch enc {
+	case StringEncodingUTF16:
+		return readUTF16String(read, ptr)
+	case StringEncodingNullTerminated:
+		return readNullTerminatedString(read, ptr)
+	case StringEncodingAutoDetect:
+		if ptr >= 4 {
+			if data, err := read(ptr-4, 4); err == nil {
+				if size := binary.LittleEndian.Uint32(data); size <= maxStringLength && size%2 == 0 {
+					if s, err := readUTF16String(read, ptr); err == nil {
+						return s, nil
+					}
+				}
+			}
+		}
+		return readNullTerminatedString(read, ptr)
+	default:
+		return "", errors.Errorf("unknown string encoding %d", enc)
+	}
+}
+
+func readUTF16String(read memReader, ptr int32) (string, error) {
+	if ptr < 4 {
+		return "", errors.Errorf("the pointer address %d is invalid", ptr)
+	}
+
+	decoder := unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM).NewDecoder()
+
+	lenData, err := read(ptr-4, 4) // sizeof(uint32) is 4
+	if err != nil {
+		return "", err
+	}
+	size := int32(binary.LittleEndian.Uint32(lenData))
+	if size < 0 {
+		return "", errors.Errorf("the string length %d is invalid", size)
+	}
+	data, err := read(ptr, size)
+	if err != nil {
+		return "", err
+	}
+	utf8bytes, err := decoder.Bytes(data)
+	if err != nil {
+		return "", err
+	}
+	return string(utf8bytes), nil
+}
+
+func readNullTerminatedString(read memReader, ptr int32) (string, error) {
+	if ptr < 0 {
+		return "", errors.Errorf("the pointer address %d is invalid", ptr)
+	}
+
+	buf := make([]byte, 0, nullTerminatedChunk)
+	for addr := ptr; len(buf) < maxStringLength; {
+		chunk, err := read(addr, nullTerminatedChunk)
+		if err != nil {
+			// near the end of memory, read byte by byte
+			if chunk, err = read(addr, 1); err != nil {
+				return "", err
+			}
+		}
+		if i := bytes.IndexByte(chunk, 0); i >= 0 {
+			buf = append(buf, chunk[:i]...)
+			if !utf8.Valid(buf) {
+				return "", errors.New("invalid utf-8 string")
+			}
+			return string(buf), nil
+		}
+		buf = append(buf, chunk...)
+		addr += int32(len(chunk))
+	}
+	return "", errors.Errorf("string exceeds %d bytes without null terminator", maxStringLength)
+}
@@ -0,0 +1,48 @@
+package wasmtime
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func memoryReader(mem []byte) memReader {
+	return func(addr, size int32) ([]byte, error) {
+		if addr < 0 || size < 0 || int(addr)+int(size) > len(mem) {
+			return nil, errors.New("overflow")
+		}
+		return mem[addr : addr+size], nil
+	}
+}
+
+func TestReadString(t *testing.T) {
+	// AssemblyScript: 4-byte length prefix and UTF-16LE data
+	as := make([]byte, 16)
+	binary.LittleEndian.PutUint32(as[4:], 4)
+	copy(as[8:], []byte{'h', 0, 'i', 0})
+
+	// TinyGo: null-terminated UTF-8 data, and the bytes before are garbage
+	tg := make([]byte, 16)
+	binary.LittleEndian.PutUint32(tg[0:], 0xFFFFFFFF)
+	copy(tg[4:], "hello\x00")
+
+	s, err := readString(memoryReader(as), 8, StringEncodingAutoDetect)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(s).To(Equal("hi"))
+
+	s, err = readString(memoryReader(tg), 4, StringEncodingAutoDetect)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(s).To(Equal("hello"))
+
+	s, err = readString(memoryReader(tg), 4, StringEncodingNullTerminated)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(s).To(Equal("hello"))
+
+	_, err = readString(memoryReader(tg), 4, StringEncodingUTF16)
+	NewWithT(t).Expect(err).NotTo(BeNil())
+
+	_, err = readString(memoryReader([]byte("no terminator")), 0, StringEncodingNullTerminated)
+	NewWithT(t).Expect(err).NotTo(BeNil())
+}
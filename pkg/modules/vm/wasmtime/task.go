@@ -13,6 +13,12 @@ type Task struct {
 	EventType string
 	Handler   string
 	Payload   []byte
+	// PublisherKey the key of publisher(device) which published the event
+	PublisherKey string
+	// TraceID trace id of the event request
+	TraceID string
+	// EmitDepth nested depth of the event emitted by ws_emit_event
+	EmitDepth int
 	mq.TaskState
 
 	vm       *Instance
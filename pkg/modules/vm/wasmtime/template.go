@@ -0,0 +1,177 @@
+package wasmtime
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync/atomic"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// formatTimeout the max duration of template execution of ws_format_string
+	formatTimeout = 100 * time.Millisecond
+	// maxFormatOutput the max byte length of string rendered by ws_format_string
+	maxFormatOutput = 1 << 20
+	// maxFormatTemplate the max byte length of template of ws_format_string
+	maxFormatTemplate = 64 << 10
+	// maxFormatValues the max byte length of json values of ws_format_string
+	maxFormatValues = 256 << 10
+	// maxFormatIterations the max iterations of range actions and template
+	// invocations of a rendering
+	maxFormatIterations = 100000
+	// maxConcurrentFormats the max renderings running at the same time, the
+	// rendering abandoned by timeout is counted until it is stopped
+	maxConcurrentFormats = 64
+)
+
+var (
+	errFormatOutputExceeded     = errors.Errorf("rendered string exceeds %d bytes", maxFormatOutput)
+	errFormatIterationsExceeded = errors.Errorf("template iterations exceed %d", maxFormatIterations)
+	errFormatStopped            = errors.New("template execution is stopped")
+	errTooManyFormats           = errors.Errorf("concurrent template renderings exceed %d", maxConcurrentFormats)
+)
+
+// formats the semaphore of concurrent renderings
+var formats = make(chan struct{}, maxConcurrentFormats)
+
+// formatState the state of a rendering, it stops the execution when the
+// iterations exceeded or the rendering is abandoned
+type formatState struct {
+	iterations int64
+	stopped    int32
+}
+
+func (s *formatState) stop() { atomic.StoreInt32(&s.stopped, 1) }
+
+// tick is called at each iteration of range actions and each template
+// invocation
+func (s *formatState) tick() (string, error) {
+	if atomic.LoadInt32(&s.stopped) != 0 {
+		return "", errFormatStopped
+	}
+	if atomic.AddInt64(&s.iterations, 1) > maxFormatIterations {
+		return "", errFormatIterationsExceeded
+	}
+	return "", nil
+}
+
+// limitedBuffer fails writing when its length exceeds limit or the rendering is
+// stopped, so the runaway template execution is stopped
+type limitedBuffer struct {
+	bytes.Buffer
+	limit int
+	state *formatState
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if atomic.LoadInt32(&b.state.stopped) != 0 {
+		return 0, errFormatStopped
+	}
+	if b.Len()+len(p) > b.limit {
+		return 0, errFormatOutputExceeded
+	}
+	return b.Buffer.Write(p)
+}
+
+// formatTickFunc the function injected to templates to count iterations
+const formatTickFunc = "_ws_tick"
+
+// injectTicks inserts the tick action at the beginning of each range body and
+// each defined template, so the iterations of rendering are counted
+func injectTicks(t *template.Template, tick parse.Node) {
+	var walk func(n parse.Node)
+	walk = func(n parse.Node) {
+		switch n := n.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, c := range n.Nodes {
+				walk(c)
+			}
+		case *parse.IfNode:
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.WithNode:
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.RangeNode:
+			walk(n.List)
+			walk(n.ElseList)
+			n.List.Nodes = append([]parse.Node{tick}, n.List.Nodes...)
+		}
+	}
+	for _, v := range t.Templates() {
+		if v.Tree == nil || v.Tree.Root == nil {
+			continue
+		}
+		walk(v.Tree.Root)
+		if v.Name() != t.Name() {
+			v.Tree.Root.Nodes = append([]parse.Node{tick}, v.Tree.Root.Nodes...)
+		}
+	}
+}
+
+// formatString renders text/template tmpl with json object values, the
+// execution is abandoned after timeout and stopped at its next output or
+// iteration
+func formatString(tmpl string, values []byte, timeout time.Duration) (string, error) {
+	if len(tmpl) > maxFormatTemplate {
+		return "", errors.Errorf("template exceeds %d bytes", maxFormatTemplate)
+	}
+	if len(values) > maxFormatValues {
+		return "", errors.Errorf("values exceed %d bytes", maxFormatValues)
+	}
+
+	state := &formatState{}
+	funcs := template.FuncMap{formatTickFunc: state.tick}
+	t, err := template.New("ws_format_string").Option("missingkey=error").Funcs(funcs).Parse(tmpl)
+	if err != nil {
+		return "", errors.Wrap(err, "parse template")
+	}
+	tick, err := template.New("").Funcs(funcs).Parse("{{" + formatTickFunc + "}}")
+	if err != nil {
+		return "", err
+	}
+	injectTicks(t, tick.Tree.Root.Nodes[0])
+
+	data := map[string]interface{}{}
+	if len(bytes.TrimSpace(values)) > 0 {
+		if err = json.Unmarshal(values, &data); err != nil {
+			return "", errors.Wrap(err, "values should be a json object")
+		}
+	}
+
+	select {
+	case formats <- struct{}{}:
+	default:
+		return "", errTooManyFormats
+	}
+
+	type result struct {
+		out string
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		defer func() { <-formats }()
+		buf := &limitedBuffer{limit: maxFormatOutput, state: state}
+		err := t.Execute(buf, data)
+		done <- result{buf.String(), err}
+	}()
+
+	select {
+	case r := <-done:
+		if r.err != nil {
+			return "", errors.Wrap(r.err, "execute template")
+		}
+		return r.out, nil
+	case <-time.After(timeout):
+		state.stop()
+		return "", errors.Errorf("template execution timeout after %s", timeout)
+	}
+}
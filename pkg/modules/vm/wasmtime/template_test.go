@@ -0,0 +1,80 @@
+package wasmtime
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestFormatString(t *testing.T) {
+	out, err := formatString(`device {{.id}} reports {{printf "%.1f" .temp}}`, []byte(`{"id":"d1","temp":23.46}`), formatTimeout)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(out).To(Equal("device d1 reports 23.5"))
+
+	_, err = formatString(`{{.id`, nil, formatTimeout)
+	NewWithT(t).Expect(err).NotTo(BeNil())
+
+	_, err = formatString(`{{.missing}}`, []byte(`{}`), formatTimeout)
+	NewWithT(t).Expect(err).NotTo(BeNil())
+
+	_, err = formatString(`{{.}}`, []byte(`[1]`), formatTimeout)
+	NewWithT(t).Expect(err).NotTo(BeNil())
+
+	_, err = formatString(`{{range .items}}`+strings.Repeat("x", 1024)+`{{end}}`,
+		[]byte(`{"items":[`+strings.Repeat("0,", 2048)+`0]}`), time.Second)
+	NewWithT(t).Expect(err).NotTo(BeNil())
+}
+
+func TestFormatString_Limits(t *testing.T) {
+	_, err := formatString(strings.Repeat("x", maxFormatTemplate+1), nil, formatTimeout)
+	NewWithT(t).Expect(err).NotTo(BeNil())
+
+	_, err = formatString(`{{.id}}`, []byte(`{"id":"`+strings.Repeat("x", maxFormatValues)+`"}`), formatTimeout)
+	NewWithT(t).Expect(err).NotTo(BeNil())
+
+	// nested ranges without output are bounded by iterations
+	items := `{"items":[` + strings.Repeat("0,", 999) + `0]}`
+	_, err = formatString(`{{range .items}}{{range $.items}}{{end}}{{end}}`, []byte(items), time.Minute)
+	NewWithT(t).Expect(err).NotTo(BeNil())
+	NewWithT(t).Expect(err.Error()).To(ContainSubstring(errFormatIterationsExceeded.Error()))
+
+	out, err := formatString(`{{range .items}}{{.}}{{else}}empty{{end}}`, []byte(`{"items":[1,2,3]}`), formatTimeout)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(out).To(Equal("123"))
+
+	out, err = formatString(`{{range .items}}{{.}}{{else}}empty{{end}}`, []byte(`{"items":[]}`), formatTimeout)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(out).To(Equal("empty"))
+
+	// recursive template invocations are bounded by iterations
+	_, err = formatString(`{{define "a"}}{{template "a" .}}{{template "a" .}}{{end}}{{template "a" .}}`, nil, time.Minute)
+	NewWithT(t).Expect(err).NotTo(BeNil())
+
+	out, err = formatString(`{{define "a"}}<{{.}}>{{end}}{{template "a" .id}}`, []byte(`{"id":"d1"}`), formatTimeout)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(out).To(Equal("<d1>"))
+}
+
+func TestFormatString_Concurrency(t *testing.T) {
+	for i := 0; i < maxConcurrentFormats; i++ {
+		formats <- struct{}{}
+	}
+	_, err := formatString(`{{.id}}`, []byte(`{"id":"d1"}`), formatTimeout)
+	NewWithT(t).Expect(err).To(Equal(errTooManyFormats))
+
+	for i := 0; i < maxConcurrentFormats; i++ {
+		<-formats
+	}
+	out, err := formatString(`{{.id}}`, []byte(`{"id":"d1"}`), formatTimeout)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(out).To(Equal("d1"))
+}
+
+func TestFormatString_StopAfterTimeout(t *testing.T) {
+	items := `{"items":[` + strings.Repeat("0,", 299) + `0]}`
+	_, err := formatString(`{{range .items}}{{range $.items}}{{printf "%v" .}}{{end}}{{end}}`, []byte(items), time.Nanosecond)
+	NewWithT(t).Expect(err).NotTo(BeNil())
+	NewWithT(t).Eventually(func() int { return len(formats) }, time.Second).Should(Equal(0))
+}
@@ -0,0 +1,105 @@
+package wasmtime
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	confipfs "github.com/machinefi/w3bstream/pkg/depends/conf/ipfs"
+)
+
+const (
+	// tokenMetadataTimeout the timeout of fetching token metadata
+	tokenMetadataTimeout = 3 * time.Second
+	// maxTokenMetadataSize the max bytes of token metadata
+	maxTokenMetadataSize = 1 << 20
+)
+
+// tokenMetadataClient fetches token metadata from public addresses only, the
+// token uri is controlled by contract deployer
+var tokenMetadataClient = newGuardedHTTPClient(publicIP)
+
+// fetchTokenMetadata fetches the metadata json of token from uri returned by
+// tokenURI/uri. the ERC1155 `{id}` placeholder is substituted by the hex id,
+// and http(s), ipfs(through ipfs api, nil means unsupported) and data uris
+// are supported
+func fetchTokenMetadata(ctx context.Context, uri string, tokenID *big.Int, ipfs *confipfs.IPFSConfig, timeout time.Duration) ([]byte, error) {
+	uri = strings.ReplaceAll(strings.TrimSpace(uri), "{id}", fmt.Sprintf("%064x", tokenID))
+
+	var (
+		data []byte
+		err  error
+	)
+	switch {
+	case strings.HasPrefix(uri, "data:"):
+		data, err = decodeDataURI(uri)
+	case strings.HasPrefix(uri, "ipfs://"):
+		if ipfs == nil {
+			return nil, errors.New("ipfs is not configured")
+		}
+		data, err = ipfs.Cat(strings.TrimPrefix(strings.TrimPrefix(uri, "ipfs://"), "ipfs/"))
+	case strings.HasPrefix(uri, "http://"), strings.HasPrefix(uri, "https://"):
+		data, err = fetchHTTP(ctx, uri, timeout)
+	default:
+		return nil, errors.Errorf("unsupported token uri: %s", uri)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !json.Valid(data) {
+		return nil, errors.Errorf("token metadata from %s is not json", uri)
+	}
+	return data, nil
+}
+
+// decodeDataURI decodes `data:[<mediatype>][;base64],<data>`
+func decodeDataURI(uri string) ([]byte, error) {
+	meta, payload, ok := strings.Cut(strings.TrimPrefix(uri, "data:"), ",")
+	if !ok {
+		return nil, errors.New("invalid data uri")
+	}
+	if strings.HasSuffix(meta, ";base64") {
+		return base64.StdEncoding.DecodeString(payload)
+	}
+	s, err := url.PathUnescape(payload)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(s), nil
+}
+
+func fetchHTTP(ctx context.Context, uri string, timeout time.Duration) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	rsp, err := tokenMetadataClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("fetch %s: %s", uri, rsp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(rsp.Body, maxTokenMetadataSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxTokenMetadataSize {
+		return nil, errors.Errorf("token metadata exceeds %d bytes", maxTokenMetadataSize)
+	}
+	return data, nil
+}
@@ -0,0 +1,55 @@
+package wasmtime
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestFetchTokenMetadata(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/000000000000000000000000000000000000000000000000000000000000002a.json":
+			_, _ = rw.Write([]byte(`{"name":"sensor #42"}`))
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		default:
+			http.NotFound(rw, r)
+		}
+	}))
+	defer srv.Close()
+
+	ctx, id := context.Background(), big.NewInt(42)
+
+	_, err := fetchTokenMetadata(ctx, srv.URL+"/{id}.json", id, nil, time.Second)
+	NewWithT(t).Expect(errors.Is(err, errForbiddenAddr)).To(BeTrue())
+
+	defer func(c *http.Client) { tokenMetadataClient = c }(tokenMetadataClient)
+	tokenMetadataClient = newGuardedHTTPClient(func(net.IP) bool { return true })
+
+	data, err := fetchTokenMetadata(ctx, srv.URL+"/{id}.json", id, nil, time.Second)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(string(data)).To(Equal(`{"name":"sensor #42"}`))
+
+	data, err = fetchTokenMetadata(ctx, "data:application/json;base64,eyJuYW1lIjoiZCJ9", id, nil, time.Second)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(string(data)).To(Equal(`{"name":"d"}`))
+
+	data, err = fetchTokenMetadata(ctx, `data:application/json,{"name":"d%201"}`, id, nil, time.Second)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(string(data)).To(Equal(`{"name":"d 1"}`))
+
+	_, err = fetchTokenMetadata(ctx, srv.URL+"/missing", id, nil, time.Second)
+	NewWithT(t).Expect(err).NotTo(BeNil())
+	_, err = fetchTokenMetadata(ctx, srv.URL+"/slow", id, nil, 50*time.Millisecond)
+	NewWithT(t).Expect(err).NotTo(BeNil())
+	_, err = fetchTokenMetadata(ctx, "ipfs://QmHash", id, nil, time.Second)
+	NewWithT(t).Expect(err).NotTo(BeNil())
+}
@@ -0,0 +1,23 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/machinefi/w3bstream/pkg/types/wasm"
+)
+
+// ResetForTest resets the sequences of db before the test and again after the
+// test completes. the test binary must be built with BuildMode `testing`
+func ResetForTest(t testing.TB, db *wasm.Database) {
+	t.Helper()
+
+	reset := func() {
+		if err := db.ResetSequences(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reset()
+	t.Cleanup(reset)
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 
 	"github.com/machinefi/w3bstream/pkg/depends/conf/filesystem"
+	"github.com/machinefi/w3bstream/pkg/depends/conf/ipfs"
 	"github.com/machinefi/w3bstream/pkg/depends/conf/log"
 	"github.com/machinefi/w3bstream/pkg/depends/conf/mqtt"
+	"github.com/machinefi/w3bstream/pkg/depends/conf/nats"
 	"github.com/machinefi/w3bstream/pkg/depends/conf/redis"
+	"github.com/machinefi/w3bstream/pkg/depends/conf/secret"
 	"github.com/machinefi/w3bstream/pkg/depends/kit/httptransport/client"
 	"github.com/machinefi/w3bstream/pkg/depends/kit/mq"
 	"github.com/machinefi/w3bstream/pkg/depends/kit/sqlx"
@@ -47,12 +50,25 @@ type (
 	CtxProxyClient struct{}
 	// CtxWasmDBConfig type *WasmDBConfig wasm database config
 	CtxWasmDBConfig struct{}
+	// CtxWasmRuntimeConfig type *WasmRuntimeConfig wasm runtime host limits
+	CtxWasmRuntimeConfig struct{}
+	// CtxEventConfig type *EventConfig event handling config
+	CtxEventConfig struct{}
+	// CtxWasmLogConfig type *WasmLogConfig wasm log rotation config
+	CtxWasmLogConfig struct{}
 	// CtxRobotNotifierConfig type *RobotNotifierConfig for notify service level message to maintainers.
 	CtxRobotNotifierConfig struct{}
 	// CtxMetricsCenterConfig *MetricsCenterConfig for metrics
 	CtxMetricsCenterConfig struct{}
 	// CtxOperatorPool type *operator.Pool global operator memory pool
 	CtxOperatorPool struct{}
+	// CtxNATS type *nats.NATSConfig NATS JetStream client, nil if not configured
+	CtxNATS struct{}
+	// CtxIPFS type *ipfs.IPFSConfig ipfs http api client, nil if not configured
+	CtxIPFS struct{}
+	// CtxSecretProvider type *secret.SecretProviderConfig secret provider of
+	// wasm, nil if not configured
+	CtxSecretProvider struct{}
 )
 
 // model contexts
@@ -95,6 +111,11 @@ type (
 	CtxStrategyResults struct{} // CtxStrategyResults
 	// CtxEventID type string. current event id
 	CtxEventID struct{}
+	// CtxTraceID type string. trace id propagated from the event request
+	CtxTraceID struct{}
+	// CtxEmitDepth type int. nested depth of the event emitted by wasm handler,
+	// 0 means the event is not emitted by wasm
+	CtxEmitDepth struct{}
 	// CtxWasmApiServer type wasmapi/types.Server wasm global async server TODO move to wasm context package
 	CtxWasmApiServer struct{}
 )
@@ -691,6 +712,69 @@ func MustWasmDBConfigFromContext(ctx context.Context) *WasmDBConfig {
 	return v
 }
 
+func WithWasmRuntimeConfig(ctx context.Context, v *WasmRuntimeConfig) context.Context {
+	return contextx.WithValue(ctx, CtxWasmRuntimeConfig{}, v)
+}
+
+func WithWasmRuntimeConfigContext(v *WasmRuntimeConfig) contextx.WithContext {
+	return func(ctx context.Context) context.Context {
+		return contextx.WithValue(ctx, CtxWasmRuntimeConfig{}, v)
+	}
+}
+
+func WasmRuntimeConfigFromContext(ctx context.Context) (*WasmRuntimeConfig, bool) {
+	v, ok := ctx.Value(CtxWasmRuntimeConfig{}).(*WasmRuntimeConfig)
+	return v, ok
+}
+
+func MustWasmRuntimeConfigFromContext(ctx context.Context) *WasmRuntimeConfig {
+	v, ok := WasmRuntimeConfigFromContext(ctx)
+	must.BeTrue(ok)
+	return v
+}
+
+func WithWasmLogConfig(ctx context.Context, v *WasmLogConfig) context.Context {
+	return contextx.WithValue(ctx, CtxWasmLogConfig{}, v)
+}
+
+func WithWasmLogConfigContext(v *WasmLogConfig) contextx.WithContext {
+	return func(ctx context.Context) context.Context {
+		return contextx.WithValue(ctx, CtxWasmLogConfig{}, v)
+	}
+}
+
+func WasmLogConfigFromContext(ctx context.Context) (*WasmLogConfig, bool) {
+	v, ok := ctx.Value(CtxWasmLogConfig{}).(*WasmLogConfig)
+	return v, ok
+}
+
+func MustWasmLogConfigFromContext(ctx context.Context) *WasmLogConfig {
+	v, ok := WasmLogConfigFromContext(ctx)
+	must.BeTrue(ok)
+	return v
+}
+
+func WithEventConfig(ctx context.Context, v *EventConfig) context.Context {
+	return contextx.WithValue(ctx, CtxEventConfig{}, v)
+}
+
+func WithEventConfigContext(v *EventConfig) contextx.WithContext {
+	return func(ctx context.Context) context.Context {
+		return contextx.WithValue(ctx, CtxEventConfig{}, v)
+	}
+}
+
+func EventConfigFromContext(ctx context.Context) (*EventConfig, bool) {
+	v, ok := ctx.Value(CtxEventConfig{}).(*EventConfig)
+	return v, ok
+}
+
+func MustEventConfigFromContext(ctx context.Context) *EventConfig {
+	v, ok := EventConfigFromContext(ctx)
+	must.BeTrue(ok)
+	return v
+}
+
 func WithEventID(ctx context.Context, v string) context.Context {
 	return contextx.WithValue(ctx, CtxEventID{}, v)
 }
@@ -712,6 +796,42 @@ func MustEventIDFromContext(ctx context.Context) string {
 	return v
 }
 
+func WithTraceID(ctx context.Context, v string) context.Context {
+	return contextx.WithValue(ctx, CtxTraceID{}, v)
+}
+
+func WithTraceIDContext(v string) contextx.WithContext {
+	return func(ctx context.Context) context.Context {
+		return contextx.WithValue(ctx, CtxTraceID{}, v)
+	}
+}
+
+func TraceIDFromContext(ctx context.Context) (string, bool) {
+	v, ok := ctx.Value(CtxTraceID{}).(string)
+	return v, ok
+}
+
+func MustTraceIDFromContext(ctx context.Context) string {
+	v, ok := TraceIDFromContext(ctx)
+	must.BeTrue(ok)
+	return v
+}
+
+func WithEmitDepth(ctx context.Context, v int) context.Context {
+	return contextx.WithValue(ctx, CtxEmitDepth{}, v)
+}
+
+func WithEmitDepthContext(v int) contextx.WithContext {
+	return func(ctx context.Context) context.Context {
+		return contextx.WithValue(ctx, CtxEmitDepth{}, v)
+	}
+}
+
+func EmitDepthFromContext(ctx context.Context) (int, bool) {
+	v, ok := ctx.Value(CtxEmitDepth{}).(int)
+	return v, ok
+}
+
 func WithTrafficLimit(ctx context.Context, r *models.TrafficLimit) context.Context {
 	_r := *r
 	return contextx.WithValue(ctx, CtxTrafficLimit{}, &_r)
@@ -776,6 +896,69 @@ func MustRobotNotifierConfigFromContext(ctx context.Context) *RobotNotifierConfi
 	return v
 }
 
+func WithNATS(ctx context.Context, v *nats.NATSConfig) context.Context {
+	return contextx.WithValue(ctx, CtxNATS{}, v)
+}
+
+func WithNATSContext(v *nats.NATSConfig) contextx.WithContext {
+	return func(ctx context.Context) context.Context {
+		return contextx.WithValue(ctx, CtxNATS{}, v)
+	}
+}
+
+func NATSFromContext(ctx context.Context) (*nats.NATSConfig, bool) {
+	v, ok := ctx.Value(CtxNATS{}).(*nats.NATSConfig)
+	return v, ok
+}
+
+func MustNATSFromContext(ctx context.Context) *nats.NATSConfig {
+	v, ok := NATSFromContext(ctx)
+	must.BeTrue(ok)
+	return v
+}
+
+func WithIPFS(ctx context.Context, v *ipfs.IPFSConfig) context.Context {
+	return contextx.WithValue(ctx, CtxIPFS{}, v)
+}
+
+func WithIPFSContext(v *ipfs.IPFSConfig) contextx.WithContext {
+	return func(ctx context.Context) context.Context {
+		return contextx.WithValue(ctx, CtxIPFS{}, v)
+	}
+}
+
+func IPFSFromContext(ctx context.Context) (*ipfs.IPFSConfig, bool) {
+	v, ok := ctx.Value(CtxIPFS{}).(*ipfs.IPFSConfig)
+	return v, ok
+}
+
+func MustIPFSFromContext(ctx context.Context) *ipfs.IPFSConfig {
+	v, ok := IPFSFromContext(ctx)
+	must.BeTrue(ok)
+	return v
+}
+
+func WithSecretProvider(ctx context.Context, v *secret.SecretProviderConfig) context.Context {
+	return contextx.WithValue(ctx, CtxSecretProvider{}, v)
+}
+
+func WithSecretProviderContext(v *secret.SecretProviderConfig) contextx.WithContext {
+	return func(ctx context.Context) context.Context {
+		return contextx.WithValue(ctx, CtxSecretProvider{}, v)
+	}
+}
+
+func SecretProviderFromContext(ctx context.Context) (*secret.SecretProviderConfig, bool) {
+	v, ok := ctx.Value(CtxSecretProvider{}).(*secret.SecretProviderConfig)
+	return v, ok
+}
+
+func MustSecretProviderFromContext(ctx context.Context) *secret.SecretProviderConfig {
+	v, ok := SecretProviderFromContext(ctx)
+	must.BeTrue(ok)
+	return v
+}
+
 func WithWasmApiServer(ctx context.Context, v wasmapi.Server) context.Context {
 	return contextx.WithValue(ctx, CtxWasmApiServer{}, v)
 }
@@ -7,12 +7,16 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/blocto/solana-go-sdk/client"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/pkg/errors"
 	"github.com/tidwall/gjson"
 
 	"github.com/machinefi/w3bstream/pkg/depends/base/types"
@@ -22,8 +26,9 @@ import (
 )
 
 type UploadConfig struct {
-	FilesizeLimitBytes int64 `env:""`
-	DiskReserveBytes   int64 `env:""`
+	FilesizeLimitBytes int64    `env:""`
+	DiskReserveBytes   int64    `env:""`
+	AllowedExtensions  []string `env:""`
 }
 
 func (c *UploadConfig) SetDefault() {
@@ -33,6 +38,20 @@ func (c *UploadConfig) SetDefault() {
 	if c.DiskReserveBytes == 0 {
 		c.DiskReserveBytes = 20 * 1024 * 1024
 	}
+	if len(c.AllowedExtensions) == 0 {
+		c.AllowedExtensions = []string{".wasm", ".tar", ".zip"}
+	}
+}
+
+// IsAllowedExtension checks if the extension of filename is in AllowedExtensions
+func (c *UploadConfig) IsAllowedExtension(filename string) bool {
+	ext := strings.ToLower(filepath.Ext(filename))
+	for _, allowed := range c.AllowedExtensions {
+		if strings.ToLower(allowed) == ext {
+			return true
+		}
+	}
+	return false
 }
 
 type FileSystem struct {
@@ -69,6 +88,8 @@ type Chain struct {
 	ChainID  uint64          `json:"chainID,omitempty"`
 	Name     enums.ChainName `json:"name"`
 	Endpoint string          `json:"endpoint"`
+	// Multicall3Address the deployed Multicall3 contract, batch contract calls if assigned
+	Multicall3Address string `json:"multicall3Address,omitempty"`
 }
 
 func (c *Chain) IsSolana() bool {
@@ -127,13 +148,82 @@ func (c *ChainConfig) Init() {
 	c.ChainIDs = cidm
 }
 
+// Validate pings each configured chain endpoint in parallel, eth_blockNumber
+// for evm chains and getLatestBlockhash for solana chains. it returns one error
+// per unreachable chain ordered by chain name
+func (c *ChainConfig) Validate(timeout time.Duration) []error {
+	chains := make([]*Chain, 0, len(c.Chains))
+	for _, ch := range c.Chains {
+		chains = append(chains, ch)
+	}
+	sort.Slice(chains, func(i, j int) bool { return chains[i].Name < chains[j].Name })
+
+	errs := make([]error, len(chains))
+	wg := &sync.WaitGroup{}
+	for i, ch := range chains {
+		wg.Add(1)
+		go func(i int, ch *Chain) {
+			defer wg.Done()
+			if err := ch.ping(timeout); err != nil {
+				errs[i] = errors.Wrapf(err, "chain %s(%d) endpoint %s", ch.Name, ch.ChainID, ch.Endpoint)
+			}
+		}(i, ch)
+	}
+	wg.Wait()
+
+	ret := make([]error, 0)
+	for _, err := range errs {
+		if err != nil {
+			ret = append(ret, err)
+		}
+	}
+	return ret
+}
+
+func (c *Chain) ping(timeout time.Duration) error {
+	if c.Endpoint == "" {
+		return errors.New("empty endpoint")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if c.IsSolana() {
+		_, err := client.NewClient(c.Endpoint).GetLatestBlockhash(ctx)
+		return err
+	}
+	cli, err := ethclient.DialContext(ctx, c.Endpoint)
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+	_, err = cli.BlockNumber(ctx)
+	return err
+}
+
 func (c *ChainConfig) GetChain(chainID uint64, chainName enums.ChainName) (*Chain, bool) {
-	r, ok := c.ChainIDs[chainID]
-	if ok {
-		return r, ok
+	if r, err := c.GetByID(chainID); err == nil {
+		return r, true
 	}
-	r, ok = c.Chains[chainName]
-	return r, ok
+	if r, err := c.GetByName(chainName); err == nil {
+		return r, true
+	}
+	return nil, false
+}
+
+// GetByName returns chain config by chain name, error if not configured
+func (c *ChainConfig) GetByName(name enums.ChainName) (*Chain, error) {
+	if r, ok := c.Chains[name]; ok && r != nil {
+		return r, nil
+	}
+	return nil, errors.Errorf("chain %v not found", name)
+}
+
+// GetByID returns chain config by chain id, error if not configured
+func (c *ChainConfig) GetByID(id uint64) (*Chain, error) {
+	if r, ok := c.ChainIDs[id]; ok && r != nil {
+		return r, nil
+	}
+	return nil, errors.Errorf("chain %v not found", id)
 }
 
 // aliases from base/types
@@ -173,13 +263,14 @@ func (v *EthAddressWhiteList) Validate(address string) bool {
 }
 
 type StrategyResult struct {
-	ProjectName string         `json:"projectName" db:"f_prj_name"`
-	AppletID    types.SFID     `json:"appletID"    db:"f_app_id"`
-	AppletName  string         `json:"appletName"  db:"f_app_name"`
-	InstanceID  types.SFID     `json:"instanceID"  db:"f_ins_id"`
-	Handler     string         `json:"handler"     db:"f_hdl"`
-	EventType   string         `json:"eventType"   db:"f_evt"`
-	AutoCollect datatypes.Bool `json:"autoCollect" db:"f_auto_collect"`
+	ProjectName string                  `json:"projectName" db:"f_prj_name"`
+	AppletID    types.SFID              `json:"appletID"    db:"f_app_id"`
+	AppletName  string                  `json:"appletName"  db:"f_app_name"`
+	InstanceID  types.SFID              `json:"instanceID"  db:"f_ins_id"`
+	Handler     string                  `json:"handler"     db:"f_hdl"`
+	EventType   string                  `json:"eventType"   db:"f_evt"`
+	AutoCollect datatypes.Bool          `json:"autoCollect" db:"f_auto_collect"`
+	MatchMode   enums.StrategyMatchMode `json:"matchMode,omitempty" db:"f_match_mode"`
 }
 
 type WasmDBConfig struct {
@@ -187,6 +278,18 @@ type WasmDBConfig struct {
 	MaxConnection   int
 	PoolSize        int
 	ConnMaxLifetime types.Duration
+	// MaxRetries retries of connecting database when init, negative means no
+	// retry
+	MaxRetries int
+	// RetryBaseDelay the delay before the first retry, it is doubled for each
+	// next retry
+	RetryBaseDelay types.Duration
+	// MaxQueryExecutionMs the statement timeout of sql queried by wasm in
+	// milliseconds, negative means no timeout
+	MaxQueryExecutionMs int
+	// TableMetricsInterval the interval of collecting estimated rows of wasm
+	// database tables of all projects, zero means no collection
+	TableMetricsInterval types.Duration
 }
 
 func (c *WasmDBConfig) SetDefault() {
@@ -199,6 +302,60 @@ func (c *WasmDBConfig) SetDefault() {
 	if c.ConnMaxLifetime == 0 {
 		c.ConnMaxLifetime = *types.AsDuration(time.Second * 20)
 	}
+	if c.MaxRetries == 0 {
+		c.MaxRetries = 5
+	}
+	if c.RetryBaseDelay == 0 {
+		c.RetryBaseDelay = *types.AsDuration(time.Second * 2)
+	}
+	if c.MaxQueryExecutionMs == 0 {
+		c.MaxQueryExecutionMs = 5000
+	}
+}
+
+type WasmRuntimeConfig struct {
+	// MaxDecompressedBytes limits the output size of ws_decompress
+	MaxDecompressedBytes int64 `env:""`
+	// MaxConcurrentApiResults limits the api call results dispatched to wasm
+	// concurrently
+	MaxConcurrentApiResults int `env:""`
+}
+
+func (c *WasmRuntimeConfig) SetDefault() {
+	if c.MaxDecompressedBytes == 0 {
+		c.MaxDecompressedBytes = 4 * 1024 * 1024
+	}
+	if c.MaxConcurrentApiResults == 0 {
+		c.MaxConcurrentApiResults = 50
+	}
+}
+
+type EventConfig struct {
+	// DedupTTL the duration of handled event id remembered for deduplication
+	DedupTTL types.Duration `env:""`
+}
+
+func (c *EventConfig) SetDefault() {
+	if c.DedupTTL == 0 {
+		c.DedupTTL = *types.AsDuration(5 * time.Minute)
+	}
+}
+
+type WasmLogConfig struct {
+	// MaxRowsPerProject the max wasm log rows kept for each project which not
+	// assigned its own
+	MaxRowsPerProject int `env:""`
+	// RotationInterval the interval of pruning wasm logs
+	RotationInterval types.Duration `env:""`
+}
+
+func (c *WasmLogConfig) SetDefault() {
+	if c.MaxRowsPerProject <= 0 {
+		c.MaxRowsPerProject = 100000
+	}
+	if c.RotationInterval == 0 {
+		c.RotationInterval = *types.AsDuration(time.Hour)
+	}
 }
 
 type MetricsCenterConfig struct {
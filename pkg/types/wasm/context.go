@@ -23,6 +23,7 @@ type (
 	CtxMqttClient        struct{}
 	CtxCustomMetrics     struct{}
 	CtxFlow              struct{}
+	CtxEventCounter      struct{}
 )
 
 func WithSQLStore(ctx context.Context, v *Database) context.Context {
@@ -255,3 +256,24 @@ func MustFlowFromContext(ctx context.Context) *Flow {
 	must.BeTrue(ok)
 	return v
 }
+
+func WithEventCounter(ctx context.Context, v *EventCounter) context.Context {
+	return contextx.WithValue(ctx, CtxEventCounter{}, v)
+}
+
+func WithEventCounterContext(v *EventCounter) contextx.WithContext {
+	return func(ctx context.Context) context.Context {
+		return contextx.WithValue(ctx, CtxEventCounter{}, v)
+	}
+}
+
+func EventCounterFromContext(ctx context.Context) (*EventCounter, bool) {
+	v, ok := ctx.Value(CtxEventCounter{}).(*EventCounter)
+	return v, ok
+}
+
+func MustEventCounterFromContext(ctx context.Context) *EventCounter {
+	v, ok := EventCounterFromContext(ctx)
+	must.BeTrue(ok)
+	return v
+}
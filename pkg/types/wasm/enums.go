@@ -19,6 +19,13 @@ const (
 	ResultStatusCode_EnvKeyNotFound
 	ResultStatusCode_NoDBContext
 	ResultStatusCode_ParamIllegal
+	ResultStatusCode_RecursionLimit
+	ResultStatusCode_Timeout
+	ResultStatusCode_RateLimited
+	ResultStatusCode_StorageQuotaExceeded
+	ResultStatusCode_ExecutionTicksExceeded
+	ResultStatusCode_CASFailed
+	ResultStatusCode_PermissionDenied
 
 	// TODO following result status
 	ResultStatusCode_Failed = -1 // reserved for wasm invoke failed
@@ -2,9 +2,11 @@ package wasm
 
 import (
 	"context"
+	"time"
 
 	"github.com/machinefi/w3bstream/pkg/depends/kit/sqlx"
 	"github.com/machinefi/w3bstream/pkg/enums"
+	"github.com/machinefi/w3bstream/pkg/types/wasm/kvdb"
 )
 
 type VM interface {
@@ -37,6 +39,10 @@ type EventHandleResult struct {
 	Rsp        []byte           `json:"-"`
 	Code       ResultStatusCode `json:"code"`
 	ErrMsg     string           `json:"errMsg"`
+	// Output handler output set by ws_set_output
+	Output []byte `json:"output,omitempty"`
+	// TraceID trace id of the event request
+	TraceID string `json:"traceID,omitempty"`
 }
 
 type EventConsumer interface {
@@ -46,8 +52,21 @@ type EventConsumer interface {
 type KVStore interface {
 	Get(string) ([]byte, error)
 	Set(key string, value []byte) error
+	// TTL returns the remaining lifetime of key, kvdb.NoExpiration if key has
+	// no expiration and kvdb.ErrKeyNotFound if key is not exists
+	TTL(key string) (time.Duration, error)
+	// BatchDelete deletes keys atomically and returns the count of keys deleted
+	BatchDelete(keys []string) (int, error)
+	// ScanPrefix iterates keys with prefix from cursor, empty cursor means
+	// from the beginning and empty nextCursor means iteration is done
+	ScanPrefix(prefix, cursor string, count int) (keys []string, nextCursor string, err error)
+	// Pipeline sets keys in one round trip, a *kvdb.PipelineError is returned
+	// if some of commands failed
+	Pipeline(cmds []KVCommand) error
 }
 
+type KVCommand = kvdb.KVCommand
+
 type SQLStore interface {
 	sqlx.SqlExecutor
 }
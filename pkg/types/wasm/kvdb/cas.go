@@ -0,0 +1,58 @@
+package kvdb
+
+import (
+	"bytes"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// casScript sets field only if its value equals ARGV[2], or field is not
+// exists if ARGV[4] is `1`, and adjusts the usage counter. returns 1 if set
+var casScript = redis.NewScript(2, `
+local cur = redis.call('HGET', KEYS[1], ARGV[1])
+if ARGV[4] == '1' then
+	if cur then
+		return 0
+	end
+elseif cur ~= ARGV[2] then
+	return 0
+end
+local old = 0
+if cur then
+	old = #ARGV[1] + #cur
+end
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[3])
+redis.call('INCRBY', KEYS[2], #ARGV[1] + #ARGV[3] - old)
+return 1
+`)
+
+// CompareAndSwap sets key to value only if the current value equals old, nil
+// old means key must not exist. it reports whether the value is swapped
+func (r *RedisDB) CompareAndSwap(key string, old, value []byte) (bool, error) {
+	conn := r.db.Get()
+	defer conn.Close()
+
+	absent := "0"
+	if old == nil {
+		absent = "1"
+	}
+	return redis.Bool(casScript.Do(conn, r.db.Prefix, r.usageKey(), key, old, value, absent))
+}
+
+// CompareAndSwap sets key to value only if the current value equals old, nil
+// old means key must not exist. it reports whether the value is swapped
+func (m *memDB) CompareAndSwap(key string, old, value []byte) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cur, ok := m.db[key]
+	if ok && m.expired(key) {
+		ok = false
+	}
+	if old == nil && ok || old != nil && (!ok || !bytes.Equal(cur, old)) {
+		return false, nil
+	}
+	m.db[key] = value
+	delete(m.expires, key)
+	return true, nil
+}
@@ -1,19 +1,51 @@
 package kvdb
 
 import (
+	"container/heap"
 	"errors"
 	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jonboulle/clockwork"
 )
 
+// SweepInterval the interval of sweeping expired keys of memory db
+var SweepInterval = 100 * time.Millisecond
+
 type memDB struct {
-	db map[string][]byte
+	mu      sync.Mutex
+	db      map[string][]byte
+	expires map[string]time.Time
+	queue   expiryQueue
+	clk     clockwork.Clock
+	once    sync.Once
+	stop    chan struct{}
+	ps      memPubSub
 }
 
 func NewMemDB() *memDB {
-	return &memDB{db: make(map[string][]byte)}
+	return NewMemDBWithClock(clockwork.NewRealClock())
+}
+
+// NewMemDBWithClock creates memory db with clock, it is useful to control ttl
+// expiration in tests
+func NewMemDBWithClock(clk clockwork.Clock) *memDB {
+	return &memDB{
+		db:      make(map[string][]byte),
+		expires: make(map[string]time.Time),
+		clk:     clk,
+		stop:    make(chan struct{}),
+	}
 }
 
 func (m *memDB) Get(key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.expired(key) {
+		return nil, errors.New(fmt.Sprintf("key[%s] not found", key))
+	}
 	value, ok := m.db[key]
 	if !ok {
 		return nil, errors.New(fmt.Sprintf("key[%s] not found", key))
@@ -22,6 +54,133 @@ func (m *memDB) Get(key string) ([]byte, error) {
 }
 
 func (m *memDB) Set(key string, value []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.set(key, value)
+	return nil
+}
+
+// set sets key without expiration, m.mu must be held
+func (m *memDB) set(key string, value []byte) {
+	m.db[key] = value
+	delete(m.expires, key)
+}
+
+// SetWithTTL sets key with a lifetime, the key is removed by sweeper after ttl
+func (m *memDB) SetWithTTL(key string, value []byte, ttl time.Duration) error {
+	m.once.Do(func() { go m.sweep() })
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	at := m.clk.Now().Add(ttl)
 	m.db[key] = value
+	m.expires[key] = at
+	heap.Push(&m.queue, &expiryItem{key: key, at: at})
 	return nil
 }
+
+func (m *memDB) TTL(key string) (time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.db[key]; !ok || m.expired(key) {
+		return 0, ErrKeyNotFound
+	}
+	if at, ok := m.expires[key]; ok {
+		return at.Sub(m.clk.Now()), nil
+	}
+	return NoExpiration, nil
+}
+
+func (m *memDB) BatchDelete(keys []string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	deleted := 0
+	for _, key := range keys {
+		if _, ok := m.db[key]; ok && !m.expired(key) {
+			deleted++
+		}
+		delete(m.db, key)
+		delete(m.expires, key)
+	}
+	return deleted, nil
+}
+
+// Close stops the sweeper
+func (m *memDB) Close() error {
+	m.once.Do(func() {}) // avoid sweeper starting after closed
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	select {
+	case <-m.stop:
+	default:
+		close(m.stop)
+	}
+	return nil
+}
+
+// expired checks if key is expired but not swept yet
+func (m *memDB) expired(key string) bool {
+	at, ok := m.expires[key]
+	return ok && !m.clk.Now().Before(at)
+}
+
+func (m *memDB) sweep() {
+	ticker := m.clk.NewTicker(SweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.Chan():
+			m.removeExpired()
+		}
+	}
+}
+
+// removeExpired pops all expired items from queue and deletes the keys which
+// are not reset after the item pushed
+func (m *memDB) removeExpired() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.clk.Now()
+	for m.queue.Len() > 0 && !now.Before(m.queue[0].at) {
+		item := heap.Pop(&m.queue).(*expiryItem)
+		if at, ok := m.expires[item.key]; ok && at.Equal(item.at) {
+			delete(m.db, item.key)
+			delete(m.expires, item.key)
+		}
+	}
+}
+
+type expiryItem struct {
+	key string
+	at  time.Time
+}
+
+// expiryQueue min heap of expiryItem ordered by expiration time
+type expiryQueue []*expiryItem
+
+func (q expiryQueue) Len() int { return len(q) }
+
+func (q expiryQueue) Less(i, j int) bool { return q[i].at.Before(q[j].at) }
+
+func (q expiryQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *expiryQueue) Push(x any) { *q = append(*q, x.(*expiryItem)) }
+
+func (q *expiryQueue) Pop() any {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return item
+}
@@ -0,0 +1,194 @@
+package kvdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	. "github.com/onsi/gomega"
+)
+
+func TestMemDB_TTL(t *testing.T) {
+	clk := clockwork.NewFakeClock()
+	m := NewMemDBWithClock(clk)
+	defer m.Close()
+
+	NewWithT(t).Expect(m.Set("persist", []byte("v"))).To(BeNil())
+	NewWithT(t).Expect(m.SetWithTTL("short", []byte("v"), time.Second)).To(BeNil())
+	NewWithT(t).Expect(m.SetWithTTL("long", []byte("v"), time.Minute)).To(BeNil())
+
+	ttl, err := m.TTL("persist")
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(ttl).To(Equal(NoExpiration))
+
+	ttl, err = m.TTL("short")
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(ttl).To(Equal(time.Second))
+
+	keys := func() int {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		return len(m.db)
+	}
+
+	clk.BlockUntil(1) // wait sweeper ticking
+	clk.Advance(time.Second)
+	NewWithT(t).Eventually(keys).Should(Equal(2))
+
+	_, err = m.Get("short")
+	NewWithT(t).Expect(err).NotTo(BeNil())
+	_, err = m.TTL("short")
+	NewWithT(t).Expect(err).To(Equal(ErrKeyNotFound))
+
+	t.Run("ResetBeforeExpired", func(t *testing.T) {
+		NewWithT(t).Expect(m.Set("long", []byte("v2"))).To(BeNil())
+		clk.Advance(time.Minute)
+		NewWithT(t).Consistently(keys, 300*time.Millisecond).Should(Equal(2))
+
+		v, err := m.Get("long")
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(v).To(Equal([]byte("v2")))
+	})
+}
+
+func TestMemDB_BatchDelete(t *testing.T) {
+	m := NewMemDB()
+	defer m.Close()
+
+	NewWithT(t).Expect(m.Set("a", []byte("v"))).To(BeNil())
+	NewWithT(t).Expect(m.Set("b", []byte("v"))).To(BeNil())
+
+	deleted, err := m.BatchDelete([]string{"a", "b", "c"})
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(deleted).To(Equal(2))
+
+	_, err = m.Get("a")
+	NewWithT(t).Expect(err).NotTo(BeNil())
+}
+
+func TestMemDB_ScanPrefix(t *testing.T) {
+	m := NewMemDB()
+	defer m.Close()
+
+	for _, k := range []string{"device_3", "device_1", "device_2", "other"} {
+		NewWithT(t).Expect(m.Set(k, []byte("v"))).To(BeNil())
+	}
+
+	keys, next, err := m.ScanPrefix("device_", "", 2)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(keys).To(Equal([]string{"device_1", "device_2"}))
+	NewWithT(t).Expect(next).To(Equal("device_2"))
+
+	keys, next, err = m.ScanPrefix("device_", next, 2)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(keys).To(Equal([]string{"device_3"}))
+	NewWithT(t).Expect(next).To(BeEmpty())
+}
+
+func TestMemDB_SetWithQuota(t *testing.T) {
+	m := NewMemDB()
+	defer m.Close()
+
+	NewWithT(t).Expect(m.SetWithQuota("k1", []byte("12345678"), 16)).To(BeNil())
+	NewWithT(t).Expect(m.SetWithQuota("k2", []byte("12345678"), 16)).To(Equal(ErrQuotaExceeded))
+	NewWithT(t).Expect(m.SetWithQuota("k1", []byte("1234"), 16)).To(BeNil())
+	NewWithT(t).Expect(m.SetWithQuota("k2", []byte("1234"), 16)).To(BeNil())
+
+	t.Run("Concurrent", func(t *testing.T) {
+		m := NewMemDB()
+		defer m.Close()
+
+		wg := sync.WaitGroup{}
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_ = m.SetWithQuota(fmt.Sprintf("k%02d", i), []byte("12345678"), 100)
+			}(i)
+		}
+		wg.Wait()
+
+		used := 0
+		for k, v := range m.db {
+			used += len(k) + len(v)
+		}
+		NewWithT(t).Expect(used <= 100).To(BeTrue())
+	})
+}
+
+func TestMemDB_PipelineWithQuota(t *testing.T) {
+	m := NewMemDB()
+	defer m.Close()
+
+	err := m.PipelineWithQuota([]KVCommand{
+		{Key: "a", Value: []byte("1234")},
+		{Key: "b", Value: []byte("1234567890")},
+		{Key: "c", Value: []byte("v"), TTL: time.Minute},
+	}, 8)
+	perr := &PipelineError{}
+	NewWithT(t).Expect(errors.As(err, &perr)).To(BeTrue())
+	NewWithT(t).Expect(perr.Errors).To(HaveLen(2))
+	NewWithT(t).Expect(perr.Errors[1]).To(Equal(ErrQuotaExceeded))
+	NewWithT(t).Expect(perr.Errors[2]).To(Equal(ErrTTLWithQuota))
+
+	v, err := m.Get("a")
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(v).To(Equal([]byte("1234")))
+
+	_, err = m.TTL("c")
+	NewWithT(t).Expect(err).To(Equal(ErrKeyNotFound))
+
+	err = m.Pipeline([]KVCommand{{Key: "c", Value: []byte("v"), TTL: time.Minute}})
+	NewWithT(t).Expect(err).To(BeNil())
+	ttl, err := m.TTL("c")
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(ttl > 0).To(BeTrue())
+}
+
+func TestMemDB_CompareAndSwap(t *testing.T) {
+	m := NewMemDB()
+	defer m.Close()
+
+	swapped, err := m.CompareAndSwap("k", nil, []byte("idle"))
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(swapped).To(BeTrue())
+
+	swapped, _ = m.CompareAndSwap("k", nil, []byte("running"))
+	NewWithT(t).Expect(swapped).To(BeFalse())
+	swapped, _ = m.CompareAndSwap("k", []byte("stopped"), []byte("running"))
+	NewWithT(t).Expect(swapped).To(BeFalse())
+	swapped, _ = m.CompareAndSwap("k", []byte("idle"), []byte("running"))
+	NewWithT(t).Expect(swapped).To(BeTrue())
+
+	v, _ := m.Get("k")
+	NewWithT(t).Expect(v).To(Equal([]byte("running")))
+}
+
+func TestMemDB_PubSub(t *testing.T) {
+	m := NewMemDB()
+	defer m.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	received := make(chan []byte, 1)
+	NewWithT(t).Expect(m.Subscribe(ctx, "ch", func(msg []byte) { received <- msg })).To(BeNil())
+
+	n, err := m.Publish("other", []byte("hello"))
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(n).To(Equal(0))
+
+	n, err = m.Publish("ch", []byte("hello"))
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(n).To(Equal(1))
+	NewWithT(t).Eventually(received).Should(Receive(Equal([]byte("hello"))))
+
+	cancel()
+	NewWithT(t).Eventually(func() int {
+		m.ps.mu.Lock()
+		defer m.ps.mu.Unlock()
+		return len(m.ps.subs)
+	}).Should(Equal(0))
+}
@@ -0,0 +1,135 @@
+package kvdb
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// KVCommand a SET command queued in pipeline, zero TTL means no expiration
+type KVCommand struct {
+	Key   string
+	Value []byte
+	TTL   time.Duration
+}
+
+// PipelineError records the commands failed in pipeline, keyed by the index
+// of command. the other commands are applied
+type PipelineError struct {
+	Errors map[int]error
+}
+
+func (e *PipelineError) Error() string {
+	indexes := make([]int, 0, len(e.Errors))
+	for i := range e.Errors {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	msgs := make([]string, 0, len(indexes))
+	for _, i := range indexes {
+		msgs = append(msgs, fmt.Sprintf("#%d: %v", i, e.Errors[i]))
+	}
+	return "pipeline commands failed: " + strings.Join(msgs, "; ")
+}
+
+// Pipeline sets keys through a single redis pipeline without quota limit
+func (r *RedisDB) Pipeline(cmds []KVCommand) error {
+	return r.PipelineWithQuota(cmds, 0)
+}
+
+// PipelineWithQuota queues all commands and executes them in one round trip.
+// commands without ttl are set as SetWithQuota does, and commands with ttl are
+// set as standalone keys by `SET key value PX ttl` after the field of the same
+// key is deleted from namespace hash. the standalone keys are not
+// counted in usage, so commands with ttl fail with ErrTTLWithQuota if limit is
+// positive. the commands are not applied atomically, a *PipelineError is
+// returned if some of them failed.
+func (r *RedisDB) PipelineWithQuota(cmds []KVCommand, limit int64) error {
+	if len(cmds) == 0 {
+		return nil
+	}
+	conn := r.db.Get()
+	defer conn.Close()
+
+	for _, s := range []*redis.Script{setScript, ttlSetScript} {
+		if err := s.Load(conn); err != nil {
+			return err
+		}
+	}
+	failed := make(map[int]error)
+	for i, cmd := range cmds {
+		var err error
+		if cmd.TTL > 0 && limit > 0 {
+			failed[i] = ErrTTLWithQuota
+			continue
+		}
+		if cmd.TTL > 0 {
+			err = ttlSetScript.SendHash(conn, r.db.Prefix, r.usageKey(), r.db.Key(cmd.Key), cmd.Key, cmd.Value, cmd.TTL.Milliseconds())
+		} else {
+			err = setScript.SendHash(conn, r.db.Prefix, r.usageKey(), r.db.Key(cmd.Key), cmd.Key, cmd.Value, limit)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := conn.Flush(); err != nil {
+		return err
+	}
+
+	for i, cmd := range cmds {
+		if _, ok := failed[i]; ok {
+			continue // not sent
+		}
+		reply, err := conn.Receive()
+		if err == nil && cmd.TTL <= 0 {
+			if used, _ := redis.Int64(reply, nil); used < 0 {
+				err = ErrQuotaExceeded
+			}
+		}
+		if err != nil {
+			if _, ok := err.(redis.Error); !ok {
+				return err // connection broken, the replies left are lost
+			}
+			failed[i] = err
+		}
+	}
+	if len(failed) > 0 {
+		return &PipelineError{Errors: failed}
+	}
+	return nil
+}
+
+// Pipeline sets keys one by one
+func (m *memDB) Pipeline(cmds []KVCommand) error {
+	return m.PipelineWithQuota(cmds, 0)
+}
+
+// PipelineWithQuota sets keys one by one, a *PipelineError is returned if some
+// of them failed
+func (m *memDB) PipelineWithQuota(cmds []KVCommand, limit int64) error {
+	failed := make(map[int]error)
+	for i, cmd := range cmds {
+		var err error
+		switch {
+		case cmd.TTL > 0 && limit > 0:
+			err = ErrTTLWithQuota
+		case cmd.TTL > 0:
+			err = m.SetWithTTL(cmd.Key, cmd.Value, cmd.TTL)
+		case limit > 0:
+			err = m.SetWithQuota(cmd.Key, cmd.Value, limit)
+		default:
+			err = m.Set(cmd.Key, cmd.Value)
+		}
+		if err != nil {
+			failed[i] = err
+		}
+	}
+	if len(failed) > 0 {
+		return &PipelineError{Errors: failed}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package kvdb
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// Channel returns the redis channel of pubsub channel, channels are scoped by
+// the namespace of project
+func (r *RedisDB) Channel(channel string) string {
+	return r.ns.Key("pubsub:" + channel)
+}
+
+// Publish PUBLISH channel msg, returns the number of subscribers received
+func (r *RedisDB) Publish(channel string, msg []byte) (int, error) {
+	conn := r.db.Get()
+	defer conn.Close()
+
+	return redis.Int(conn.Do("PUBLISH", r.Channel(channel), msg))
+}
+
+// Subscribe calls fn with each message published to channel until ctx is
+// done, the channel is unsubscribed before the connection closed
+func (r *RedisDB) Subscribe(ctx context.Context, channel string, fn func(msg []byte)) error {
+	conn, err := r.db.Dial()
+	if err != nil {
+		return err
+	}
+	psc := redis.PubSubConn{Conn: conn}
+	if err = psc.Subscribe(r.Channel(channel)); err != nil {
+		_ = conn.Close()
+		return err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = psc.Unsubscribe()
+			_ = psc.Close() // unblock receiving
+		case <-done:
+		}
+	}()
+
+	go func() {
+		defer close(done)
+		defer psc.Close()
+		for {
+			switch v := psc.ReceiveWithTimeout(0).(type) {
+			case redis.Message:
+				fn(v.Data)
+			case error:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// memSubscriberBufferSize the messages buffered for a memory db subscriber,
+// the messages published when buffer is full are dropped
+const memSubscriberBufferSize = 64
+
+// memPubSub channels of memory db, messages are delivered in process to the
+// subscribers of the same db
+type memPubSub struct {
+	mu   sync.Mutex
+	subs map[string]map[chan []byte]struct{}
+}
+
+// Publish delivers msg to subscribers of channel without blocking, returns the
+// number of subscribers received
+func (m *memDB) Publish(channel string, msg []byte) (int, error) {
+	m.ps.mu.Lock()
+	defer m.ps.mu.Unlock()
+
+	n := 0
+	for ch := range m.ps.subs[channel] {
+		select {
+		case ch <- msg:
+			n++
+		default:
+		}
+	}
+	return n, nil
+}
+
+// Subscribe calls fn with each message published to channel until ctx is done
+func (m *memDB) Subscribe(ctx context.Context, channel string, fn func(msg []byte)) error {
+	ch := make(chan []byte, memSubscriberBufferSize)
+
+	m.ps.mu.Lock()
+	if m.ps.subs == nil {
+		m.ps.subs = make(map[string]map[chan []byte]struct{})
+	}
+	if m.ps.subs[channel] == nil {
+		m.ps.subs[channel] = make(map[chan []byte]struct{})
+	}
+	m.ps.subs[channel][ch] = struct{}{}
+	m.ps.mu.Unlock()
+
+	go func() {
+		defer func() {
+			m.ps.mu.Lock()
+			delete(m.ps.subs[channel], ch)
+			if len(m.ps.subs[channel]) == 0 {
+				delete(m.ps.subs, channel)
+			}
+			m.ps.mu.Unlock()
+		}()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg := <-ch:
+				fn(msg)
+			}
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,122 @@
+package kvdb
+
+import (
+	"errors"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+var (
+	ErrQuotaExceeded = errors.New("kv storage quota exceeded")
+	// ErrTTLWithQuota keys with ttl are stored out of the quota counter, so
+	// they are not allowed when quota is limited
+	ErrTTLWithQuota = errors.New("kv set with ttl is not allowed when storage quota is limited")
+)
+
+// usageKey the counter of bytes(key and value) stored in hash, it is kept in
+// the namespace of project to count the usage of each project
+const usageKey = "__kv_usage__"
+
+// setScript sets field and adjusts the usage counter atomically, returns -1
+// if the usage would exceed the limit. limit not positive means unlimited. the
+// standalone key set with ttl before is deleted, which would report a stale ttl
+var setScript = redis.NewScript(3, `
+local old = 0
+if redis.call('HEXISTS', KEYS[1], ARGV[1]) == 1 then
+	old = #ARGV[1] + redis.call('HSTRLEN', KEYS[1], ARGV[1])
+end
+local delta = #ARGV[1] + #ARGV[2] - old
+local limit = tonumber(ARGV[3])
+if limit > 0 and delta > 0 and tonumber(redis.call('GET', KEYS[2]) or 0) + delta > limit then
+	return -1
+end
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+redis.call('DEL', KEYS[3])
+return redis.call('INCRBY', KEYS[2], delta)
+`)
+
+// ttlSetScript sets standalone key with ttl in milliseconds, and deletes the
+// field of the same key and decreases the usage counter atomically, otherwise
+// the field shadows the standalone key when reading
+var ttlSetScript = redis.NewScript(3, `
+if redis.call('HEXISTS', KEYS[1], ARGV[1]) == 1 then
+	redis.call('DECRBY', KEYS[2], #ARGV[1] + redis.call('HSTRLEN', KEYS[1], ARGV[1]))
+	redis.call('HDEL', KEYS[1], ARGV[1])
+end
+return redis.call('SET', KEYS[3], ARGV[2], 'PX', ARGV[3])
+`)
+
+// deleteScript deletes fields and the standalone keys set with ttl, and
+// decreases the usage counter atomically. KEYS[1] is the namespace hash,
+// KEYS[2] is the usage counter and KEYS[2+i] is the standalone key of field
+// ARGV[i]. returns the count of keys deleted from either representation
+var deleteScript = redis.NewScript(-1, `
+local freed, n = 0, 0
+for i, k in ipairs(ARGV) do
+	local existed = false
+	if redis.call('HEXISTS', KEYS[1], k) == 1 then
+		freed = freed + #k + redis.call('HSTRLEN', KEYS[1], k)
+		redis.call('HDEL', KEYS[1], k)
+		existed = true
+	end
+	if redis.call('DEL', KEYS[2 + i]) == 1 then
+		existed = true
+	end
+	if existed then
+		n = n + 1
+	end
+end
+if freed > 0 then
+	redis.call('DECRBY', KEYS[2], freed)
+end
+return n
+`)
+
+// SetWithQuota sets key and returns ErrQuotaExceeded if the total bytes set by
+// project would exceed limit after setting. limit not positive means unlimited
+func (r *RedisDB) SetWithQuota(key string, value []byte, limit int64) error {
+	conn := r.db.Get()
+	defer conn.Close()
+
+	used, err := redis.Int64(setScript.Do(conn, r.db.Prefix, r.usageKey(), r.db.Key(key), key, value, limit))
+	if err != nil {
+		return err
+	}
+	if used < 0 {
+		return ErrQuotaExceeded
+	}
+	return nil
+}
+
+// Usage returns the total bytes of keys and values stored by project
+func (r *RedisDB) Usage() (int64, error) {
+	conn := r.db.Get()
+	defer conn.Close()
+
+	used, err := redis.Int64(conn.Do("GET", r.usageKey()))
+	if err == redis.ErrNil {
+		return 0, nil
+	}
+	return used, err
+}
+
+// SetWithQuota sets key and returns ErrQuotaExceeded if the total bytes of
+// db would exceed limit after setting
+func (m *memDB) SetWithQuota(key string, value []byte, limit int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if limit > 0 {
+		used := int64(len(key) + len(value))
+		for k, v := range m.db {
+			if k != key && !m.expired(k) {
+				used += int64(len(k) + len(v))
+			}
+		}
+		if used > limit {
+			return ErrQuotaExceeded
+		}
+	}
+	m.set(key, value)
+	return nil
+}
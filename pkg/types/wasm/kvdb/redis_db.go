@@ -2,7 +2,9 @@ package kvdb
 
 import (
 	"context"
+	"errors"
 	"strconv"
+	"time"
 
 	"github.com/gomodule/redigo/redis"
 
@@ -11,21 +13,40 @@ import (
 	"github.com/machinefi/w3bstream/pkg/depends/x/misc/must"
 )
 
+var ErrKeyNotFound = errors.New("key not found")
+
+// NoExpiration is the ttl of key without expiration
+const NoExpiration time.Duration = -1
+
 type RedisDB struct {
 	db *confredis.Redis
+	// ns the namespace of project, the usage counter and pubsub channels are
+	// kept in it. it is db if the store is not created for a project
+	ns *confredis.Redis
 }
 
 func NewRedisDB(d *confredis.Redis) *RedisDB {
-	return &RedisDB{db: d}
+	return &RedisDB{db: d, ns: d}
+}
+
+// NewProjectRedisDB creates redis db for project. the entries are stored in
+// the hash and keys of d shared by all projects, as NewRedisDB does, and only
+// the usage counter and pubsub channels are in the namespace of project
+func NewProjectRedisDB(d *confredis.Redis, projectID string) *RedisDB {
+	return &RedisDB{db: d, ns: d.WithPrefix(projectID)}
 }
 
+// Get HGET prefix key, falls back to GET key for the entries set with ttl
 func (r *RedisDB) Get(key string) ([]byte, error) {
 	var args []interface{}
 	args = append(args, r.db.Prefix, key)
 	result, err := r.db.Exec(&confredis.Cmd{Name: "HGET", Args: args})
-	if err != nil || result == nil {
+	if err != nil {
 		return nil, err
 	}
+	if result == nil {
+		return r.GetKey(key)
+	}
 	val, err := redis.Bytes(result, nil)
 	if err != nil {
 		return nil, err
@@ -33,13 +54,60 @@ func (r *RedisDB) Get(key string) ([]byte, error) {
 	return val, nil
 }
 
+// Set sets key without quota limit, the usage is still counted
 func (r *RedisDB) Set(key string, value []byte) error {
-	var args []interface{}
-	args = append(args, r.db.Prefix, key, string(value))
-	if _, err := r.db.Exec(&confredis.Cmd{Name: "HSET", Args: args}); err != nil {
-		return err
+	return r.SetWithQuota(key, value, 0)
+}
+
+// TTL PTTL key, falls back to HEXISTS for the entries set by Set which
+// never expire
+func (r *RedisDB) TTL(key string) (time.Duration, error) {
+	result, err := r.db.Exec(&confredis.Cmd{Name: "PTTL", Args: []interface{}{r.db.Key(key)}})
+	if err != nil {
+		return 0, err
+	}
+	ms, err := redis.Int64(result, nil)
+	if err != nil {
+		return 0, err
+	}
+	switch ms {
+	case -1:
+		return NoExpiration, nil
+	case -2:
+		result, err = r.db.Exec(&confredis.Cmd{Name: "HEXISTS", Args: []interface{}{r.db.Prefix, key}})
+		if err != nil {
+			return 0, err
+		}
+		exists, err := redis.Bool(result, nil)
+		if err != nil {
+			return 0, err
+		}
+		if exists {
+			return NoExpiration, nil
+		}
+		return 0, ErrKeyNotFound
+	default:
+		return time.Duration(ms) * time.Millisecond, nil
 	}
-	return nil
+}
+
+// BatchDelete HDEL prefix key [key ...] and DEL the standalone keys set with
+// ttl, and decreases the usage
+func (r *RedisDB) BatchDelete(keys []string) (int, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	args := []interface{}{len(keys) + 2, r.db.Prefix, r.usageKey()}
+	for _, key := range keys {
+		args = append(args, r.db.Key(key))
+	}
+	for _, key := range keys {
+		args = append(args, key)
+	}
+	conn := r.db.Get()
+	defer conn.Close()
+
+	return redis.Int(deleteScript.Do(conn, args...))
 }
 
 func (r *RedisDB) IncrBy(key string, value []byte) ([]byte, error) {
@@ -100,6 +168,9 @@ func (r *RedisDB) DelKey(key string) error {
 	return nil
 }
 
+// usageKey returns the key of usage counter
+func (r *RedisDB) usageKey() string { return r.ns.Key(usageKey) }
+
 type redisDBKey struct{}
 
 func WithRedisDBKeyContext(redisDB *RedisDB) func(context.Context) context.Context {
@@ -0,0 +1,26 @@
+package kvdb
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	confredis "github.com/machinefi/w3bstream/pkg/depends/conf/redis"
+)
+
+func TestNewProjectRedisDB(t *testing.T) {
+	ep := &confredis.Redis{Prefix: "test:srv-applet-mgr:"}
+
+	r1 := NewProjectRedisDB(ep, "1001")
+	r2 := NewProjectRedisDB(ep, "1002")
+
+	NewWithT(t).Expect(ep.Prefix).To(Equal("test:srv-applet-mgr:"))
+	// entries are kept in the storage layout of NewRedisDB
+	NewWithT(t).Expect(r1.db.Prefix).To(Equal(ep.Prefix))
+	NewWithT(t).Expect(r1.db.Key("k")).To(Equal(NewRedisDB(ep).db.Key("k")))
+	NewWithT(t).Expect(r1.db.Prefix).To(Equal(r2.db.Prefix))
+	// usage counters and pubsub channels are per project
+	NewWithT(t).Expect(r1.usageKey()).NotTo(Equal(r2.usageKey()))
+	NewWithT(t).Expect(r1.usageKey()).NotTo(Equal(NewRedisDB(ep).usageKey()))
+	NewWithT(t).Expect(r1.Channel("c")).NotTo(Equal(r2.Channel("c")))
+}
@@ -0,0 +1,73 @@
+package kvdb
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gomodule/redigo/redis"
+
+	confredis "github.com/machinefi/w3bstream/pkg/depends/conf/redis"
+)
+
+// DefaultScanCount the count hint of scanning if count is not positive
+const DefaultScanCount = 100
+
+// globEscaper escapes the special characters of redis glob-style pattern
+var globEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
+
+// ScanPrefix HSCAN prefix cursor MATCH prefix* COUNT count, empty cursor means
+// scanning from the beginning and empty nextCursor means scanning is done.
+// count is a hint, the number of keys returned may be different from count.
+func (r *RedisDB) ScanPrefix(prefix, cursor string, count int) ([]string, string, error) {
+	if cursor == "" {
+		cursor = "0"
+	}
+	if count <= 0 {
+		count = DefaultScanCount
+	}
+	values, err := redis.Values(r.db.Exec(&confredis.Cmd{
+		Name: "HSCAN",
+		Args: []interface{}{r.db.Prefix, cursor, "MATCH", globEscaper.Replace(prefix) + "*", "COUNT", count},
+	}))
+	if err != nil {
+		return nil, "", err
+	}
+	var (
+		next  int64
+		pairs []string
+	)
+	if _, err = redis.Scan(values, &next, &pairs); err != nil {
+		return nil, "", err
+	}
+	keys := make([]string, 0, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		keys = append(keys, pairs[i])
+	}
+	if next == 0 {
+		return keys, "", nil
+	}
+	return keys, strconv.FormatInt(next, 10), nil
+}
+
+// ScanPrefix scans keys in lexical order, the cursor is the last key returned
+func (m *memDB) ScanPrefix(prefix, cursor string, count int) ([]string, string, error) {
+	if count <= 0 {
+		count = DefaultScanCount
+	}
+
+	m.mu.Lock()
+	matched := make([]string, 0)
+	for key := range m.db {
+		if strings.HasPrefix(key, prefix) && key > cursor && !m.expired(key) {
+			matched = append(matched, key)
+		}
+	}
+	m.mu.Unlock()
+
+	sort.Strings(matched)
+	if len(matched) <= count {
+		return matched, "", nil
+	}
+	return matched[:count], matched[count-1], nil
+}
@@ -0,0 +1,142 @@
+package kvdb
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+
+	confredis "github.com/machinefi/w3bstream/pkg/depends/conf/redis"
+)
+
+// NamespaceStats storage usage of kv namespace
+type NamespaceStats struct {
+	Namespace  string `json:"namespace"`
+	KeyCount   int64  `json:"keyCount"`
+	TotalBytes int64  `json:"totalBytes"`
+	// UsedBytes bytes of keys and values stored by project, which is limited
+	// by storage quota
+	UsedBytes int64 `json:"usedBytes"`
+}
+
+// StatsInterval the minimum interval of scanning a redis namespace, the stats
+// is cached in the interval to prevent SCAN blocking redis server
+var StatsInterval = 10 * time.Second
+
+// statsScanCount COUNT hint of each SCAN
+const statsScanCount = 100
+
+type cachedStats struct {
+	stats *NamespaceStats
+	at    time.Time
+}
+
+var (
+	statsMu    sync.Mutex
+	statsCache = map[string]*cachedStats{}
+)
+
+// Stats returns the key count and memory usage of the kv namespace, including
+// the hash entries set by Set and the keys set by SetKey. the namespace is
+// shared by projects, the bytes stored by project are reported as UsedBytes.
+// the usage counters are not counted.
+func (r *RedisDB) Stats() (*NamespaceStats, error) {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+
+	now := time.Now()
+	if c, ok := statsCache[r.ns.Prefix]; ok && now.Sub(c.at) < StatsInterval {
+		return c.stats, nil
+	}
+
+	stats, err := r.scanStats()
+	if err != nil {
+		return nil, err
+	}
+	for ns, c := range statsCache {
+		if now.Sub(c.at) >= StatsInterval {
+			delete(statsCache, ns)
+		}
+	}
+	statsCache[r.ns.Prefix] = &cachedStats{stats: stats, at: now}
+	return stats, nil
+}
+
+func (r *RedisDB) scanStats() (*NamespaceStats, error) {
+	used, err := r.Usage()
+	if err != nil {
+		return nil, err
+	}
+	stats := &NamespaceStats{Namespace: r.db.Prefix, UsedBytes: used}
+
+	n, err := redis.Int64(r.db.Exec(&confredis.Cmd{Name: "HLEN", Args: []interface{}{r.db.Prefix}}))
+	if err != nil {
+		return nil, err
+	}
+	if n > 0 {
+		size, err := r.memoryUsage(r.db.Prefix)
+		if err != nil {
+			return nil, err
+		}
+		stats.KeyCount, stats.TotalBytes = n, size
+	}
+
+	var (
+		cursor  = int64(0)
+		pattern = globEscaper.Replace(r.db.Key("")) + "*"
+	)
+	for {
+		values, err := redis.Values(r.db.Exec(&confredis.Cmd{
+			Name: "SCAN",
+			Args: []interface{}{cursor, "MATCH", pattern, "COUNT", statsScanCount},
+		}))
+		if err != nil {
+			return nil, err
+		}
+		var keys []string
+		if _, err = redis.Scan(values, &cursor, &keys); err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			if strings.HasSuffix(key, ":"+usageKey) {
+				continue
+			}
+			size, err := r.memoryUsage(key)
+			if err != nil {
+				return nil, err
+			}
+			stats.KeyCount++
+			stats.TotalBytes += size
+		}
+		if cursor == 0 {
+			return stats, nil
+		}
+	}
+}
+
+// memoryUsage MEMORY USAGE key, returns 0 if key not exists
+func (r *RedisDB) memoryUsage(key string) (int64, error) {
+	size, err := redis.Int64(r.db.Exec(&confredis.Cmd{Name: "MEMORY", Args: []interface{}{"USAGE", key}}))
+	if err == redis.ErrNil {
+		return 0, nil
+	}
+	return size, err
+}
+
+// Stats returns the key count and value bytes of memory db
+func (m *memDB) Stats() (*NamespaceStats, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := &NamespaceStats{}
+	for k, v := range m.db {
+		if m.expired(k) {
+			continue
+		}
+		stats.KeyCount++
+		stats.TotalBytes += int64(len(k) + len(v))
+	}
+	stats.UsedBytes = stats.TotalBytes
+	return stats, nil
+}
@@ -0,0 +1,101 @@
+package kvdb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gomodule/redigo/redis"
+
+	confredis "github.com/machinefi/w3bstream/pkg/depends/conf/redis"
+)
+
+var ErrKeyspaceNotificationDisabled = errors.New("keyspace notification is disabled, `notify-keyspace-events` should contain `K` and `A` or `h$gx`")
+
+// Watch calls fn with the value of key each time it is changed until ctx is
+// done, nil value means the key is deleted. the keys are fields of namespace
+// hash or standalone keys of namespace if set with ttl, so it subscribes the
+// keyspace notifications of both and compares the value of key after each
+// notification, the notifications of the other fields of hash are dropped as
+// the value is not changed. onEnd is called if the watch is ended by error
+// before ctx done.
+func (r *RedisDB) Watch(ctx context.Context, key string, fn func(value []byte), onEnd func(err error)) error {
+	if err := r.checkKeyspaceNotification(); err != nil {
+		return err
+	}
+
+	last, err := r.Get(key)
+	if err != nil {
+		return err
+	}
+
+	conn, err := r.db.Dial()
+	if err != nil {
+		return err
+	}
+	psc := redis.PubSubConn{Conn: conn}
+	if err = psc.Subscribe(
+		fmt.Sprintf("__keyspace@%d__:%s", r.db.DB, r.db.Prefix),
+		fmt.Sprintf("__keyspace@%d__:%s", r.db.DB, r.db.Key(key)),
+	); err != nil {
+		_ = conn.Close()
+		return err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = psc.Close() // unblock receiving
+		case <-done:
+		}
+	}()
+
+	go func() {
+		defer close(done)
+		defer psc.Close()
+		for {
+			switch v := psc.ReceiveWithTimeout(0).(type) {
+			case redis.Message:
+				value, err := r.Get(key)
+				if err != nil {
+					continue
+				}
+				if bytes.Equal(value, last) && (value == nil) == (last == nil) {
+					continue
+				}
+				last = value
+				fn(value)
+			case error:
+				if ctx.Err() == nil && onEnd != nil {
+					onEnd(v)
+				}
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// checkKeyspaceNotification checks if keyspace events of hash, string, generic
+// and expired are notified
+func (r *RedisDB) checkKeyspaceNotification() error {
+	result, err := r.db.Exec(&confredis.Cmd{Name: "CONFIG", Args: []interface{}{"GET", "notify-keyspace-events"}})
+	if err != nil {
+		return err
+	}
+	kv, err := redis.Strings(result, nil)
+	if err != nil {
+		return err
+	}
+	if len(kv) != 2 || !strings.Contains(kv[1], "K") {
+		return ErrKeyspaceNotificationDisabled
+	}
+	if !strings.Contains(kv[1], "A") && !(strings.Contains(kv[1], "h") &&
+		strings.Contains(kv[1], "$") && strings.Contains(kv[1], "g") && strings.Contains(kv[1], "x")) {
+		return ErrKeyspaceNotificationDisabled
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package sql_util
+
+import (
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+)
+
+// TableChange row change decoded from pgoutput logical replication messages.
+// column values are in postgres text format, nil means NULL. Old contains the
+// replica identity columns only unless the table is `REPLICA IDENTITY FULL`
+type TableChange struct {
+	Op     string             `json:"op"`
+	Schema string             `json:"schema"`
+	Table  string             `json:"table"`
+	Old    map[string]*string `json:"old,omitempty"`
+	New    map[string]*string `json:"new,omitempty"`
+}
+
+const (
+	TableChangeOpInsert = "INSERT"
+	TableChangeOpUpdate = "UPDATE"
+	TableChangeOpDelete = "DELETE"
+)
+
+type pgRelation struct {
+	schema string
+	table  string
+	cols   []string
+}
+
+// PgOutputDecoder decodes pgoutput messages of protocol version 1. relation
+// messages are cached by decoder, so one decoder should be used per slot
+type PgOutputDecoder struct {
+	relations map[uint32]*pgRelation
+}
+
+func NewPgOutputDecoder() *PgOutputDecoder {
+	return &PgOutputDecoder{relations: make(map[uint32]*pgRelation)}
+}
+
+// Decode decodes a pgoutput message, it returns nil change if message is not
+// a row change, eg: BEGIN, COMMIT and RELATION
+func (d *PgOutputDecoder) Decode(msg []byte) (*TableChange, error) {
+	if len(msg) == 0 {
+		return nil, errors.New("empty message")
+	}
+	r := &pgReader{buf: msg[1:]}
+
+	switch msg[0] {
+	case 'R':
+		rel := &pgRelation{}
+		id := r.uint32()
+		rel.schema = r.string()
+		rel.table = r.string()
+		r.skip(1) // replica identity setting
+		n := int(r.uint16())
+		for i := 0; i < n && r.err == nil; i++ {
+			r.skip(1) // flags
+			rel.cols = append(rel.cols, r.string())
+			r.skip(8) // type oid and modifier
+		}
+		if r.err != nil {
+			return nil, r.err
+		}
+		d.relations[id] = rel
+		return nil, nil
+	case 'I':
+		rel, err := d.relation(r.uint32())
+		if err != nil {
+			return nil, err
+		}
+		c := &TableChange{Op: TableChangeOpInsert, Schema: rel.schema, Table: rel.table}
+		if r.byte() != 'N' {
+			return nil, errors.New("malformed insert message")
+		}
+		c.New = r.tuple(rel)
+		return c, r.err
+	case 'U':
+		rel, err := d.relation(r.uint32())
+		if err != nil {
+			return nil, err
+		}
+		c := &TableChange{Op: TableChangeOpUpdate, Schema: rel.schema, Table: rel.table}
+		kind := r.byte()
+		if kind == 'K' || kind == 'O' {
+			c.Old = r.tuple(rel)
+			kind = r.byte()
+		}
+		if kind != 'N' {
+			return nil, errors.New("malformed update message")
+		}
+		c.New = r.tuple(rel)
+		return c, r.err
+	case 'D':
+		rel, err := d.relation(r.uint32())
+		if err != nil {
+			return nil, err
+		}
+		c := &TableChange{Op: TableChangeOpDelete, Schema: rel.schema, Table: rel.table}
+		if kind := r.byte(); kind != 'K' && kind != 'O' {
+			return nil, errors.New("malformed delete message")
+		}
+		c.Old = r.tuple(rel)
+		return c, r.err
+	default:
+		return nil, nil
+	}
+}
+
+func (d *PgOutputDecoder) relation(id uint32) (*pgRelation, error) {
+	rel, ok := d.relations[id]
+	if !ok {
+		return nil, errors.Errorf("unknown relation %d", id)
+	}
+	return rel, nil
+}
+
+type pgReader struct {
+	buf []byte
+	err error
+}
+
+func (r *pgReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if len(r.buf) < n {
+		r.err = errors.New("unexpected end of message")
+		return nil
+	}
+	b := r.buf[:n]
+	r.buf = r.buf[n:]
+	return b
+}
+
+func (r *pgReader) skip(n int) { r.next(n) }
+
+func (r *pgReader) byte() byte {
+	if b := r.next(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *pgReader) uint16() uint16 {
+	if b := r.next(2); b != nil {
+		return binary.BigEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *pgReader) uint32() uint32 {
+	if b := r.next(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *pgReader) string() string {
+	if r.err != nil {
+		return ""
+	}
+	for i, b := range r.buf {
+		if b == 0 {
+			s := string(r.buf[:i])
+			r.buf = r.buf[i+1:]
+			return s
+		}
+	}
+	r.err = errors.New("unterminated string")
+	return ""
+}
+
+// tuple reads TupleData, unchanged toasted values are omitted
+func (r *pgReader) tuple(rel *pgRelation) map[string]*string {
+	n := int(r.uint16())
+	if r.err == nil && n > len(rel.cols) {
+		r.err = errors.Errorf("tuple has %d columns, relation %s has %d", n, rel.table, len(rel.cols))
+	}
+	values := make(map[string]*string, n)
+	for i := 0; i < n && r.err == nil; i++ {
+		switch kind := r.byte(); kind {
+		case 'n':
+			values[rel.cols[i]] = nil
+		case 'u':
+		case 't':
+			v := string(r.next(int(r.uint32())))
+			values[rel.cols[i]] = &v
+		default:
+			r.err = errors.Errorf("unknown tuple data kind %q", kind)
+		}
+	}
+	return values
+}
@@ -0,0 +1,87 @@
+package sql_util_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/machinefi/w3bstream/pkg/types/wasm/sql_util"
+)
+
+type pgMsg []byte
+
+func (m pgMsg) byte(b byte) pgMsg { return append(m, b) }
+
+func (m pgMsg) uint16(v uint16) pgMsg {
+	b := make([]byte, 2)
+	binary.BigEndian.PutUint16(b, v)
+	return append(m, b...)
+}
+
+func (m pgMsg) uint32(v uint32) pgMsg {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, v)
+	return append(m, b...)
+}
+
+func (m pgMsg) string(s string) pgMsg { return append(append(m, s...), 0) }
+
+func (m pgMsg) text(s string) pgMsg { return append(m.byte('t').uint32(uint32(len(s))), s...) }
+
+func TestPgOutputDecoder_Decode(t *testing.T) {
+	rel := pgMsg{'R'}.uint32(16384).string("public").string("t_demo").byte('d').uint16(2)
+	rel = rel.byte(1).string("f_id").uint32(23).uint32(0xFFFFFFFF)
+	rel = rel.byte(0).string("f_name").uint32(25).uint32(0xFFFFFFFF)
+
+	t.Run("UnknownRelation", func(t *testing.T) {
+		d := sql_util.NewPgOutputDecoder()
+		_, err := d.Decode(pgMsg{'I'}.uint32(16384).byte('N').uint16(1).text("1"))
+		NewWithT(t).Expect(err).NotTo(BeNil())
+	})
+
+	d := sql_util.NewPgOutputDecoder()
+	c, err := d.Decode(rel)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(c).To(BeNil())
+
+	t.Run("Begin", func(t *testing.T) {
+		c, err := d.Decode(pgMsg{'B'}.uint32(0).uint32(1).uint32(0).uint32(0).uint32(7))
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(c).To(BeNil())
+	})
+
+	t.Run("Insert", func(t *testing.T) {
+		c, err := d.Decode(pgMsg{'I'}.uint32(16384).byte('N').uint16(2).text("1").byte('n'))
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(c.Op).To(Equal(sql_util.TableChangeOpInsert))
+		NewWithT(t).Expect(c.Schema + "." + c.Table).To(Equal("public.t_demo"))
+		NewWithT(t).Expect(c.Old).To(BeNil())
+		NewWithT(t).Expect(*c.New["f_id"]).To(Equal("1"))
+		NewWithT(t).Expect(c.New).To(HaveKeyWithValue("f_name", BeNil()))
+	})
+
+	t.Run("UpdateWithOldKey", func(t *testing.T) {
+		c, err := d.Decode(pgMsg{'U'}.uint32(16384).
+			byte('K').uint16(2).text("1").byte('n').
+			byte('N').uint16(2).text("2").byte('u'))
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(c.Op).To(Equal(sql_util.TableChangeOpUpdate))
+		NewWithT(t).Expect(*c.Old["f_id"]).To(Equal("1"))
+		NewWithT(t).Expect(*c.New["f_id"]).To(Equal("2"))
+		NewWithT(t).Expect(c.New).NotTo(HaveKey("f_name"))
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		c, err := d.Decode(pgMsg{'D'}.uint32(16384).byte('O').uint16(2).text("2").text("x"))
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(c.Op).To(Equal(sql_util.TableChangeOpDelete))
+		NewWithT(t).Expect(*c.Old["f_name"]).To(Equal("x"))
+		NewWithT(t).Expect(c.New).To(BeNil())
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		_, err := d.Decode(pgMsg{'I'}.uint32(16384).byte('N').uint16(2).byte('t').uint32(10).string("1"))
+		NewWithT(t).Expect(err).NotTo(BeNil())
+	})
+}
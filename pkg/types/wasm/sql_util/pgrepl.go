@@ -0,0 +1,70 @@
+package sql_util
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// streaming replication messages carried in CopyData, see
+// https://www.postgresql.org/docs/current/protocol-replication.html
+const (
+	XLogDataByteID                = 'w'
+	PrimaryKeepaliveMessageByteID = 'k'
+	StandbyStatusUpdateByteID     = 'r'
+)
+
+// pgEpoch the epoch of postgres timestamps in replication messages
+var pgEpoch = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// XLogData wal data of logical replication stream
+type XLogData struct {
+	WALStart uint64
+	WALEnd   uint64
+	// WALData pgoutput message
+	WALData []byte
+}
+
+// ParseXLogData parses XLogData message without the leading byte id
+func ParseXLogData(buf []byte) (*XLogData, error) {
+	if len(buf) < 24 {
+		return nil, errors.Errorf("XLogData requires at least 24 bytes, got %d", len(buf))
+	}
+	return &XLogData{
+		WALStart: binary.BigEndian.Uint64(buf),
+		WALEnd:   binary.BigEndian.Uint64(buf[8:]),
+		WALData:  buf[24:],
+	}, nil
+}
+
+// PrimaryKeepalive keepalive message of logical replication stream
+type PrimaryKeepalive struct {
+	WALEnd uint64
+	// ReplyRequested sender should reply as soon as possible to avoid timeout
+	// disconnection
+	ReplyRequested bool
+}
+
+// ParsePrimaryKeepalive parses keepalive message without the leading byte id
+func ParsePrimaryKeepalive(buf []byte) (*PrimaryKeepalive, error) {
+	if len(buf) != 17 {
+		return nil, errors.Errorf("PrimaryKeepaliveMessage must be 17 bytes, got %d", len(buf))
+	}
+	return &PrimaryKeepalive{
+		WALEnd:         binary.BigEndian.Uint64(buf),
+		ReplyRequested: buf[16] != 0,
+	}, nil
+}
+
+// StandbyStatusUpdate encodes status update reports wal before lsn has been
+// written, flushed and applied, which allows server to recycle the wal
+func StandbyStatusUpdate(lsn uint64, at time.Time) []byte {
+	buf := make([]byte, 34)
+	buf[0] = StandbyStatusUpdateByteID
+	binary.BigEndian.PutUint64(buf[1:], lsn)
+	binary.BigEndian.PutUint64(buf[9:], lsn)
+	binary.BigEndian.PutUint64(buf[17:], lsn)
+	binary.BigEndian.PutUint64(buf[25:], uint64(at.Sub(pgEpoch).Microseconds()))
+	return buf // the last byte 0 means no reply requested
+}
@@ -0,0 +1,52 @@
+package sql_util_test
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/machinefi/w3bstream/pkg/types/wasm/sql_util"
+)
+
+func (m pgMsg) uint64(v uint64) pgMsg {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return append(m, b...)
+}
+
+func TestReplicationMessages(t *testing.T) {
+	t.Run("XLogData", func(t *testing.T) {
+		x, err := sql_util.ParseXLogData(pgMsg{}.uint64(100).uint64(200).uint64(0).byte('B'))
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(x.WALStart).To(Equal(uint64(100)))
+		NewWithT(t).Expect(x.WALEnd).To(Equal(uint64(200)))
+		NewWithT(t).Expect(x.WALData).To(Equal([]byte{'B'}))
+
+		_, err = sql_util.ParseXLogData(pgMsg{}.uint64(100).uint64(200))
+		NewWithT(t).Expect(err).NotTo(BeNil())
+	})
+
+	t.Run("PrimaryKeepalive", func(t *testing.T) {
+		k, err := sql_util.ParsePrimaryKeepalive(pgMsg{}.uint64(300).uint64(0).byte(1))
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(k.WALEnd).To(Equal(uint64(300)))
+		NewWithT(t).Expect(k.ReplyRequested).To(BeTrue())
+
+		_, err = sql_util.ParsePrimaryKeepalive(pgMsg{}.uint64(300))
+		NewWithT(t).Expect(err).NotTo(BeNil())
+	})
+
+	t.Run("StandbyStatusUpdate", func(t *testing.T) {
+		at := time.Date(2000, 1, 1, 0, 0, 1, 0, time.UTC)
+		msg := sql_util.StandbyStatusUpdate(400, at)
+		NewWithT(t).Expect(msg).To(HaveLen(34))
+		NewWithT(t).Expect(msg[0]).To(Equal(byte('r')))
+		for _, off := range []int{1, 9, 17} {
+			NewWithT(t).Expect(binary.BigEndian.Uint64(msg[off:])).To(Equal(uint64(400)))
+		}
+		NewWithT(t).Expect(binary.BigEndian.Uint64(msg[25:])).To(Equal(uint64(time.Second.Microseconds())))
+		NewWithT(t).Expect(msg[33]).To(Equal(byte(0)))
+	})
+}
@@ -4,10 +4,16 @@ import (
 	"database/sql"
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
+	"unicode"
 
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 	"github.com/tidwall/gjson"
+
+	"github.com/machinefi/w3bstream/pkg/enums"
 )
 
 func ParseQuery(data []byte) (prestate string, params []interface{}, err error) {
@@ -38,6 +44,250 @@ func ParseQuery(data []byte) (prestate string, params []interface{}, err error)
 	return
 }
 
+// ParseQueries parses a json array of queries, each element is formatted as
+// the input of ParseQuery
+func ParseQueries(data []byte) (prestates []string, params [][]interface{}, err error) {
+	if !gjson.ValidBytes(data) {
+		return nil, nil, errors.New("queries is invalid")
+	}
+	res := gjson.ParseBytes(data)
+	if !res.IsArray() || len(res.Array()) == 0 {
+		return nil, nil, errors.New("queries should be a non-empty array")
+	}
+	for _, q := range res.Array() {
+		prestate, param, err := ParseQuery([]byte(q.Raw))
+		if err != nil {
+			return nil, nil, err
+		}
+		prestates = append(prestates, prestate)
+		params = append(params, param)
+	}
+	return
+}
+
+// IsSelectStatement checks if prestate is a single SELECT statement, it may
+// start with the CTEs without data modifying, eg: `WITH t AS (SELECT ...)
+// SELECT ...`
+func IsSelectStatement(prestate string) bool {
+	stmt := strings.TrimSpace(prestate)
+	stmt = strings.TrimSpace(strings.TrimSuffix(stmt, ";"))
+	if strings.Contains(stmt, ";") {
+		return false
+	}
+	switch {
+	case hasKeywordPrefix(stmt, "select"):
+		return true
+	case hasKeywordPrefix(stmt, "with"):
+		for _, w := range sqlWords(stmt) {
+			if dataModifyingKeywords[strings.ToUpper(w)] {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// dataModifyingKeywords the statements not allowed in CTEs of SELECT statement
+var dataModifyingKeywords = map[string]bool{
+	"INSERT": true, "UPDATE": true, "DELETE": true, "MERGE": true,
+}
+
+// hasKeywordPrefix checks if stmt starts with keyword followed by space
+func hasKeywordPrefix(stmt, keyword string) bool {
+	n := len(keyword)
+	return len(stmt) > n && strings.EqualFold(stmt[:n], keyword) &&
+		unicode.IsSpace(rune(stmt[n]))
+}
+
+// sqlWords splits the unquoted words of stmt, the string literals and quoted
+// identifiers are skipped
+func sqlWords(stmt string) []string {
+	words := make([]string, 0)
+	start, quote := -1, rune(0)
+	for i, r := range stmt {
+		if quote != 0 {
+			if r == quote {
+				quote = 0
+			}
+			continue
+		}
+		isWord := r == '_' || unicode.IsLetter(r) || (start >= 0 && unicode.IsDigit(r))
+		if isWord {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			words = append(words, stmt[start:i])
+			start = -1
+		}
+		if r == '\'' || r == '"' {
+			quote = r
+		}
+	}
+	if start >= 0 {
+		words = append(words, stmt[start:])
+	}
+	return words
+}
+
+// CountStatement wraps a SELECT statement to count the rows it returns
+func CountStatement(prestate string) (string, error) {
+	if !IsSelectStatement(prestate) {
+		return "", errors.New("only SELECT statement can be counted")
+	}
+	stmt := strings.TrimSuffix(strings.TrimSpace(prestate), ";")
+	return "SELECT COUNT(*) FROM (" + stmt + ") AS _cnt", nil
+}
+
+// ExplainStatement prepends EXPLAIN to a SELECT statement to output its
+// actual execution plan as json
+func ExplainStatement(prestate string) (string, error) {
+	if !IsSelectStatement(prestate) {
+		return "", errors.New("only SELECT statement can be explained")
+	}
+	stmt := strings.TrimSuffix(strings.TrimSpace(prestate), ";")
+	return "EXPLAIN (ANALYZE, BUFFERS, FORMAT JSON) " + stmt, nil
+}
+
+// ShadowTables prepends CTEs named as tables to a SELECT statement, the CTEs
+// select the rows matching cond from the tables of schema, so the unqualified
+// references to the tables in statement only see these rows. the statement is
+// returned unchanged if it is not a SELECT statement or tables is empty
+func ShadowTables(prestate, schema string, tables []string, cond string) string {
+	if !IsSelectStatement(prestate) || len(tables) == 0 {
+		return prestate
+	}
+	ctes := make([]string, 0, len(tables))
+	for _, t := range tables {
+		ctes = append(ctes, fmt.Sprintf(`"%s" AS (SELECT * FROM "%s"."%s" WHERE %s)`, t, schema, t, cond))
+	}
+	stmt := strings.TrimSuffix(strings.TrimSpace(prestate), ";")
+	if !hasKeywordPrefix(stmt, "with") {
+		return "WITH " + strings.Join(ctes, ", ") + " " + stmt
+	}
+	// merges into the CTEs of statement, the CTEs of statement follow so they
+	// see the shadowed tables
+	with, rest := "WITH ", strings.TrimSpace(stmt[4:])
+	if hasKeywordPrefix(rest, "recursive") {
+		with, rest = "WITH RECURSIVE ", strings.TrimSpace(rest[9:])
+	}
+	return with + strings.Join(ctes, ", ") + ", " + rest
+}
+
+// IsStatementTimeout checks if err is raised by postgres statement_timeout
+func IsStatementTimeout(err error) bool {
+	var e *pq.Error
+	return errors.As(err, &e) && e.Code == "57014" && strings.Contains(e.Message, "statement timeout")
+}
+
+// UpsertStatement builds a parameterized INSERT statement of cols into table,
+// the rows conflicted on conflictCols are updated with the other cols. the
+// names are quoted as is, caller should validate them with the table schema
+func UpsertStatement(table string, cols, conflictCols []string) (string, error) {
+	if table == "" || len(cols) == 0 || len(conflictCols) == 0 {
+		return "", errors.New("table, cols and conflict cols are required")
+	}
+	conflicts := make(map[string]bool, len(conflictCols))
+	quotedConflicts := make([]string, 0, len(conflictCols))
+	for _, c := range conflictCols {
+		conflicts[c] = true
+		quotedConflicts = append(quotedConflicts, `"`+c+`"`)
+	}
+	quoted := make([]string, 0, len(cols))
+	values := make([]string, 0, len(cols))
+	updates := make([]string, 0, len(cols))
+	for i, c := range cols {
+		quoted = append(quoted, `"`+c+`"`)
+		values = append(values, fmt.Sprintf("$%d", i+1))
+		if !conflicts[c] {
+			updates = append(updates, fmt.Sprintf(`"%s" = EXCLUDED."%s"`, c, c))
+		}
+	}
+	action := "DO NOTHING"
+	if len(updates) > 0 {
+		action = "DO UPDATE SET " + strings.Join(updates, ", ")
+	}
+	return fmt.Sprintf(`INSERT INTO "%s" (%s) VALUES (%s) ON CONFLICT (%s) %s`,
+		table, strings.Join(quoted, ", "), strings.Join(values, ", "),
+		strings.Join(quotedConflicts, ", "), action), nil
+}
+
+// PaginateFilter condition of keyset pagination as `"Col" Op $n`
+type PaginateFilter struct {
+	Col string
+	Op  string
+}
+
+var paginateOps = map[string]bool{
+	"=": true, "!=": true, "<>": true, "<": true, "<=": true, ">": true, ">=": true,
+	"LIKE": true, "ILIKE": true,
+}
+
+// PaginateStatement builds a parameterized keyset pagination SELECT of table
+// ordered by orderCol. if afterLast, `$1` is the orderCol value of the last row
+// of previous page, and the params of filters follow in order. the names are
+// quoted as is, caller should validate them with the table schema
+func PaginateStatement(table, orderCol string, afterLast bool, filters []PaginateFilter, pageSize int) (string, error) {
+	if table == "" || orderCol == "" {
+		return "", errors.New("table and order column are required")
+	}
+	if pageSize <= 0 {
+		return "", errors.New("page size must be positive")
+	}
+
+	conds := make([]string, 0, len(filters)+1)
+	idx := 1
+	if afterLast {
+		conds = append(conds, fmt.Sprintf(`"%s" > $%d`, orderCol, idx))
+		idx++
+	}
+	for _, f := range filters {
+		op := strings.ToUpper(strings.TrimSpace(f.Op))
+		if !paginateOps[op] {
+			return "", errors.Errorf("unsupported filter operator: %s", f.Op)
+		}
+		conds = append(conds, fmt.Sprintf(`"%s" %s $%d`, f.Col, op, idx))
+		idx++
+	}
+
+	stmt := fmt.Sprintf(`SELECT * FROM "%s"`, table)
+	if len(conds) > 0 {
+		stmt += " WHERE " + strings.Join(conds, " AND ")
+	}
+	return stmt + fmt.Sprintf(` ORDER BY "%s" LIMIT %d`, orderCol, pageSize), nil
+}
+
+// EncodeQueryParam encodes the json value v of column typed datatype as the
+// typed param decoded by DecodeQueryParam, eg: `{"int64":1}`
+func EncodeQueryParam(datatype enums.WasmDBDatatype, v gjson.Result) ([]byte, error) {
+	var param map[string]interface{}
+	switch datatype {
+	case enums.WASM_DB_DATATYPE__INT,
+		enums.WASM_DB_DATATYPE__INT8, enums.WASM_DB_DATATYPE__UINT8,
+		enums.WASM_DB_DATATYPE__INT16, enums.WASM_DB_DATATYPE__UINT16,
+		enums.WASM_DB_DATATYPE__INT32, enums.WASM_DB_DATATYPE__UINT32,
+		enums.WASM_DB_DATATYPE__INT64, enums.WASM_DB_DATATYPE__UINT64,
+		enums.WASM_DB_DATATYPE__UINT, enums.WASM_DB_DATATYPE__TIMESTAMP:
+		param = map[string]interface{}{"int64": v.Int()}
+	case enums.WASM_DB_DATATYPE__FLOAT32, enums.WASM_DB_DATATYPE__FLOAT64:
+		param = map[string]interface{}{"float64": v.Float()}
+	case enums.WASM_DB_DATATYPE__TEXT,
+		enums.WASM_DB_DATATYPE__DECIMAL, enums.WASM_DB_DATATYPE__NUMERIC:
+		param = map[string]interface{}{"string": v.String()}
+	case enums.WASM_DB_DATATYPE__BOOL:
+		param = map[string]interface{}{"bool": v.Bool()}
+	case enums.WASM_DB_DATATYPE__TIMESTAMPTZ:
+		param = map[string]interface{}{"time": v.String()}
+	default:
+		return nil, errors.Errorf("unsupported param datatype: %s", datatype)
+	}
+	return json.Marshal(param)
+}
+
 func DecodeQueryParam(in *gjson.Result) (ret interface{}, err error) {
 	switch {
 	case in.Get("int32").Exists():
@@ -78,7 +328,7 @@ func JsonifyRows(rawRows *sql.Rows) ([]byte, error) {
 			switch columnTypes[i].DatabaseTypeName() {
 			case "VARCHAR", "TEXT", "CHAR":
 				scanArgs[i] = new(sql.NullString)
-			case "TIMESTAMP", "TIME", "DATE":
+			case "TIMESTAMP", "TIMESTAMPTZ", "TIME", "DATE":
 				scanArgs[i] = new(sql.NullTime)
 			case "BOOL", "BOOLEAN":
 				scanArgs[i] = new(sql.NullBool)
@@ -124,13 +374,12 @@ func JsonifyRows(rawRows *sql.Rows) ([]byte, error) {
 					continue
 				}
 				entryMap[colName], err = v.Value()
-			// TODO: support time encodings
-			// case *sql.NullTime:
-			// 	if !v.Valid {
-			// 		entryMap[colName] = nil
-			// 		continue
-			// 	}
-			// 	entryMap[colName], err = v.Value()
+			case *sql.NullTime:
+				if !v.Valid {
+					entryMap[colName] = nil
+					continue
+				}
+				entryMap[colName] = v.Time.Format(time.RFC3339Nano)
 			default:
 				entryMap[colName] = scanArgs[i]
 			}
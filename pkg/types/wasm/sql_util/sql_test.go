@@ -0,0 +1,184 @@
+package sql_util_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/tidwall/gjson"
+
+	"github.com/machinefi/w3bstream/pkg/enums"
+	"github.com/machinefi/w3bstream/pkg/types/wasm/sql_util"
+)
+
+func TestCountStatement(t *testing.T) {
+	cases := []struct {
+		name      string
+		prestate  string
+		statement string
+		valid     bool
+	}{
+		{"Select", "SELECT * FROM t_demo WHERE f_id > $1", "SELECT COUNT(*) FROM (SELECT * FROM t_demo WHERE f_id > $1) AS _cnt", true},
+		{"LowerCaseWithSemicolon", "  select f_id from t_demo; ", "SELECT COUNT(*) FROM (select f_id from t_demo) AS _cnt", true},
+		{"Delete", "DELETE FROM t_demo", "", false},
+		{"MultiStatements", "SELECT 1; DELETE FROM t_demo", "", false},
+		{"SelectPrefix", "SELECTED", "", false},
+		{"With", "WITH t AS (SELECT * FROM t_demo) SELECT f_id FROM t", "SELECT COUNT(*) FROM (WITH t AS (SELECT * FROM t_demo) SELECT f_id FROM t) AS _cnt", true},
+		{"WithRecursive", "with recursive t(n) AS (SELECT 1 UNION ALL SELECT n+1 FROM t WHERE n < 3) SELECT n FROM t", "SELECT COUNT(*) FROM (with recursive t(n) AS (SELECT 1 UNION ALL SELECT n+1 FROM t WHERE n < 3) SELECT n FROM t) AS _cnt", true},
+		{"WithQuotedKeyword", `WITH t AS (SELECT "delete", 'update' FROM t_demo) SELECT * FROM t`, `SELECT COUNT(*) FROM (WITH t AS (SELECT "delete", 'update' FROM t_demo) SELECT * FROM t) AS _cnt`, true},
+		{"WithDelete", "WITH d AS (DELETE FROM t_demo RETURNING *) SELECT * FROM d", "", false},
+		{"WithInsert", "WITH t AS (SELECT 1) INSERT INTO t_demo SELECT * FROM t", "", false},
+		{"WithPrefix", "WITHOUT", "", false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			stmt, err := sql_util.CountStatement(c.prestate)
+			if c.valid {
+				NewWithT(t).Expect(err).To(BeNil())
+				NewWithT(t).Expect(stmt).To(Equal(c.statement))
+			} else {
+				NewWithT(t).Expect(err).NotTo(BeNil())
+			}
+		})
+	}
+}
+
+func TestExplainStatement(t *testing.T) {
+	stmt, err := sql_util.ExplainStatement(" select f_id from t_demo where f_id > $1;")
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(stmt).To(Equal("EXPLAIN (ANALYZE, BUFFERS, FORMAT JSON) select f_id from t_demo where f_id > $1"))
+
+	_, err = sql_util.ExplainStatement("DELETE FROM t_demo")
+	NewWithT(t).Expect(err).NotTo(BeNil())
+}
+
+func TestShadowTables(t *testing.T) {
+	stmt := sql_util.ShadowTables("SELECT * FROM t_a JOIN t_b USING (f_id);", "public", []string{"t_a", "t_b"}, "is_deleted = FALSE")
+	NewWithT(t).Expect(stmt).To(Equal(`WITH "t_a" AS (SELECT * FROM "public"."t_a" WHERE is_deleted = FALSE), ` +
+		`"t_b" AS (SELECT * FROM "public"."t_b" WHERE is_deleted = FALSE) SELECT * FROM t_a JOIN t_b USING (f_id)`))
+
+	stmt = sql_util.ShadowTables("WITH x AS (SELECT * FROM t_a) SELECT * FROM x", "public", []string{"t_a"}, "TRUE")
+	NewWithT(t).Expect(stmt).To(Equal(`WITH "t_a" AS (SELECT * FROM "public"."t_a" WHERE TRUE), x AS (SELECT * FROM t_a) SELECT * FROM x`))
+
+	stmt = sql_util.ShadowTables("with recursive x(n) AS (SELECT 1) SELECT * FROM x, t_a", "public", []string{"t_a"}, "TRUE")
+	NewWithT(t).Expect(stmt).To(Equal(`WITH RECURSIVE "t_a" AS (SELECT * FROM "public"."t_a" WHERE TRUE), x(n) AS (SELECT 1) SELECT * FROM x, t_a`))
+
+	NewWithT(t).Expect(sql_util.ShadowTables("DELETE FROM t_a", "public", []string{"t_a"}, "TRUE")).To(Equal("DELETE FROM t_a"))
+	NewWithT(t).Expect(sql_util.ShadowTables("SELECT 1", "public", nil, "TRUE")).To(Equal("SELECT 1"))
+}
+
+func TestIsStatementTimeout(t *testing.T) {
+	err := &pq.Error{Code: "57014", Message: "canceling statement due to statement timeout"}
+	NewWithT(t).Expect(sql_util.IsStatementTimeout(errors.Wrap(err, "query"))).To(BeTrue())
+	NewWithT(t).Expect(sql_util.IsStatementTimeout(&pq.Error{Code: "57014", Message: "canceling statement due to user request"})).To(BeFalse())
+	NewWithT(t).Expect(sql_util.IsStatementTimeout(errors.New("any"))).To(BeFalse())
+}
+
+func TestUpsertStatement(t *testing.T) {
+	stmt, err := sql_util.UpsertStatement("t_device", []string{"f_id", "f_name", "f_ts"}, []string{"f_id"})
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(stmt).To(Equal(`INSERT INTO "t_device" ("f_id", "f_name", "f_ts") VALUES ($1, $2, $3) ` +
+		`ON CONFLICT ("f_id") DO UPDATE SET "f_name" = EXCLUDED."f_name", "f_ts" = EXCLUDED."f_ts"`))
+
+	stmt, err = sql_util.UpsertStatement("t_device", []string{"f_id"}, []string{"f_id"})
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(stmt).To(Equal(`INSERT INTO "t_device" ("f_id") VALUES ($1) ON CONFLICT ("f_id") DO NOTHING`))
+
+	_, err = sql_util.UpsertStatement("t_device", []string{"f_id"}, nil)
+	NewWithT(t).Expect(err).NotTo(BeNil())
+}
+
+func TestPaginateStatement(t *testing.T) {
+	stmt, err := sql_util.PaginateStatement("t_event", "f_id", false, nil, 10)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(stmt).To(Equal(`SELECT * FROM "t_event" ORDER BY "f_id" LIMIT 10`))
+
+	stmt, err = sql_util.PaginateStatement("t_event", "f_id", true, []sql_util.PaginateFilter{
+		{Col: "f_device", Op: "="}, {Col: "f_name", Op: "ilike"},
+	}, 10)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(stmt).To(Equal(`SELECT * FROM "t_event" WHERE "f_id" > $1 AND "f_device" = $2 ` +
+		`AND "f_name" ILIKE $3 ORDER BY "f_id" LIMIT 10`))
+
+	_, err = sql_util.PaginateStatement("t_event", "f_id", false, []sql_util.PaginateFilter{{Col: "f_id", Op: "; DROP"}}, 10)
+	NewWithT(t).Expect(err).NotTo(BeNil())
+
+	_, err = sql_util.PaginateStatement("t_event", "f_id", false, nil, 0)
+	NewWithT(t).Expect(err).NotTo(BeNil())
+}
+
+func TestEncodeQueryParam(t *testing.T) {
+	cases := []struct {
+		name     string
+		datatype enums.WasmDBDatatype
+		value    string
+		encoded  string
+		decoded  interface{}
+	}{
+		{"Int", enums.WASM_DB_DATATYPE__INT32, `10`, `{"int64":10}`, int64(10)},
+		{"Uint", enums.WASM_DB_DATATYPE__UINT64, `10`, `{"int64":10}`, int64(10)},
+		{"Timestamp", enums.WASM_DB_DATATYPE__TIMESTAMP, `1700000000`, `{"int64":1700000000}`, int64(1700000000)},
+		{"Float", enums.WASM_DB_DATATYPE__FLOAT32, `1.5`, `{"float64":1.5}`, 1.5},
+		{"Text", enums.WASM_DB_DATATYPE__TEXT, `"a"`, `{"string":"a"}`, "a"},
+		{"Numeric", enums.WASM_DB_DATATYPE__NUMERIC, `"1.10"`, `{"string":"1.10"}`, "1.10"},
+		{"Bool", enums.WASM_DB_DATATYPE__BOOL, `true`, `{"bool":true}`, true},
+		{"Timestamptz", enums.WASM_DB_DATATYPE__TIMESTAMPTZ, `"2023-01-02T03:04:05.123Z"`,
+			`{"time":"2023-01-02T03:04:05.123Z"}`, time.Date(2023, 1, 2, 3, 4, 5, 123000000, time.UTC)},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			encoded, err := sql_util.EncodeQueryParam(c.datatype, gjson.Parse(c.value))
+			NewWithT(t).Expect(err).To(BeNil())
+			NewWithT(t).Expect(string(encoded)).To(Equal(c.encoded))
+
+			res := gjson.ParseBytes(encoded)
+			decoded, err := sql_util.DecodeQueryParam(&res)
+			NewWithT(t).Expect(err).To(BeNil())
+			NewWithT(t).Expect(decoded).To(BeEquivalentTo(c.decoded))
+		})
+	}
+
+	_, err := sql_util.EncodeQueryParam(enums.WASM_DB_DATATYPE__TSVECTOR, gjson.Parse(`"a"`))
+	NewWithT(t).Expect(err).NotTo(BeNil())
+}
+
+func TestParseQueries(t *testing.T) {
+	data := []byte(`[
+		{"statement": "INSERT INTO t_event (f_id) VALUES ($1)", "params": [{"int64": 1}]},
+		{"statement": "INSERT INTO t_index (f_name) VALUES ($1)", "params": [{"string": "a"}]}
+	]`)
+
+	prestates, params, err := sql_util.ParseQueries(data)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(prestates).To(HaveLen(2))
+	NewWithT(t).Expect(params[0]).To(Equal([]interface{}{int64(1)}))
+	NewWithT(t).Expect(params[1]).To(Equal([]interface{}{"a"}))
+
+	for _, invalid := range []string{`[]`, `{"statement": "", "params": []}`, `[{"statement": ""}]`} {
+		_, _, err = sql_util.ParseQueries([]byte(invalid))
+		NewWithT(t).Expect(err).NotTo(BeNil())
+	}
+}
+
+func TestJsonifyRows_Timestamptz(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	NewWithT(t).Expect(err).To(BeNil())
+	defer db.Close()
+
+	ts := time.Date(2023, 5, 1, 8, 30, 0, 0, time.FixedZone("", 8*3600))
+	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRowsWithColumnDefinition(
+		sqlmock.NewColumn("f_at").OfType("TIMESTAMPTZ", time.Time{}),
+	).AddRow(ts))
+
+	rows, err := db.Query("SELECT f_at FROM t_demo")
+	NewWithT(t).Expect(err).To(BeNil())
+	defer rows.Close()
+
+	data, err := sql_util.JsonifyRows(rows)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(string(data)).To(Equal(`{"f_at":"2023-05-01T08:30:00+08:00"}`))
+}
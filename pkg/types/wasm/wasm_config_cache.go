@@ -3,6 +3,8 @@ package wasm
 import (
 	"context"
 
+	"github.com/pkg/errors"
+
 	"github.com/machinefi/w3bstream/pkg/enums"
 	"github.com/machinefi/w3bstream/pkg/types"
 	"github.com/machinefi/w3bstream/pkg/types/wasm/kvdb"
@@ -19,10 +21,16 @@ type Cache struct {
 	kv KVStore
 }
 
+// Init creates kv store of cache. the redis store keeps entries in the hash
+// shared by projects, the usage quota and pubsub channels are per project
 func (c *Cache) Init(parent context.Context) error {
 	switch c.Mode {
 	case enums.CACHE_MODE__REDIS:
-		c.kv = kvdb.NewRedisDB(types.MustRedisEndpointFromContext(parent))
+		prj, ok := types.ProjectFromContext(parent)
+		if !ok {
+			return errors.New("project is required by redis cache")
+		}
+		c.kv = kvdb.NewProjectRedisDB(types.MustRedisEndpointFromContext(parent), prj.ProjectID.String())
 	default:
 		c.kv = kvdb.NewMemDB()
 	}
@@ -1,11 +1,25 @@
 package wasm
 
 import (
+	"compress/gzip"
 	"context"
+	"crypto/md5"
+	"database/sql"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
+	"unicode"
 
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 
 	confpostgres "github.com/machinefi/w3bstream/pkg/depends/conf/postgres"
@@ -16,9 +30,17 @@ import (
 	"github.com/machinefi/w3bstream/pkg/depends/kit/sqlx/migration"
 	"github.com/machinefi/w3bstream/pkg/depends/x/misc/retry"
 	"github.com/machinefi/w3bstream/pkg/enums"
+	"github.com/machinefi/w3bstream/pkg/models"
+	"github.com/machinefi/w3bstream/pkg/modules/metrics"
 	"github.com/machinefi/w3bstream/pkg/types"
 )
 
+// BuildMode is injected by ldflags, BuildModeTesting enables the destructive
+// helpers which are only used in tests, eg: Database.ResetSequences
+var BuildMode = ""
+
+const BuildModeTesting = "testing"
+
 func NewDatabase(name string) *Database {
 	return &Database{Name: name}
 }
@@ -31,10 +53,23 @@ type Database struct {
 	Dialect enums.WasmDBDialect `json:"dialect,omitempty,default=''"`
 	// Schemas schema list
 	Schemas []*Schema `json:"schemas,omitempty"`
+	// AllowRuntimeMigration allows wasm to migrate schemas by ws_db_migrate
+	AllowRuntimeMigration bool `json:"allowRuntimeMigration,omitempty"`
 	// schemas reference of Schemas; key: schema name
 	schemas map[string]*Schema
 
 	ep *confpostgres.Endpoint // database endpoint
+	// pools connection pool of each schema, the search path of connections is
+	// set when connected; key: schema name
+	pools map[string]*confpostgres.Endpoint
+	// poolSize max connections shared by schema pools
+	poolSize int
+	// project name of database, it is the label of metrics
+	project string
+	// watches count of running table change watches
+	watches int
+	// mtx guards schemas and pools changed by AddSchema and watches
+	mtx sync.RWMutex
 }
 
 type Schema struct {
@@ -53,8 +88,34 @@ type Table struct {
 	Cols []*Column `json:"cols"`
 	// Keys table index or primary define
 	Keys []*Key `json:"keys"`
+	// Partitioning table partitioning, nil means not partitioned
+	Partitioning *PartitionSpec `json:"partitioning,omitempty"`
+	// SoftDelete appends SoftDeleteColumn and SoftDeleteAtColumn and their
+	// index to table. the soft deleted rows are excluded from the SELECTs of
+	// ws_get_sql_db and rows are deleted softly by ws_soft_delete
+	SoftDelete bool `json:"softDelete,omitempty"`
+	// FTS full-text search columns, each appends a TSVECTOR column with GIN
+	// index to table which is populated from the source column by trigger
+	FTS []*FTSColumn `json:"fts,omitempty"`
 }
 
+// FTSColumn tsvector column populated by `to_tsvector(Language, SourceColumn)`
+type FTSColumn struct {
+	// Name tsvector column name
+	Name string `json:"name"`
+	// SourceColumn text column indexed
+	SourceColumn string `json:"sourceColumn"`
+	// Language text search configuration, eg: `english`, `simple`
+	Language string `json:"language"`
+}
+
+const (
+	// SoftDeleteColumn flags if row is soft deleted
+	SoftDeleteColumn = "is_deleted"
+	// SoftDeleteAtColumn soft deletion time in epoch milliseconds
+	SoftDeleteAtColumn = "deleted_at_ms"
+)
+
 func (t *Table) Build() *builder.Table {
 	tbl := builder.T(t.Name)
 	tbl.Desc = []string{t.Desc}
@@ -64,9 +125,333 @@ func (t *Table) Build() *builder.Table {
 	for _, k := range t.Keys {
 		tbl.AddKey(k.Build(t.Name))
 	}
+	if t.SoftDelete {
+		cols, key := t.softDeleteColumns()
+		for _, c := range cols {
+			tbl.AddCol(c.Build())
+		}
+		if key != nil {
+			tbl.AddKey(key.Build(t.Name))
+		}
+	}
+	for _, c := range t.FTS {
+		col, key := t.ftsColumn(c)
+		if col != nil {
+			tbl.AddCol(col.Build())
+		}
+		if key != nil {
+			tbl.AddKey(key.Build(t.Name))
+		}
+	}
+	if p := t.Partitioning; p != nil {
+		tbl.PartitionBy = strings.ToUpper(p.Strategy) + " (" + strings.ToLower(p.ColumnName) + ")"
+	}
 	return tbl
 }
 
+// softDeleteColumns returns soft delete columns and index not defined by table
+func (t *Table) softDeleteColumns() ([]*Column, *Key) {
+	var cols []*Column
+	if !hasColumn(t, SoftDeleteColumn) {
+		dft := "false"
+		cols = append(cols, &Column{Name: SoftDeleteColumn, Constrains: Constrains{
+			Datatype: enums.WASM_DB_DATATYPE__BOOL,
+			Default:  &dft,
+			Desc:     "soft deleted flag",
+		}})
+	}
+	if !hasColumn(t, SoftDeleteAtColumn) {
+		dft := "0"
+		cols = append(cols, &Column{Name: SoftDeleteAtColumn, Constrains: Constrains{
+			Datatype: enums.WASM_DB_DATATYPE__TIMESTAMP,
+			Default:  &dft,
+			Desc:     "soft deleted time in epoch milliseconds",
+		}})
+	}
+	key := &Key{Name: "soft_delete", ColumnNames: []string{SoftDeleteColumn, SoftDeleteAtColumn}}
+	if hasKey(t, key.Name) {
+		return cols, nil
+	}
+	return cols, key
+}
+
+// AddFTSColumn adds a full-text search column name populated from sourceCol
+// with text search configuration lang
+func (t *Table) AddFTSColumn(name, sourceCol, lang string) error {
+	c := &FTSColumn{Name: name, SourceColumn: sourceCol, Language: lang}
+	t.FTS = append(t.FTS, c)
+	if err := t.ValidateFTS(); err != nil {
+		t.FTS = t.FTS[:len(t.FTS)-1]
+		return err
+	}
+	return nil
+}
+
+// ftsColumn returns tsvector column and its GIN index not defined by table
+func (t *Table) ftsColumn(c *FTSColumn) (*Column, *Key) {
+	var col *Column
+	if !hasColumn(t, c.Name) {
+		col = &Column{Name: c.Name, Constrains: Constrains{
+			Datatype: enums.WASM_DB_DATATYPE__TSVECTOR,
+			Null:     true,
+			Desc:     "full-text search document of " + c.SourceColumn,
+		}}
+	}
+	key := &Key{Name: c.Name, Method: "gin", ColumnNames: []string{c.Name}}
+	if hasKey(t, key.Name) {
+		return col, nil
+	}
+	return col, key
+}
+
+var ftsIdentifier = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// ValidateFTS checks that full-text search columns are not duplicated, source
+// columns are TEXT columns of table and languages are valid identifiers
+func (t *Table) ValidateFTS() error {
+	names := make(map[string]bool)
+	for _, c := range t.FTS {
+		if !ftsIdentifier.MatchString(c.Name) {
+			return errors.Errorf("invalid fts column name: %s", c.Name)
+		}
+		if names[c.Name] {
+			return errors.Errorf("duplicated fts column: %s", c.Name)
+		}
+		names[c.Name] = true
+		if !ftsIdentifier.MatchString(c.Language) {
+			return errors.Errorf("fts column %s: invalid language: %s", c.Name, c.Language)
+		}
+		var src *Column
+		for _, col := range t.Cols {
+			if col.Name == c.SourceColumn {
+				src = col
+			}
+		}
+		if src == nil || src.Constrains.Datatype != enums.WASM_DB_DATATYPE__TEXT {
+			return errors.Errorf("fts column %s: source column %s must be a TEXT column", c.Name, c.SourceColumn)
+		}
+	}
+	return nil
+}
+
+// createFTSTriggers creates or replaces the triggers which populate the full
+// text search columns before rows inserted or updated. the rows existed before
+// trigger created are not populated until they are updated
+func createFTSTriggers(ctx context.Context, db sqlx.DBExecutor, s *Schema) error {
+	for _, t := range s.Tables {
+		for _, c := range t.FTS {
+			fn := fmt.Sprintf("%s.%s_%s_fts", s.Name, t.Name, c.Name)
+			trigger := fmt.Sprintf("%s_%s_fts", t.Name, c.Name)
+			for _, q := range []string{
+				fmt.Sprintf(
+					"CREATE OR REPLACE FUNCTION %s() RETURNS trigger AS $$ BEGIN "+
+						"NEW.%s := to_tsvector('%s'::regconfig, coalesce(NEW.%s, '')); "+
+						"RETURN NEW; END $$ LANGUAGE plpgsql",
+					fn, c.Name, c.Language, c.SourceColumn,
+				),
+				fmt.Sprintf("DROP TRIGGER IF EXISTS %s ON %s.%s", trigger, s.Name, t.Name),
+				fmt.Sprintf(
+					"CREATE TRIGGER %s BEFORE INSERT OR UPDATE ON %s.%s FOR EACH ROW EXECUTE PROCEDURE %s()",
+					trigger, s.Name, t.Name, fn,
+				),
+			} {
+				if _, err := db.ExecContext(ctx, q); err != nil {
+					return errors.Wrapf(err, "create fts trigger %s", trigger)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// PartitionSpec partitioning of time-series table
+type PartitionSpec struct {
+	// Strategy partition strategy: RANGE, LIST or HASH
+	Strategy string `json:"strategy"`
+	// ColumnName partition key column
+	ColumnName string `json:"columnName"`
+	// Interval range of each child partition, eg: `1 month`, `7 days`, it is
+	// only for RANGE strategy on TIMESTAMP(epoch milliseconds) column. child
+	// partitions of current, previous and next interval are created when
+	// database initialized
+	Interval string `json:"interval,omitempty"`
+}
+
+// ValidatePartitioning checks partition strategy, key column and interval
+func (t *Table) ValidatePartitioning() error {
+	p := t.Partitioning
+	if p == nil {
+		return nil
+	}
+	switch strings.ToUpper(p.Strategy) {
+	case "RANGE", "LIST", "HASH":
+	default:
+		return errors.Errorf("unsupported partition strategy: %s", p.Strategy)
+	}
+
+	var col *Column
+	for _, c := range t.Cols {
+		if strings.EqualFold(c.Name, p.ColumnName) {
+			col = c
+			break
+		}
+	}
+	if col == nil {
+		return errors.Errorf("partition column %s not found", p.ColumnName)
+	}
+	// unique constraints of partitioned table must include partition column
+	for _, k := range t.Keys {
+		if !k.IsUnique || k.Expr != "" {
+			continue
+		}
+		found := false
+		for _, name := range k.ColumnNames {
+			if strings.EqualFold(name, p.ColumnName) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return errors.Errorf("unique key %s must include partition column %s", k.Name, p.ColumnName)
+		}
+	}
+
+	if p.Interval == "" {
+		return nil
+	}
+	if !strings.EqualFold(p.Strategy, "RANGE") {
+		return errors.New("partition interval is only for RANGE strategy")
+	}
+	if col.Constrains.Datatype != enums.WASM_DB_DATATYPE__TIMESTAMP {
+		return errors.New("partition interval is only for TIMESTAMP column")
+	}
+	_, _, err := parsePartitionInterval(p.Interval)
+	return err
+}
+
+// parsePartitionInterval parses interval like `1 month` to count and unit
+func parsePartitionInterval(interval string) (int, string, error) {
+	parts := strings.Fields(strings.ToLower(interval))
+	if len(parts) != 2 {
+		return 0, "", errors.Errorf("invalid partition interval: %s", interval)
+	}
+	n, err := strconv.Atoi(parts[0])
+	if err != nil || n <= 0 {
+		return 0, "", errors.Errorf("invalid partition interval: %s", interval)
+	}
+	unit := strings.TrimSuffix(parts[1], "s")
+	switch unit {
+	case "day", "week", "month", "year":
+		return n, unit, nil
+	default:
+		return 0, "", errors.Errorf("invalid partition interval unit: %s", parts[1])
+	}
+}
+
+// partitionRange child partition covers [From, To)
+type partitionRange struct {
+	Name     string
+	From, To time.Time
+}
+
+// partitionRanges returns child partitions of the previous, current and next
+// interval of now
+func partitionRanges(table string, interval string, now time.Time) ([]partitionRange, error) {
+	n, unit, err := parsePartitionInterval(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	// the start of interval is aligned from unix epoch, so that the ranges
+	// created at different time never overlap
+	now = now.UTC()
+	y, m, d := now.Date()
+	var (
+		start time.Time
+		next  func(time.Time, int) time.Time
+	)
+	switch unit {
+	case "day", "week":
+		days := n
+		anchor := time.Unix(0, 0).UTC()
+		if unit == "week" {
+			days, anchor = 7*n, anchor.AddDate(0, 0, 4) // 1970-01-05 is monday
+		}
+		elapsed := int(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Sub(anchor).Hours() / 24)
+		start = anchor.AddDate(0, 0, elapsed-mod(elapsed, days))
+		next = func(t time.Time, i int) time.Time { return t.AddDate(0, 0, i*days) }
+	case "month":
+		elapsed := (y-1970)*12 + int(m) - 1
+		start = time.Date(1970, time.January, 1, 0, 0, 0, 0, time.UTC).AddDate(0, elapsed-mod(elapsed, n), 0)
+		next = func(t time.Time, i int) time.Time { return t.AddDate(0, i*n, 0) }
+	case "year":
+		start = time.Date(1970+(y-1970)-mod(y-1970, n), time.January, 1, 0, 0, 0, 0, time.UTC)
+		next = func(t time.Time, i int) time.Time { return t.AddDate(i*n, 0, 0) }
+	}
+
+	ranges := make([]partitionRange, 0, 3)
+	for i := -1; i <= 1; i++ {
+		from := next(start, i)
+		ranges = append(ranges, partitionRange{
+			Name: fmt.Sprintf("%s_p%s", table, from.Format("20060102")),
+			From: from,
+			To:   next(from, 1),
+		})
+	}
+	return ranges, nil
+}
+
+func mod(a, b int) int { return (a%b + b) % b }
+
+// createPartitions creates the DEFAULT partition and the child partitions of
+// RANGE partitioned tables which interval is defined. the rows out of child
+// partitions, eg: inserted after the next interval before partitions created
+// again, are stored in the DEFAULT partition. a child partition is skipped if
+// the DEFAULT partition has rows of its range, these rows stay in the DEFAULT
+// partition
+func createPartitions(ctx context.Context, db sqlx.DBExecutor, s *Schema, now time.Time) error {
+	for _, t := range s.Tables {
+		if t.Partitioning == nil || t.Partitioning.Interval == "" {
+			continue
+		}
+		ranges, err := partitionRanges(t.Name, t.Partitioning.Interval, now)
+		if err != nil {
+			return err
+		}
+		_, err = db.ExecContext(ctx, fmt.Sprintf(
+			"CREATE TABLE IF NOT EXISTS %s.%s_pdefault PARTITION OF %s.%s DEFAULT",
+			s.Name, t.Name, s.Name, t.Name,
+		))
+		if err != nil {
+			return errors.Wrapf(err, "create default partition of %s", t.Name)
+		}
+		for _, r := range ranges {
+			_, err = db.ExecContext(ctx, fmt.Sprintf(
+				"CREATE TABLE IF NOT EXISTS %s.%s PARTITION OF %s.%s FOR VALUES FROM (%d) TO (%d)",
+				s.Name, r.Name, s.Name, t.Name, r.From.UnixMilli(), r.To.UnixMilli(),
+			))
+			if err != nil && !isCheckViolation(err) {
+				return errors.Wrapf(err, "create partition %s", r.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// isCheckViolation reports if err is `check_violation`(23514), which is raised
+// when DEFAULT partition has rows of the partition creating
+func isCheckViolation(err error) bool {
+	e, ok := sqlx.UnwrapAll(err).(*pq.Error)
+	return ok && e.Code == "23514"
+}
+
+// isUniqueViolation reports if err is `unique_violation`(23505), which is raised
+// when the version recorded concurrently
+func isUniqueViolation(err error) bool {
+	e, ok := sqlx.UnwrapAll(err).(*pq.Error)
+	return ok && e.Code == "23505"
+}
+
 type Column struct {
 	// Name column name
 	Name string `json:"name"`
@@ -107,10 +492,14 @@ func (c Column) Datatype(t enums.WasmDBDatatype) string {
 		return "boolean"
 	case enums.WASM_DB_DATATYPE__TIMESTAMP:
 		return "bigint"
+	case enums.WASM_DB_DATATYPE__TIMESTAMPTZ:
+		return "timestamptz"
 	case enums.WASM_DB_DATATYPE__DECIMAL:
 		return "decimal"
 	case enums.WASM_DB_DATATYPE__NUMERIC:
 		return "numeric"
+	case enums.WASM_DB_DATATYPE__TSVECTOR:
+		return "tsvector"
 	default:
 		panic(fmt.Errorf("unsupport type: %v", t.String()))
 	}
@@ -128,6 +517,7 @@ func (c *Column) Build() *builder.Column {
 		AutoIncrement: dt.AutoIncrement,
 		Comment:       dt.Desc,
 		Desc:          []string{dt.Desc},
+		Check:         dt.CheckExpr,
 	}
 	if dt.Default != nil && len(*dt.Default) == 0 {
 		*dt.Default = "''"
@@ -135,6 +525,50 @@ func (c *Column) Build() *builder.Column {
 	return col
 }
 
+var checkExprKeywords = map[string]bool{
+	"and": true, "or": true, "not": true, "is": true, "null": true,
+	"true": true, "false": true, "in": true, "between": true, "like": true,
+	"ilike": true, "similar": true, "to": true, "escape": true, "case": true,
+	"when": true, "then": true, "else": true, "end": true,
+}
+
+// ValidateCheckExpr checks that the CHECK constraint expr only references the
+// column itself. function names, type names after `::`, keywords and quoted
+// literals are skipped
+func (c *Column) ValidateCheckExpr() error {
+	expr := c.Constrains.CheckExpr
+	for i := 0; i < len(expr); {
+		ch := expr[i]
+		switch {
+		case ch == '\'':
+			end := strings.IndexByte(expr[i+1:], '\'')
+			if end < 0 {
+				return errors.Errorf("column %s: unterminated literal in check expr", c.Name)
+			}
+			i += end + 2
+		case ch == '_' || unicode.IsLetter(rune(ch)):
+			j := i
+			for j < len(expr) && (expr[j] == '_' || unicode.IsLetter(rune(expr[j])) || unicode.IsDigit(rune(expr[j]))) {
+				j++
+			}
+			ident := expr[i:j]
+			isFunc := strings.HasPrefix(strings.TrimLeft(expr[j:], " "), "(")
+			isType := strings.HasSuffix(strings.TrimRight(expr[:i], " "), "::")
+			if !isFunc && !isType && !checkExprKeywords[strings.ToLower(ident)] && ident != c.Name {
+				return errors.Errorf("column %s: check expr references `%s`", c.Name, ident)
+			}
+			i = j
+		case unicode.IsDigit(rune(ch)):
+			for i < len(expr) && (expr[i] == '.' || unicode.IsDigit(rune(expr[i]))) {
+				i++
+			}
+		default:
+			i++
+		}
+	}
+	return nil
+}
+
 type Constrains struct {
 	Datatype      enums.WasmDBDatatype `json:"datatype"`
 	Length        uint64               `json:"length,omitempty"`
@@ -143,14 +577,20 @@ type Constrains struct {
 	Null          bool                 `json:"null,omitempty"`
 	AutoIncrement bool                 `json:"autoincrement,omitempty"`
 	Desc          string               `json:"desc,omitempty"`
+	// CheckExpr CHECK constraint expr which only references the column itself,
+	// eg: `value > 0 AND value < 1000`
+	CheckExpr string `json:"checkExpr,omitempty"`
 }
 
 type Key struct {
-	Name        string   `json:"name,omitempty"`
+	Name string `json:"name,omitempty"`
+	// Method index method, eg: `btree`, `hash`, `gin` for TSVECTOR columns
 	Method      string   `json:"method,omitempty"`
 	IsUnique    bool     `json:"isUnique,omitempty"`
 	ColumnNames []string `json:"columnNames"`
 	Expr        string   `json:"expr,omitempty"`
+	// IncludeColumns non-key columns of covering index
+	IncludeColumns []string `json:"includeColumns,omitempty"`
 }
 
 func (k *Key) Build(tblName string) *builder.Key {
@@ -173,6 +613,7 @@ func (k *Key) Build(tblName string) *builder.Key {
 		Def: builder.IndexDef{
 			ColNames: k.ColumnNames,
 			Expr:     k.Expr,
+			Include:  k.IncludeColumns,
 		},
 	}
 }
@@ -190,15 +631,14 @@ func (d *Database) WithSchema(name string) (db sqlx.DBExecutor, err error) {
 		name = "public"
 	}
 
-	if _, ok := d.schemas[name]; !ok {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	pool, ok := d.pools[name]
+	if !ok {
 		return nil, errors.Errorf("schema %s not found in database %s", name, d.Name)
 	}
-	db = d.ep
-	_, err = db.Exec(builder.Expr("SET SEARCH_PATH TO " + name))
-	if err != nil {
-		return nil, errors.Errorf("switch schema failed: %v", err)
-	}
-	return db, nil
+	return pool, nil
 }
 
 func (d *Database) WithDefaultSchema() (sqlx.DBExecutor, error) {
@@ -211,26 +651,8 @@ func (d *Database) Init(parent context.Context) (err error) {
 
 	// init database endpoint
 	prj := types.MustProjectFromContext(parent)
-	d.Name = prj.DatabaseName()
-
-	// clone config and init config
 	cfg := types.MustWasmDBConfigFromContext(parent)
-	ep := cfg.Endpoint
-	ep.Base = d.Name
-
-	if ep.Param == nil {
-		ep.Param = make(url.Values)
-	}
-	ep.Param["sslmode"] = []string{"disable"}
-	ep.Param["application_name"] = []string{d.Name}
-	d.ep = &confpostgres.Endpoint{
-		Master:          ep,
-		Database:        sqlx.NewDatabase(d.Name),
-		Retry:           retry.Default,
-		PoolSize:        cfg.PoolSize,
-		ConnMaxLifetime: cfg.ConnMaxLifetime,
-	}
-	d.ep.SetDefault()
+	d.setEndpoint(prj, cfg, cfg.PoolSize, nil)
 
 	if d.schemas == nil {
 		d.schemas = make(map[string]*Schema)
@@ -248,11 +670,39 @@ func (d *Database) Init(parent context.Context) (err error) {
 			d.schemas[s.Name] = &Schema{Name: s.Name}
 		}
 
+		for _, t := range s.Tables {
+			for _, c := range t.Cols {
+				if err = c.ValidateCheckExpr(); err != nil {
+					return errors.Wrapf(err, "table %s", t.Name)
+				}
+			}
+			if err = t.ValidatePartitioning(); err != nil {
+				return errors.Wrapf(err, "table %s", t.Name)
+			}
+			if err = t.ValidateFTS(); err != nil {
+				return errors.Wrapf(err, "table %s", t.Name)
+			}
+		}
+
 		d.schemas[s.Name].Tables = append(d.schemas[s.Name].Tables, s.Tables...)
 	}
 
-	if err = d.ep.Init(); err != nil {
-		return err
+	delay := cfg.RetryBaseDelay.Duration()
+	for attempt := 0; ; attempt++ {
+		if err = d.ep.Init(); err == nil {
+			break
+		}
+		remaining := cfg.MaxRetries - attempt
+		if remaining <= 0 {
+			return errors.Wrapf(err, "connect database %s", d.Name)
+		}
+		l.WithValues("remaining_retries", remaining).Warn(err)
+		select {
+		case <-parent.Done():
+			return errors.Wrapf(parent.Err(), "connect database %s", d.Name)
+		case <-time.After(delay):
+		}
+		delay *= 2
 	}
 
 	// create project database user and grant privileges
@@ -278,12 +728,759 @@ func (d *Database) Init(parent context.Context) (err error) {
 		}
 		db := ep.WithSchema(s.Name)
 		l := l.WithValues("schema", s.Name)
+		// create the missing tables first, so a partially initialized schema
+		// is diffed by migration on existed tables only
+		if err = backfillSchema(parent, db, s); err != nil {
+			l.Error(err)
+			return err
+		}
 		if err = migration.Migrate(db, nil); err != nil {
 			l.Error(err)
 			return err
 		}
+		if err = createPartitions(parent, db, s, time.Now()); err != nil {
+			l.Error(err)
+			return err
+		}
+		if err = createFTSTriggers(parent, db, s); err != nil {
+			l.Error(err)
+			return err
+		}
+		if err = recordMigration(parent, db, s); err != nil {
+			l.Error(err)
+			return err
+		}
 		l.Info("migrated")
 	}
 
+	// init connection pool of each schema
+	d.closePools()
+	d.pools = make(map[string]*confpostgres.Endpoint)
+	d.poolSize = cfg.MaxConnection
+	if d.poolSize <= 0 {
+		d.poolSize = cfg.PoolSize
+	}
+	for name := range d.schemas {
+		if err = d.initPool(name); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
+
+// setEndpoint sets the endpoint of project database by cfg, param is added to
+// the connection parameters
+func (d *Database) setEndpoint(prj *models.Project, cfg *types.WasmDBConfig, poolSize int, param url.Values) {
+	d.Name = prj.DatabaseName()
+	d.project = prj.Name
+
+	// clone config and init config
+	ep := cfg.Endpoint
+	ep.Base = d.Name
+	ep.Param = make(url.Values)
+	for k, v := range cfg.Endpoint.Param {
+		ep.Param[k] = v
+	}
+	for k, v := range param {
+		ep.Param[k] = v
+	}
+	ep.Param["sslmode"] = []string{"disable"}
+	ep.Param["application_name"] = []string{d.Name}
+	d.ep = &confpostgres.Endpoint{
+		Master:          ep,
+		Database:        sqlx.NewDatabase(d.Name),
+		Retry:           &retry.Retry{}, // retried with backoff by Init
+		PoolSize:        poolSize,
+		ConnMaxLifetime: cfg.ConnMaxLifetime,
+	}
+	d.ep.SetDefault()
+}
+
+// Open connects to the database initialized before by a single read only
+// connection, it doesn't create users, migrate schemas or init schema pools.
+// it is used by the maintenance tasks, such as statistics and snapshot
+func (d *Database) Open(ctx context.Context) error {
+	prj := types.MustProjectFromContext(ctx)
+	cfg := types.MustWasmDBConfigFromContext(ctx)
+	d.setEndpoint(prj, cfg, 1, url.Values{
+		"options": []string{"-c default_transaction_read_only=on"},
+	})
+
+	d.schemas = make(map[string]*Schema)
+	for _, s := range d.Schemas {
+		name := s.Name
+		if name == "" {
+			name = "public"
+		}
+		if _, ok := d.schemas[name]; !ok {
+			d.schemas[name] = &Schema{Name: name}
+		}
+		d.schemas[name].Tables = append(d.schemas[name].Tables, s.Tables...)
+	}
+
+	if err := d.ep.Init(); err != nil {
+		return errors.Wrapf(err, "connect database %s", d.Name)
+	}
+	return nil
+}
+
+// initPool inits the connection pool of schema, the schemas share poolSize
+// connections and each pool has one connection at least
+func (d *Database) initPool(name string) error {
+	size := d.poolSize / len(d.schemas)
+	if size < 1 {
+		size = 1
+	}
+	pool := &confpostgres.Endpoint{
+		Master:          d.ep.Master,
+		Database:        sqlx.NewDatabase(d.Name).WithSchema(name),
+		Retry:           d.ep.Retry,
+		PoolSize:        size,
+		ConnMaxLifetime: d.ep.ConnMaxLifetime,
+	}
+	pool.SetDefault()
+	if err := pool.Init(); err != nil {
+		return errors.Wrapf(err, "init pool of schema %s", name)
+	}
+	d.pools[name] = pool
+	return nil
+}
+
+// AddSchema merges schemas into the initialized database and migrates the new
+// schemas, tables, columns and keys. the definitions existed are not changed,
+// so it never drops or modifies anything
+func (d *Database) AddSchema(ctx context.Context, schemas ...*Schema) error {
+	if d.ep == nil {
+		return errors.Errorf("database %s is not initialized", d.Name)
+	}
+
+	for _, s := range schemas {
+		for _, t := range s.Tables {
+			for _, c := range t.Cols {
+				if err := c.ValidateCheckExpr(); err != nil {
+					return errors.Wrapf(err, "table %s", t.Name)
+				}
+			}
+			if err := t.ValidatePartitioning(); err != nil {
+				return errors.Wrapf(err, "table %s", t.Name)
+			}
+			if err := t.ValidateFTS(); err != nil {
+				return errors.Wrapf(err, "table %s", t.Name)
+			}
+		}
+	}
+
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	for _, s := range schemas {
+		if s.Name == "" {
+			s.Name = "public"
+		}
+		prev, ok := d.schemas[s.Name]
+		if !ok {
+			prev = &Schema{Name: s.Name}
+		}
+		merged := prev.merge(s)
+
+		target := sqlx.NewDatabase(d.Name)
+		for _, t := range merged.Tables {
+			target.AddTable(t.Build())
+		}
+		exec := *d.ep.DB
+		exec.Database = target
+		db := exec.WithSchema(s.Name)
+		if err := migration.Migrate(db, nil); err != nil {
+			return errors.Wrapf(err, "migrate schema %s", s.Name)
+		}
+		if err := createPartitions(ctx, db, merged, time.Now()); err != nil {
+			return err
+		}
+		if err := createFTSTriggers(ctx, db, merged); err != nil {
+			return err
+		}
+		if err := recordMigration(ctx, db, merged); err != nil {
+			return err
+		}
+
+		d.schemas[s.Name] = merged
+		d.setSchema(merged)
+		if _, ok = d.pools[s.Name]; !ok {
+			if err := d.initPool(s.Name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// setSchema replaces the definitions of schema in Schemas by s, so Schemas is
+// the definition merged at runtime
+func (d *Database) setSchema(s *Schema) {
+	schemas := make([]*Schema, 0, len(d.Schemas)+1)
+	for _, v := range d.Schemas {
+		name := v.Name
+		if name == "" {
+			name = "public"
+		}
+		if name != s.Name {
+			schemas = append(schemas, v)
+		}
+	}
+	d.Schemas = append(schemas, s)
+}
+
+// Definition returns the json definition of database including the schemas
+// merged by AddSchema, it should be persisted as the project database config
+// to keep the runtime migrations after redeploying
+func (d *Database) Definition() ([]byte, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+	return json.Marshal(d)
+}
+
+// BackfillSchema creates the tables of schema which are not existed in
+// database and records the migration, the existed tables are skipped entirely
+// without diffing their columns and keys. it is used to recover a partially
+// initialized database
+func (d *Database) BackfillSchema(ctx context.Context, schemaName string) error {
+	if d.ep == nil {
+		return errors.Errorf("database %s is not initialized", d.Name)
+	}
+	if schemaName == "" {
+		schemaName = "public"
+	}
+
+	d.mtx.RLock()
+	s, ok := d.schemas[schemaName]
+	d.mtx.RUnlock()
+	if !ok {
+		return errors.Errorf("schema %s is not defined", schemaName)
+	}
+
+	if err := backfillSchema(ctx, d.ep, s); err != nil {
+		return err
+	}
+	return recordMigration(ctx, d.ep, s)
+}
+
+// backfillSchema creates the tables of s which are not existed in database
+func backfillSchema(ctx context.Context, db sqlx.DBExecutor, s *Schema) error {
+	rows, err := db.QueryContext(ctx,
+		"SELECT table_name FROM information_schema.tables WHERE table_schema = $1", s.Name)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	existed := make(map[string]bool)
+	for rows.Next() {
+		name := ""
+		if err = rows.Scan(&name); err != nil {
+			return err
+		}
+		existed[name] = true
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	missing := &Schema{Name: s.Name}
+	for _, t := range s.Tables {
+		if !existed[t.Name] {
+			missing.Tables = append(missing.Tables, t)
+		}
+	}
+	if len(missing.Tables) == 0 {
+		return nil
+	}
+
+	dialect := db.Dialect()
+	tasks := sqlx.NewTasks(db).With(func(db sqlx.DBExecutor) error {
+		_, err := db.ExecContext(ctx, fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", s.Name))
+		return err
+	})
+	for _, t := range missing.Tables {
+		for _, expr := range dialect.CreateTableIsNotExists(t.Build().WithSchema(s.Name)) {
+			expr := expr
+			tasks = tasks.With(func(db sqlx.DBExecutor) error {
+				_, err := db.Exec(expr)
+				return err
+			})
+		}
+	}
+	if err = tasks.Do(); err != nil {
+		return errors.Wrapf(err, "backfill schema %s", s.Name)
+	}
+	if err = createPartitions(ctx, db, missing, time.Now()); err != nil {
+		return err
+	}
+	return createFTSTriggers(ctx, db, missing)
+}
+
+// SoftDeleteTables returns the names of soft delete tables of schema
+func (d *Database) SoftDeleteTables(schema string) []string {
+	if schema == "" {
+		schema = "public"
+	}
+
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	s, ok := d.schemas[schema]
+	if !ok {
+		return nil
+	}
+	var names []string
+	for _, t := range s.Tables {
+		if t.SoftDelete {
+			names = append(names, t.Name)
+		}
+	}
+	return names
+}
+
+// TableColumns returns the column names of table in schema, ok is false if
+// the table is not defined
+func (d *Database) TableColumns(schema, table string) (cols []string, ok bool) {
+	if schema == "" {
+		schema = "public"
+	}
+
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	s, ok := d.schemas[schema]
+	if !ok {
+		return nil, false
+	}
+	for _, t := range s.Tables {
+		if t.Name != table {
+			continue
+		}
+		for _, c := range t.Cols {
+			cols = append(cols, c.Name)
+		}
+		if t.SoftDelete {
+			cols = append(cols, SoftDeleteColumn, SoftDeleteAtColumn)
+		}
+		return cols, true
+	}
+	return nil, false
+}
+
+// UniqueColumn returns the datatype of column col if it is the only column of
+// a unique key (including primary key) of table, the column values are
+// distinct, so it can be used as the cursor of keyset pagination
+func (d *Database) UniqueColumn(schema, table, col string) (enums.WasmDBDatatype, bool) {
+	if schema == "" {
+		schema = "public"
+	}
+
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	s, ok := d.schemas[schema]
+	if !ok {
+		return 0, false
+	}
+	for _, t := range s.Tables {
+		if t.Name != table {
+			continue
+		}
+		unique := false
+		for _, k := range t.Keys {
+			if k.IsUnique && k.Expr == "" && len(k.ColumnNames) == 1 && k.ColumnNames[0] == col {
+				unique = true
+				break
+			}
+		}
+		if !unique {
+			return 0, false
+		}
+		for _, c := range t.Cols {
+			if c.Name == col {
+				return c.Constrains.Datatype, true
+			}
+		}
+		return 0, false
+	}
+	return 0, false
+}
+
+// merge returns a copy of schema with the tables, columns and keys of s which
+// are not defined yet
+func (schema *Schema) merge(s *Schema) *Schema {
+	ret := &Schema{Name: schema.Name}
+	tables := make(map[string]*Table)
+	for _, t := range schema.Tables {
+		cp := *t
+		cp.Cols = append([]*Column{}, t.Cols...)
+		cp.Keys = append([]*Key{}, t.Keys...)
+		tables[t.Name] = &cp
+		ret.Tables = append(ret.Tables, &cp)
+	}
+
+	for _, t := range s.Tables {
+		prev, ok := tables[t.Name]
+		if !ok {
+			tables[t.Name] = t
+			ret.Tables = append(ret.Tables, t)
+			continue
+		}
+		prev.SoftDelete = prev.SoftDelete || t.SoftDelete
+		for _, c := range t.Cols {
+			if !hasColumn(prev, c.Name) {
+				prev.Cols = append(prev.Cols, c)
+			}
+		}
+		for _, k := range t.Keys {
+			if !hasKey(prev, k.Name) {
+				prev.Keys = append(prev.Keys, k)
+			}
+		}
+	}
+	return ret
+}
+
+func hasColumn(t *Table, name string) bool {
+	for _, c := range t.Cols {
+		if strings.EqualFold(c.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasKey(t *Table, name string) bool {
+	for _, k := range t.Keys {
+		if strings.EqualFold(k.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// SchemaMigrationsTable records migration history of each schema, a new version
+// is appended when tables definition of schema changed
+const SchemaMigrationsTable = "schema_migrations"
+
+var ErrNoMigrationHistory = errors.New("no migration history")
+
+// maxRecordMigrationAttempts the max attempts of recording a migration version
+const maxRecordMigrationAttempts = 5
+
+// schemaChecksum returns the md5 checksum of tables definition of schema. the
+// tables, columns, keys and fts columns are sorted by name, so the checksum is
+// not changed by their definition order, such as schemas merged at runtime
+// and loaded from config after redeploying
+func schemaChecksum(s *Schema) (string, error) {
+	tables := make([]*Table, 0, len(s.Tables))
+	for _, t := range s.Tables {
+		cp := *t
+		cp.Cols = append([]*Column{}, t.Cols...)
+		sort.SliceStable(cp.Cols, func(i, j int) bool { return cp.Cols[i].Name < cp.Cols[j].Name })
+		cp.Keys = append([]*Key{}, t.Keys...)
+		sort.SliceStable(cp.Keys, func(i, j int) bool {
+			return cp.Keys[i].Build(t.Name).Name < cp.Keys[j].Build(t.Name).Name
+		})
+		cp.FTS = append([]*FTSColumn{}, t.FTS...)
+		sort.SliceStable(cp.FTS, func(i, j int) bool { return cp.FTS[i].Name < cp.FTS[j].Name })
+		tables = append(tables, &cp)
+	}
+	sort.SliceStable(tables, func(i, j int) bool { return tables[i].Name < tables[j].Name })
+
+	content, err := json.Marshal(tables)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", md5.Sum(content)), nil
+}
+
+func recordMigration(ctx context.Context, db sqlx.DBExecutor, s *Schema) error {
+	checksum, err := schemaChecksum(s)
+	if err != nil {
+		return err
+	}
+
+	tbl := s.Name + "." + SchemaMigrationsTable
+	if _, err = db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+tbl+
+		" (version bigint PRIMARY KEY, checksum character varying(32) NOT NULL,"+
+		" applied_at timestamp with time zone NOT NULL DEFAULT now())"); err != nil {
+		return errors.Wrap(err, "create migration table")
+	}
+	// the version is allocated by MAX(version)+1, the instances migrating
+	// concurrently may allocate the same version, and the loser violates the
+	// primary key and retries. the checksum recorded by the winner is checked
+	// when retrying, so the same definition is not recorded twice
+	for attempt := 1; ; attempt++ {
+		_, err = db.ExecContext(ctx, "INSERT INTO "+tbl+" (version, checksum)"+
+			" SELECT COALESCE(MAX(version), 0) + 1, $1::text FROM "+tbl+
+			" HAVING COALESCE((SELECT checksum FROM "+tbl+" ORDER BY version DESC LIMIT 1), '') <> $1::text",
+			checksum,
+		)
+		if err == nil || !isUniqueViolation(err) || attempt >= maxRecordMigrationAttempts {
+			return errors.Wrap(err, "record migration")
+		}
+	}
+}
+
+// SchemaVersion returns the latest migration version of schema, it returns
+// ErrNoMigrationHistory if schema was never migrated
+func (d *Database) SchemaVersion(ctx context.Context, schema string) (int64, error) {
+	if schema == "" {
+		schema = "public"
+	}
+	db, err := d.WithSchema(schema)
+	if err != nil {
+		return 0, err
+	}
+
+	tbl := schema + "." + SchemaMigrationsTable
+
+	var exists bool
+	if err = queryRow(ctx, db, &exists, "SELECT to_regclass($1) IS NOT NULL", tbl); err != nil {
+		return 0, err
+	}
+	if !exists {
+		return 0, ErrNoMigrationHistory
+	}
+
+	var version sql.NullInt64
+	if err = queryRow(ctx, db, &version, "SELECT MAX(version) FROM "+tbl); err != nil {
+		return 0, err
+	}
+	if !version.Valid {
+		return 0, ErrNoMigrationHistory
+	}
+	return version.Int64, nil
+}
+
+func queryRow(ctx context.Context, db sqlx.DBExecutor, dst interface{}, query string, args ...interface{}) error {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err = rows.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+	return rows.Scan(dst)
+}
+
+// IndexStat usage statistics of index
+type IndexStat struct {
+	SchemaName    string `json:"schemaName"`
+	TableName     string `json:"tableName"`
+	IndexName     string `json:"indexName"`
+	IndexScans    int64  `json:"indexScans"`
+	TuplesFetched int64  `json:"tuplesFetched"`
+}
+
+// IndexStats returns the usage statistics of user indexes from
+// pg_stat_user_indexes, the statistics are accumulated since the last reset
+func (d *Database) IndexStats(ctx context.Context) ([]IndexStat, error) {
+	rows, err := d.ep.QueryContext(ctx,
+		"SELECT schemaname, relname, indexrelname, idx_scan, idx_tup_fetch "+
+			"FROM pg_stat_user_indexes ORDER BY schemaname, relname, indexrelname")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := make([]IndexStat, 0)
+	for rows.Next() {
+		s := IndexStat{}
+		if err = rows.Scan(&s.SchemaName, &s.TableName, &s.IndexName, &s.IndexScans, &s.TuplesFetched); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+// TableIndex index definition of table from pg_indexes
+type TableIndex struct {
+	IndexName  string `json:"indexname"`
+	IndexDef   string `json:"indexdef"`
+	Tablespace string `json:"tablespace"`
+}
+
+// TableIndexes returns the indexes of table, the table must be registered in
+// the schemas of database
+func (d *Database) TableIndexes(ctx context.Context, schema, table string) ([]TableIndex, error) {
+	if d.ep == nil {
+		return nil, errors.Errorf("database %s is not initialized", d.Name)
+	}
+	if schema == "" {
+		schema = "public"
+	}
+	if _, ok := d.TableColumns(schema, table); !ok {
+		return nil, errors.Errorf("table %s.%s not found in database %s", schema, table, d.Name)
+	}
+
+	rows, err := d.ep.QueryContext(ctx,
+		"SELECT indexname, indexdef, tablespace FROM pg_indexes "+
+			"WHERE schemaname = $1 AND tablename = $2 ORDER BY indexname",
+		schema, table,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	indexes := make([]TableIndex, 0)
+	for rows.Next() {
+		i, space := TableIndex{}, sql.NullString{}
+		if err = rows.Scan(&i.IndexName, &i.IndexDef, &space); err != nil {
+			return nil, err
+		}
+		i.Tablespace = space.String
+		indexes = append(indexes, i)
+	}
+	return indexes, rows.Err()
+}
+
+// CollectTableRows reads the estimated rows of registered tables from
+// pg_class and emits them by gauge w3bstream_db_table_rows. the tables never
+// analyzed are skipped
+func (d *Database) CollectTableRows(ctx context.Context) error {
+	d.mtx.RLock()
+	ep := d.ep
+	tables := make([][2]string, 0)
+	for _, s := range d.schemas {
+		for _, t := range s.Tables {
+			tables = append(tables, [2]string{s.Name, t.Name})
+		}
+	}
+	d.mtx.RUnlock()
+
+	if ep == nil {
+		return errors.Errorf("database %s is not initialized", d.Name)
+	}
+	for _, t := range tables {
+		var rows float64
+		err := queryRow(ctx, ep, &rows,
+			"SELECT c.reltuples FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace"+
+				" WHERE n.nspname = $1 AND c.relname = $2",
+			t[0], t[1],
+		)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if rows < 0 { // -1 if table was never analyzed
+			continue
+		}
+		metrics.DBTableRowsMtc.WithLabelValues(d.project, t[0], t[1]).Set(rows)
+	}
+	return nil
+}
+
+// Close closes the connections of database endpoint and schema pools
+func (d *Database) Close() error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	d.closePools()
+	d.pools = nil
+	if d.ep != nil {
+		if c, ok := d.ep.SqlExecutor.(io.Closer); ok {
+			return c.Close()
+		}
+	}
+	return nil
+}
+
+func (d *Database) closePools() {
+	for _, pool := range d.pools {
+		if c, ok := pool.SqlExecutor.(io.Closer); ok {
+			_ = c.Close()
+		}
+	}
+}
+
+// ResetSequences restarts the sequences of all auto increment columns in the
+// registered schemas. it is only allowed when BuildMode is BuildModeTesting
+func (d *Database) ResetSequences(ctx context.Context) error {
+	if BuildMode != BuildModeTesting {
+		return errors.Errorf("reset sequences is not allowed in build mode: '%s'", BuildMode)
+	}
+	if d.ep == nil {
+		return errors.Errorf("database %s is not initialized", d.Name)
+	}
+
+	for _, s := range d.schemas {
+		for _, t := range s.Tables {
+			for _, c := range t.Cols {
+				if !c.Constrains.AutoIncrement {
+					continue
+				}
+				seq := fmt.Sprintf("%s.%s_%s_seq", s.Name, t.Name, c.Name)
+				if _, err := d.ep.ExecContext(ctx, "ALTER SEQUENCE "+seq+" RESTART WITH 1"); err != nil {
+					return errors.Wrapf(err, "reset sequence %s", seq)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// connURL url of connecting to database without password
+func (d *Database) connURL() *url.URL {
+	ep := d.ep.Master
+	q := url.Values{}
+	for k, v := range ep.Param {
+		q[k] = v
+	}
+	u := &url.URL{
+		Scheme:   "postgres",
+		Host:     ep.Host(),
+		Path:     "/" + d.Name,
+		RawQuery: q.Encode(),
+	}
+	if ep.Username != "" {
+		u.User = url.User(ep.Username)
+	}
+	return u
+}
+
+// Snapshot dumps the database by pg_dump and returns the gzip compressed sql
+// stream. the dump process is killed when ctx is canceled. the password is
+// passed by environment, so it is not exposed in process list
+func (d *Database) Snapshot(ctx context.Context) (io.ReadCloser, error) {
+	if d.ep == nil {
+		return nil, errors.Errorf("database %s is not initialized", d.Name)
+	}
+
+	cmd := exec.CommandContext(ctx, "pg_dump", "--dbname", d.connURL().String())
+	cmd.Env = append(os.Environ(), "PGPASSWORD="+d.ep.Master.Password.String())
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err = cmd.Start(); err != nil {
+		return nil, errors.Wrap(err, "start pg_dump")
+	}
+
+	r, w := io.Pipe()
+	go func() {
+		gw := gzip.NewWriter(w)
+		_, err := io.Copy(gw, stdout)
+		if err == nil {
+			err = gw.Close()
+		}
+		if werr := cmd.Wait(); err == nil && werr != nil {
+			err = errors.Wrap(werr, "pg_dump")
+		}
+		_ = w.CloseWithError(err)
+	}()
+	return r, nil
+}
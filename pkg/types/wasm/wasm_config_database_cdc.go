@@ -0,0 +1,228 @@
+package wasm
+
+import (
+	"context"
+	"crypto/md5"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgproto3/v2"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	"github.com/machinefi/w3bstream/pkg/types/wasm/sql_util"
+)
+
+const (
+	// MaxTableChangeWatches max concurrent table change watches of a project
+	MaxTableChangeWatches = 5
+	// standbyStatusInterval interval of reporting the consumed wal position,
+	// which should be less than `wal_sender_timeout` of server
+	standbyStatusInterval = 10 * time.Second
+	// tableChangeCleanupTimeout timeout of closing replication connection and
+	// dropping publication
+	tableChangeCleanupTimeout = 5 * time.Second
+)
+
+var ErrTooManyTableChangeWatches = errors.Errorf("table change watches exceed %d", MaxTableChangeWatches)
+
+// WatchTableChanges streams row changes of table to fn until ctx is done, or
+// the stream fails and onErr is called with the error. changes are decoded by
+// pgoutput from a temporary logical replication slot created on a dedicated
+// replication connection, so the slot is dropped by server when connection is
+// closed. the publication of the watch is dropped when the watch ends. it
+// requires `wal_level=logical` and the REPLICATION attribute of database user
+func (d *Database) WatchTableChanges(ctx context.Context, schema, table string, fn func(*sql_util.TableChange), onErr func(error)) error {
+	if d.ep == nil {
+		return errors.Errorf("database %s is not initialized", d.Name)
+	}
+	if schema == "" {
+		schema = "public"
+	}
+	if _, ok := d.TableColumns(schema, table); !ok {
+		return errors.Errorf("table %s.%s not found in database %s", schema, table, d.Name)
+	}
+
+	d.mtx.Lock()
+	if d.watches >= MaxTableChangeWatches {
+		d.mtx.Unlock()
+		return ErrTooManyTableChangeWatches
+	}
+	d.watches++
+	d.mtx.Unlock()
+
+	release := func() {
+		d.mtx.Lock()
+		d.watches--
+		d.mtx.Unlock()
+	}
+
+	// slot and publication are named by watch, so they can be dropped without
+	// affecting the other watches of the same table
+	name := fmt.Sprintf("ws_cdc_%x", md5.Sum([]byte(d.Name+uuid.NewString())))[:23]
+
+	_, err := d.ep.ExecContext(ctx, fmt.Sprintf(
+		"CREATE PUBLICATION %s FOR TABLE %s.%s",
+		name, pq.QuoteIdentifier(schema), pq.QuoteIdentifier(table),
+	))
+	if err != nil {
+		release()
+		return errors.Wrap(err, "create publication")
+	}
+
+	conn, err := pgconn.Connect(ctx, d.replicationDSN())
+	if err == nil {
+		err = startReplication(ctx, conn, name, name)
+		if err != nil {
+			closeReplicationConn(conn)
+		}
+	}
+	if err != nil {
+		d.dropPublication(name)
+		release()
+		return err
+	}
+
+	go func() {
+		defer release()
+		defer d.dropPublication(name)
+		defer closeReplicationConn(conn)
+
+		if err := streamTableChanges(ctx, conn, fn); err != nil && ctx.Err() == nil {
+			onErr(err)
+		}
+	}()
+	return nil
+}
+
+// replicationDSN dsn of logical replication connection to database
+func (d *Database) replicationDSN() string {
+	u := d.connURL()
+	q := u.Query()
+	q.Set("replication", "database")
+	u.RawQuery = q.Encode()
+	if u.User != nil {
+		u.User = url.UserPassword(u.User.Username(), d.ep.Master.Password.String())
+	}
+	return u.String()
+}
+
+func (d *Database) dropPublication(name string) {
+	ctx, cancel := context.WithTimeout(context.Background(), tableChangeCleanupTimeout)
+	defer cancel()
+	_, _ = d.ep.ExecContext(ctx, "DROP PUBLICATION IF EXISTS "+name)
+}
+
+func closeReplicationConn(conn *pgconn.PgConn) {
+	ctx, cancel := context.WithTimeout(context.Background(), tableChangeCleanupTimeout)
+	defer cancel()
+	_ = conn.Close(ctx)
+}
+
+// startReplication creates temporary slot and starts streaming from it, the
+// connection is in copy both mode if succeeded
+func startReplication(ctx context.Context, conn *pgconn.PgConn, slot, pub string) error {
+	_, err := conn.Exec(ctx, fmt.Sprintf(
+		"CREATE_REPLICATION_SLOT %s TEMPORARY LOGICAL pgoutput NOEXPORT_SNAPSHOT", slot,
+	)).ReadAll()
+	if err != nil {
+		return errors.Wrap(err, "create replication slot")
+	}
+
+	q := &pgproto3.Query{String: fmt.Sprintf(
+		"START_REPLICATION SLOT %s LOGICAL 0/0 (proto_version '1', publication_names '%s')", slot, pub,
+	)}
+	if err = conn.SendBytes(ctx, q.Encode(nil)); err != nil {
+		return errors.Wrap(err, "start replication")
+	}
+	for {
+		msg, err := conn.ReceiveMessage(ctx)
+		if err != nil {
+			return errors.Wrap(err, "start replication")
+		}
+		switch m := msg.(type) {
+		case *pgproto3.CopyBothResponse:
+			return nil
+		case *pgproto3.ErrorResponse:
+			return errors.Wrap(pgconn.ErrorResponseToPgError(m), "start replication")
+		case *pgproto3.NoticeResponse, *pgproto3.ParameterStatus:
+		default:
+			return errors.Errorf("start replication: unexpected message %T", msg)
+		}
+	}
+}
+
+// streamTableChanges calls fn with each change received until ctx is done.
+// the consumed position is reported after the changes before it are handled,
+// which allows server to recycle the wal
+func streamTableChanges(ctx context.Context, conn *pgconn.PgConn, fn func(*sql_util.TableChange)) error {
+	var (
+		dec      = sql_util.NewPgOutputDecoder()
+		lsn      uint64
+		deadline = time.Now().Add(standbyStatusInterval)
+	)
+
+	for {
+		if !time.Now().Before(deadline) {
+			status := &pgproto3.CopyData{Data: sql_util.StandbyStatusUpdate(lsn, time.Now())}
+			if err := conn.SendBytes(ctx, status.Encode(nil)); err != nil {
+				return errors.Wrap(err, "send standby status")
+			}
+			deadline = time.Now().Add(standbyStatusInterval)
+		}
+
+		rctx, cancel := context.WithDeadline(ctx, deadline)
+		msg, err := conn.ReceiveMessage(rctx)
+		cancel()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if pgconn.Timeout(err) {
+				continue
+			}
+			return errors.Wrap(err, "receive replication message")
+		}
+
+		switch m := msg.(type) {
+		case *pgproto3.CopyData:
+			if len(m.Data) == 0 {
+				continue
+			}
+			switch m.Data[0] {
+			case sql_util.PrimaryKeepaliveMessageByteID:
+				k, err := sql_util.ParsePrimaryKeepalive(m.Data[1:])
+				if err != nil {
+					return err
+				}
+				// all data before WALEnd has been received and handled
+				if k.WALEnd > lsn {
+					lsn = k.WALEnd
+				}
+				if k.ReplyRequested {
+					deadline = time.Time{}
+				}
+			case sql_util.XLogDataByteID:
+				x, err := sql_util.ParseXLogData(m.Data[1:])
+				if err != nil {
+					return err
+				}
+				c, err := dec.Decode(x.WALData)
+				if err != nil {
+					return errors.Wrapf(err, "decode pgoutput message at %X", x.WALStart)
+				}
+				if c != nil {
+					fn(c)
+				}
+				if end := x.WALStart + uint64(len(x.WALData)); end > lsn {
+					lsn = end
+				}
+			}
+		case *pgproto3.ErrorResponse:
+			return pgconn.ErrorResponseToPgError(m)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package wasm
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+
+	confpostgres "github.com/machinefi/w3bstream/pkg/depends/conf/postgres"
+	"github.com/machinefi/w3bstream/pkg/depends/kit/sqlx"
+	driverpostgres "github.com/machinefi/w3bstream/pkg/depends/kit/sqlx/driver/postgres"
+	"github.com/machinefi/w3bstream/pkg/enums"
+)
+
+func TestSchemaChecksum(t *testing.T) {
+	col := func(name string) *Column {
+		return &Column{Name: name, Constrains: Constrains{Datatype: enums.WASM_DB_DATATYPE__INT64}}
+	}
+	a := &Table{
+		Name: "a",
+		Cols: []*Column{col("id"), col("v")},
+		Keys: []*Key{{Name: "primary", IsUnique: true, ColumnNames: []string{"id"}}, {Name: "v", ColumnNames: []string{"v"}}},
+	}
+	b := &Table{Name: "b", Cols: []*Column{col("id")}}
+
+	s1 := &Schema{Name: "public", Tables: []*Table{a, b}}
+	s2 := &Schema{Name: "public", Tables: []*Table{
+		b,
+		{Name: "a", Cols: []*Column{col("v"), col("id")}, Keys: []*Key{a.Keys[1], a.Keys[0]}},
+	}}
+
+	sum1, err := schemaChecksum(s1)
+	NewWithT(t).Expect(err).To(BeNil())
+	sum2, err := schemaChecksum(s2)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(sum1).To(Equal(sum2))
+	// definition order is not changed
+	NewWithT(t).Expect(s2.Tables[0].Name).To(Equal("b"))
+
+	s3 := &Schema{Name: "public", Tables: []*Table{a, {Name: "b", Cols: []*Column{col("id"), col("x")}}}}
+	sum3, err := schemaChecksum(s3)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(sum3).NotTo(Equal(sum1))
+}
+
+func TestDatabase_Definition(t *testing.T) {
+	d := &Database{
+		AllowRuntimeMigration: true,
+		Schemas: []*Schema{
+			{Tables: []*Table{{Name: "a"}}},
+			{Name: "public", Tables: []*Table{{Name: "b"}}},
+			{Name: "ext", Tables: []*Table{{Name: "c"}}},
+		},
+	}
+	d.setSchema(&Schema{Name: "public", Tables: []*Table{{Name: "a"}, {Name: "b"}, {Name: "d"}}})
+
+	raw, err := d.Definition()
+	NewWithT(t).Expect(err).To(BeNil())
+
+	v := &Database{}
+	NewWithT(t).Expect(json.Unmarshal(raw, v)).To(BeNil())
+	NewWithT(t).Expect(v.AllowRuntimeMigration).To(BeTrue())
+	NewWithT(t).Expect(v.Schemas).To(HaveLen(2))
+	NewWithT(t).Expect(v.Schemas[0].Name).To(Equal("ext"))
+	NewWithT(t).Expect(v.Schemas[1].Name).To(Equal("public"))
+	NewWithT(t).Expect(v.Schemas[1].Tables).To(HaveLen(3))
+}
+
+// migrationRecorder records the statements executed, and fails the INSERTs by
+// errs in order
+type migrationRecorder struct {
+	sqlx.DBExecutor
+	inserts int
+	errs    []error
+}
+
+func (r *migrationRecorder) ExecContext(_ context.Context, query string, _ ...interface{}) (sql.Result, error) {
+	if !strings.HasPrefix(query, "INSERT") {
+		return nil, nil
+	}
+	r.inserts++
+	if len(r.errs) > 0 {
+		err := r.errs[0]
+		r.errs = r.errs[1:]
+		return nil, err
+	}
+	return nil, nil
+}
+
+func TestRecordMigration(t *testing.T) {
+	s := &Schema{Name: "public", Tables: []*Table{{Name: "a"}}}
+	conflict := &pq.Error{Code: "23505"}
+
+	t.Run("RetryUniqueViolation", func(t *testing.T) {
+		r := &migrationRecorder{errs: []error{conflict, conflict}}
+		NewWithT(t).Expect(recordMigration(context.Background(), r, s)).To(BeNil())
+		NewWithT(t).Expect(r.inserts).To(Equal(3))
+	})
+
+	t.Run("AttemptsExhausted", func(t *testing.T) {
+		r := &migrationRecorder{}
+		for i := 0; i < maxRecordMigrationAttempts; i++ {
+			r.errs = append(r.errs, conflict)
+		}
+		NewWithT(t).Expect(recordMigration(context.Background(), r, s)).NotTo(BeNil())
+		NewWithT(t).Expect(r.inserts).To(Equal(maxRecordMigrationAttempts))
+	})
+
+	t.Run("OtherError", func(t *testing.T) {
+		r := &migrationRecorder{errs: []error{errors.New("any")}}
+		NewWithT(t).Expect(recordMigration(context.Background(), r, s)).NotTo(BeNil())
+		NewWithT(t).Expect(r.inserts).To(Equal(1))
+	})
+}
+
+// mockConnector connects to sqlmock with postgres dialect
+type mockConnector struct {
+	*driverpostgres.Connector
+	dsn string
+	drv driver.Driver
+}
+
+func (c *mockConnector) Connect(context.Context) (driver.Conn, error) { return c.drv.Open(c.dsn) }
+
+func (c *mockConnector) Driver() driver.Driver { return c.drv }
+
+func (c *mockConnector) WithDBName(string) driver.Connector { return c }
+
+func TestDatabase_BackfillSchema(t *testing.T) {
+	dsn := "backfill_schema"
+	conn, mock, err := sqlmock.NewWithDSN(dsn)
+	NewWithT(t).Expect(err).To(BeNil())
+	defer conn.Close()
+
+	col := func(name string) *Column {
+		return &Column{Name: name, Constrains: Constrains{Datatype: enums.WASM_DB_DATATYPE__INT64}}
+	}
+	s := &Schema{Name: "public", Tables: []*Table{
+		{Name: "a", Cols: []*Column{col("id")}},
+		{Name: "b", Cols: []*Column{col("id")}},
+	}}
+	d := &Database{
+		Name:    "demo",
+		schemas: map[string]*Schema{"public": s},
+		ep: &confpostgres.Endpoint{
+			DB: sqlx.NewDatabase("demo").OpenDB(&mockConnector{
+				Connector: &driverpostgres.Connector{},
+				dsn:       dsn,
+				drv:       conn.Driver(),
+			}),
+		},
+	}
+	existed := func(names ...string) *sqlmock.Rows {
+		rows := sqlmock.NewRows([]string{"table_name"})
+		for _, name := range names {
+			rows.AddRow(name)
+		}
+		return rows
+	}
+
+	t.Run("CreateMissingTables", func(t *testing.T) {
+		mock.ExpectQuery("SELECT table_name FROM information_schema.tables").
+			WithArgs("public").WillReturnRows(existed("a"))
+		mock.ExpectBegin()
+		mock.ExpectExec("CREATE SCHEMA IF NOT EXISTS public").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`CREATE TABLE IF NOT EXISTS public\.b`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS public.schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO public.schema_migrations").WillReturnResult(sqlmock.NewResult(0, 1))
+
+		NewWithT(t).Expect(d.BackfillSchema(context.Background(), "")).To(BeNil())
+		NewWithT(t).Expect(mock.ExpectationsWereMet()).To(BeNil())
+	})
+
+	t.Run("NoMissingTables", func(t *testing.T) {
+		mock.ExpectQuery("SELECT table_name FROM information_schema.tables").
+			WithArgs("public").WillReturnRows(existed("a", "b"))
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS public.schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO public.schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+
+		NewWithT(t).Expect(d.BackfillSchema(context.Background(), "public")).To(BeNil())
+		NewWithT(t).Expect(mock.ExpectationsWereMet()).To(BeNil())
+	})
+
+	t.Run("CreateFailed", func(t *testing.T) {
+		mock.ExpectQuery("SELECT table_name FROM information_schema.tables").
+			WithArgs("public").WillReturnRows(existed())
+		mock.ExpectBegin()
+		mock.ExpectExec("CREATE SCHEMA IF NOT EXISTS public").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`CREATE TABLE IF NOT EXISTS public\.a`).WillReturnError(errors.New("any"))
+		mock.ExpectRollback()
+
+		NewWithT(t).Expect(d.BackfillSchema(context.Background(), "public")).NotTo(BeNil())
+		NewWithT(t).Expect(mock.ExpectationsWereMet()).To(BeNil())
+	})
+
+	t.Run("UndefinedSchema", func(t *testing.T) {
+		NewWithT(t).Expect(d.BackfillSchema(context.Background(), "other")).NotTo(BeNil())
+	})
+}
@@ -0,0 +1,60 @@
+package wasm
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/machinefi/w3bstream/pkg/enums"
+)
+
+func TestDatabase_UniqueColumn(t *testing.T) {
+	col := func(name string, datatype enums.WasmDBDatatype) *Column {
+		return &Column{Name: name, Constrains: Constrains{Datatype: datatype}}
+	}
+	d := &Database{schemas: map[string]*Schema{"public": {
+		Name: "public",
+		Tables: []*Table{{
+			Name: "t_event",
+			Cols: []*Column{
+				col("f_id", enums.WASM_DB_DATATYPE__INT64),
+				col("f_name", enums.WASM_DB_DATATYPE__TEXT),
+				col("f_device", enums.WASM_DB_DATATYPE__TEXT),
+				col("f_ts", enums.WASM_DB_DATATYPE__TIMESTAMPTZ),
+				col("f_lower", enums.WASM_DB_DATATYPE__TEXT),
+			},
+			Keys: []*Key{
+				{Name: "primary", IsUnique: true, ColumnNames: []string{"f_id"}},
+				{Name: "name", IsUnique: true, ColumnNames: []string{"f_name"}},
+				{Name: "device_ts", IsUnique: true, ColumnNames: []string{"f_device", "f_ts"}},
+				{Name: "ts", ColumnNames: []string{"f_ts"}},
+				{Name: "lower", IsUnique: true, ColumnNames: []string{"f_lower"}, Expr: "lower(f_lower)"},
+			},
+		}},
+	}}}
+
+	cases := []struct {
+		name     string
+		schema   string
+		table    string
+		col      string
+		datatype enums.WasmDBDatatype
+		ok       bool
+	}{
+		{"PrimaryKey", "", "t_event", "f_id", enums.WASM_DB_DATATYPE__INT64, true},
+		{"UniqueKey", "public", "t_event", "f_name", enums.WASM_DB_DATATYPE__TEXT, true},
+		{"PartOfCompositeKey", "public", "t_event", "f_device", 0, false},
+		{"NonUniqueKey", "public", "t_event", "f_ts", 0, false},
+		{"ExprKey", "public", "t_event", "f_lower", 0, false},
+		{"UnknownColumn", "public", "t_event", "f_any", 0, false},
+		{"UnknownTable", "public", "t_any", "f_id", 0, false},
+		{"UnknownSchema", "other", "t_event", "f_id", 0, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			datatype, ok := d.UniqueColumn(c.schema, c.table, c.col)
+			NewWithT(t).Expect(ok).To(Equal(c.ok))
+			NewWithT(t).Expect(datatype).To(Equal(c.datatype))
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package wasm
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestPartitionRanges(t *testing.T) {
+	date := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	cases := []struct {
+		interval string
+		now      time.Time
+		starts   []time.Time
+	}{
+		{"1 day", date(2023, 3, 15).Add(13 * time.Hour), []time.Time{date(2023, 3, 14), date(2023, 3, 15), date(2023, 3, 16), date(2023, 3, 17)}},
+		{"7 days", date(2023, 3, 15), []time.Time{date(2023, 3, 2), date(2023, 3, 9), date(2023, 3, 16), date(2023, 3, 23)}},
+		{"1 week", date(2023, 3, 15), []time.Time{date(2023, 3, 6), date(2023, 3, 13), date(2023, 3, 20), date(2023, 3, 27)}},
+		{"1 month", date(2023, 1, 31), []time.Time{date(2022, 12, 1), date(2023, 1, 1), date(2023, 2, 1), date(2023, 3, 1)}},
+		{"3 months", date(2023, 5, 20), []time.Time{date(2023, 1, 1), date(2023, 4, 1), date(2023, 7, 1), date(2023, 10, 1)}},
+		{"2 years", date(2023, 5, 20), []time.Time{date(2020, 1, 1), date(2022, 1, 1), date(2024, 1, 1), date(2026, 1, 1)}},
+		{"1 day", date(1969, 12, 31), []time.Time{date(1969, 12, 30), date(1969, 12, 31), date(1970, 1, 1), date(1970, 1, 2)}},
+	}
+
+	for _, c := range cases {
+		ranges, err := partitionRanges("t_metrics", c.interval, c.now)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(ranges).To(HaveLen(3))
+		for i, r := range ranges {
+			NewWithT(t).Expect(r.From).To(Equal(c.starts[i]), c.interval)
+			NewWithT(t).Expect(r.To).To(Equal(c.starts[i+1]), c.interval)
+			NewWithT(t).Expect(r.Name).To(Equal("t_metrics_p" + c.starts[i].Format("20060102")))
+		}
+		NewWithT(t).Expect(!c.now.Before(ranges[1].From) && c.now.Before(ranges[1].To)).To(BeTrue(), c.interval)
+	}
+
+	// ranges computed at different time of the same interval are identical
+	r1, _ := partitionRanges("t_metrics", "7 days", date(2023, 3, 16))
+	r2, _ := partitionRanges("t_metrics", "7 days", date(2023, 3, 22).Add(23*time.Hour))
+	NewWithT(t).Expect(r1).To(Equal(r2))
+
+	for _, interval := range []string{"", "month", "0 day", "-1 day", "1 hour", "one month"} {
+		_, err := partitionRanges("t_metrics", interval, date(2023, 3, 15))
+		NewWithT(t).Expect(err).NotTo(BeNil(), interval)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"testing"
 
+	. "github.com/onsi/gomega"
+
 	base "github.com/machinefi/w3bstream/pkg/depends/base/types"
 	"github.com/machinefi/w3bstream/pkg/depends/kit/sqlx/builder"
 	"github.com/machinefi/w3bstream/pkg/depends/x/contextx"
@@ -166,3 +168,94 @@ func TestDatabase_Init(t *testing.T) {
 	// migration test: drop column
 	// SHOULD NOT support drop column
 }
+
+func TestColumn_ValidateCheckExpr(t *testing.T) {
+	for expr, valid := range map[string]bool{
+		"":                                 true,
+		"f_value > 0 AND f_value < 1000":   true,
+		"length(f_value) > 3":              true,
+		"f_value::numeric >= 0.5":          true,
+		"f_value IN ('a', 'b c', 'other')": true,
+		"f_value IS NOT NULL":              true,
+		"f_value > f_other":                false,
+		"f_value > 'unterminated":          false,
+	} {
+		c := &wasm.Column{
+			Name:       "f_value",
+			Constrains: wasm.Constrains{CheckExpr: expr},
+		}
+		NewWithT(t).Expect(c.ValidateCheckExpr() == nil).To(Equal(valid), expr)
+	}
+}
+
+func TestTable_Partitioning(t *testing.T) {
+	tbl := &wasm.Table{
+		Name: "t_metrics",
+		Cols: []*wasm.Column{
+			{Name: "f_value", Constrains: wasm.Constrains{Datatype: enums.WASM_DB_DATATYPE__FLOAT64}},
+			{Name: "f_created_at", Constrains: wasm.Constrains{Datatype: enums.WASM_DB_DATATYPE__TIMESTAMP}},
+		},
+	}
+
+	for spec, valid := range map[wasm.PartitionSpec]bool{
+		{Strategy: "RANGE", ColumnName: "f_created_at", Interval: "1 month"}: true,
+		{Strategy: "range", ColumnName: "f_created_at", Interval: "7 days"}:  true,
+		{Strategy: "HASH", ColumnName: "f_value"}:                            true,
+		{Strategy: "RANGE", ColumnName: "f_other"}:                           false,
+		{Strategy: "HASH", ColumnName: "f_created_at", Interval: "1 month"}:  false,
+		{Strategy: "RANGE", ColumnName: "f_value", Interval: "1 month"}:      false,
+		{Strategy: "RANGE", ColumnName: "f_created_at", Interval: "1 hour"}:  false,
+		{Strategy: "INTERVAL", ColumnName: "f_created_at"}:                   false,
+	} {
+		spec := spec
+		tbl.Partitioning = &spec
+		NewWithT(t).Expect(tbl.ValidatePartitioning() == nil).To(Equal(valid), spec)
+	}
+
+	tbl.Partitioning = &wasm.PartitionSpec{Strategy: "range", ColumnName: "f_created_at", Interval: "1 month"}
+	NewWithT(t).Expect(tbl.Build().PartitionBy).To(Equal("RANGE (f_created_at)"))
+
+	// unique keys must include partition column
+	tbl.Keys = []*wasm.Key{{Name: "primary", IsUnique: true, ColumnNames: []string{"f_value"}}}
+	NewWithT(t).Expect(tbl.ValidatePartitioning()).NotTo(BeNil())
+	tbl.Keys[0].ColumnNames = append(tbl.Keys[0].ColumnNames, "f_created_at")
+	NewWithT(t).Expect(tbl.ValidatePartitioning()).To(BeNil())
+	tbl.Keys = append(tbl.Keys, &wasm.Key{Name: "i_value", ColumnNames: []string{"f_value"}})
+	NewWithT(t).Expect(tbl.ValidatePartitioning()).To(BeNil())
+}
+
+func TestTable_SoftDelete(t *testing.T) {
+	tbl := &wasm.Table{
+		Name:       "t_device",
+		Cols:       []*wasm.Column{{Name: "f_id", Constrains: wasm.Constrains{Datatype: enums.WASM_DB_DATATYPE__INT64}}},
+		SoftDelete: true,
+	}
+
+	built := tbl.Build()
+	NewWithT(t).Expect(built.Col(wasm.SoftDeleteColumn)).NotTo(BeNil())
+	NewWithT(t).Expect(built.Col(wasm.SoftDeleteAtColumn)).NotTo(BeNil())
+	NewWithT(t).Expect(built.Key("t_device_i_is_deleted_deleted_at_ms")).NotTo(BeNil())
+
+	tbl.SoftDelete = false
+	NewWithT(t).Expect(tbl.Build().Col(wasm.SoftDeleteColumn)).To(BeNil())
+}
+
+func TestTable_AddFTSColumn(t *testing.T) {
+	tbl := &wasm.Table{
+		Name: "t_log",
+		Cols: []*wasm.Column{
+			{Name: "f_id", Constrains: wasm.Constrains{Datatype: enums.WASM_DB_DATATYPE__INT64}},
+			{Name: "f_message", Constrains: wasm.Constrains{Datatype: enums.WASM_DB_DATATYPE__TEXT}},
+		},
+	}
+
+	NewWithT(t).Expect(tbl.AddFTSColumn("f_message_tsv", "f_message", "english")).To(BeNil())
+	NewWithT(t).Expect(tbl.AddFTSColumn("f_message_tsv", "f_message", "simple")).NotTo(BeNil())
+	NewWithT(t).Expect(tbl.AddFTSColumn("f_id_tsv", "f_id", "english")).NotTo(BeNil())
+	NewWithT(t).Expect(tbl.AddFTSColumn("f_other_tsv", "f_message", "english');")).NotTo(BeNil())
+	NewWithT(t).Expect(tbl.FTS).To(HaveLen(1))
+
+	built := tbl.Build()
+	NewWithT(t).Expect(built.Col("f_message_tsv").ColumnType.DataType).To(Equal("tsvector"))
+	NewWithT(t).Expect(built.Key("t_log_i_f_message_tsv").Method).To(Equal("gin"))
+}
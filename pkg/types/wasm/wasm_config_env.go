@@ -4,13 +4,61 @@ import (
 	"context"
 	"os"
 
+	conflog "github.com/machinefi/w3bstream/pkg/depends/conf/log"
+	"github.com/machinefi/w3bstream/pkg/depends/x/mapx"
 	"github.com/machinefi/w3bstream/pkg/enums"
 	"github.com/machinefi/w3bstream/pkg/types"
 )
 
+// DefaultMinLogLevel the minimum wasm log level if project not assigned
+const DefaultMinLogLevel = conflog.InfoLevel
+
+// minLogLevels project min log levels, keyed by env prefix. levels are shared
+// by all instances of project and can be changed at runtime
+var minLogLevels = mapx.New[string, conflog.Level]()
+
 type Env struct {
 	prefix string
 	Env    [][2]string `json:"env"`
+	// MinLogLevel logs more verbose than this level will be dropped, nil means
+	// DefaultMinLogLevel
+	MinLogLevel *conflog.Level `json:"minLogLevel,omitempty"`
+	// MaxKVValueBytes the maximum bytes of value per kv write, 0 means unlimited
+	MaxKVValueBytes int `json:"maxKVValueBytes,omitempty"`
+	// MaxKVTotalBytes the maximum bytes of keys and values stored by project,
+	// 0 means unlimited
+	MaxKVTotalBytes int64 `json:"maxKVTotalBytes,omitempty"`
+	// DebugMode enables debugging host functions, such as ws_get_sql_db_explain,
+	// which should be used in development environments only
+	DebugMode bool `json:"debugMode,omitempty"`
+	// MaxExecutionTicks the maximum epoch ticks(10ms each) of a handler
+	// invocation, the invocation is interrupted when exhausted. it is a wall
+	// clock budget, the time blocked in host functions(such as ws_wait_for_tx,
+	// ws_grpc_call, ws_api_call and sql queries) is counted. 0 means unlimited
+	MaxExecutionTicks uint64 `json:"maxExecutionTicks,omitempty"`
+	// GrpcAllowedDomains domains(including subdomains) can be called by
+	// ws_grpc_call, empty means no grpc endpoint is allowed
+	GrpcAllowedDomains []string `json:"grpcAllowedDomains,omitempty"`
+	// Capabilities host functions(ws_ prefixed) the project can access, empty
+	// means all allowed. functions not listed are linked as stubs returning
+	// ResultStatusCode_PermissionDenied
+	Capabilities []string `json:"capabilities,omitempty"`
+	// SlackWebhookURL the slack incoming webhook(https://hooks.slack.com/...)
+	// ws_send_slack_message posts to, empty means slack message is not allowed
+	SlackWebhookURL string `json:"slackWebhookURL,omitempty"`
+	// RelayerURLs EIP-2771 relayers ws_send_tx_relay can submit to, empty
+	// means relaying is not allowed
+	RelayerURLs []string `json:"relayerURLs,omitempty"`
+}
+
+// RelayerAllowed checks if relayer url is listed in RelayerURLs
+func (env *Env) RelayerAllowed(url string) bool {
+	for _, v := range env.RelayerURLs {
+		if v == url {
+			return true
+		}
+	}
+	return false
 }
 
 func (env *Env) ConfigType() enums.ConfigType {
@@ -27,9 +75,23 @@ func (env *Env) Get(k string) (v string, exists bool) {
 	return os.LookupEnv(env.Key(k))
 }
 
+// LogLevel returns the current minimum log level of project
+func (env *Env) LogLevel() conflog.Level {
+	if lv, ok := minLogLevels.Load(env.prefix); ok {
+		return lv
+	}
+	return DefaultMinLogLevel
+}
+
 func (env *Env) Init(parent context.Context) (err error) {
 	env.prefix = types.MustProjectFromContext(parent).Name + "__"
 
+	if env.MinLogLevel == nil {
+		minLogLevels.Store(env.prefix, DefaultMinLogLevel)
+	} else {
+		minLogLevels.Store(env.prefix, *env.MinLogLevel)
+	}
+
 	defer func() {
 		if err != nil {
 			_ = env.Uninit(nil)
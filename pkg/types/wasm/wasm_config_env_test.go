@@ -0,0 +1,41 @@
+package wasm
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	conflog "github.com/machinefi/w3bstream/pkg/depends/conf/log"
+	"github.com/machinefi/w3bstream/pkg/models"
+	"github.com/machinefi/w3bstream/pkg/types"
+)
+
+func TestEnv_MinLogLevel(t *testing.T) {
+	ctx := types.WithProject(context.Background(), &models.Project{
+		ProjectName: models.ProjectName{Name: "test_env_min_log_level"},
+	})
+
+	cases := []struct {
+		name   string
+		config string
+		expect conflog.Level
+	}{
+		{"Unassigned", `{}`, DefaultMinLogLevel},
+		{"Panic", `{"minLogLevel":"panic"}`, conflog.PanicLevel},
+		{"Debug", `{"minLogLevel":"debug"}`, conflog.DebugLevel},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			NewWithT(t).Expect(func() conflog.Level {
+				env := &Env{}
+				NewWithT(t).Expect(json.Unmarshal([]byte(c.config), env)).To(Succeed())
+				NewWithT(t).Expect(env.Init(ctx)).To(Succeed())
+				defer env.Uninit(ctx)
+				return env.LogLevel()
+			}()).To(Equal(c.expect))
+		})
+	}
+}
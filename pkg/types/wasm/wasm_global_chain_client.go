@@ -8,15 +8,20 @@ import (
 	"math/big"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/blocto/solana-go-sdk/client"
 	solcommon "github.com/blocto/solana-go-sdk/common"
 	soltypes "github.com/blocto/solana-go-sdk/types"
 	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/pkg/errors"
 
 	base "github.com/machinefi/w3bstream/pkg/depends/base/types"
@@ -110,18 +115,19 @@ func (c *ChainClient) SendTX(conf *types.ChainConfig, chainID uint64, chainName
 }
 
 func (c *ChainClient) sendTX(conf *types.ChainConfig, chainID uint64, chainName enums.ChainName, toStr, valueStr, dataStr string, op *optypes.SyncOperator) (string, error) {
-	chain, ok := conf.GetChain(chainID, chainName)
-	if !ok {
-		return "", errors.Errorf("the chain %d %s is not supported", chainID, chainName)
+	chain, err := getChain(conf, chainID, chainName)
+	if err != nil {
+		return "", err
 	}
+	typ := op.Operator().Type
 	if chain.IsSolana() {
-		if op.Op.Type != enums.OPERATOR_KEY__ED25519 {
+		if typ != enums.OPERATOR_KEY__ED25519 {
 			return "", errors.New("invalid operator key type, require ED25519")
 		}
 		return c.sendSolanaTX(chain, dataStr, op)
 	}
 
-	if op.Op.Type != enums.OPERATOR_KEY__ECDSA {
+	if typ != enums.OPERATOR_KEY__ECDSA {
 		return "", errors.New("invalid operator key type, require ECDSA")
 	}
 	return c.sendEthTX(chain, toStr, valueStr, dataStr, op)
@@ -129,7 +135,7 @@ func (c *ChainClient) sendTX(conf *types.ChainConfig, chainID uint64, chainName
 
 func (c *ChainClient) sendSolanaTX(chain *types.Chain, dataStr string, op *optypes.SyncOperator) (string, error) {
 	cli := client.NewClient(chain.Endpoint)
-	b := common.FromHex(op.Op.PrivateKey)
+	b := common.FromHex(op.Operator().PrivateKey)
 	pk := ed25519.PrivateKey(b)
 	account := soltypes.Account{
 		PublicKey:  solcommon.PublicKeyFromBytes(pk.Public().(ed25519.PublicKey)),
@@ -174,6 +180,10 @@ func (c *ChainClient) sendEthTX(chain *types.Chain, toStr, valueStr, dataStr str
 		return "", errors.New("missing to or value string")
 	}
 
+	if err := checkHealth(chain); err != nil {
+		return "", err
+	}
+
 	op.Mux.Lock()
 	defer op.Mux.Unlock()
 
@@ -248,10 +258,22 @@ func (c *ChainClient) sendEthTX(chain *types.Chain, toStr, valueStr, dataStr str
 	return signedTx.Hash().Hex(), nil
 }
 
+// getChain finds chain by chainID first, then chainName if assigned
+func getChain(conf *types.ChainConfig, chainID uint64, chainName enums.ChainName) (*types.Chain, error) {
+	chain, err := conf.GetByID(chainID)
+	if err != nil && chainName != "" {
+		return conf.GetByName(chainName)
+	}
+	return chain, err
+}
+
 func (c *ChainClient) getEthClient(conf *types.ChainConfig, chainID uint64, chainName enums.ChainName) (*ethclient.Client, error) {
-	chain, ok := conf.GetChain(chainID, chainName)
-	if !ok {
-		return nil, errors.Errorf("the chain %d %s is not supported", chainID, chainName)
+	chain, err := getChain(conf, chainID, chainName)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkHealth(chain); err != nil {
+		return nil, err
 	}
 
 	return ethclient.Dial(chain.Endpoint)
@@ -279,3 +301,588 @@ func (c *ChainClient) CallContract(conf *types.ChainConfig, chainID uint64, chai
 
 	return cli.CallContract(context.Background(), msg, nil)
 }
+
+// WaitForTx polls the receipt of txHash every interval until the transaction is
+// mined or ctx is done
+func (c *ChainClient) WaitForTx(ctx context.Context, conf *types.ChainConfig, chainID uint64, txHash string, interval time.Duration) (*ethtypes.Receipt, error) {
+	cli, err := c.getEthClient(conf, chainID, "")
+	if err != nil {
+		return nil, err
+	}
+	defer cli.Close()
+
+	hash := common.HexToHash(txHash)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		receipt, err := cli.TransactionReceipt(ctx, hash)
+		if err == nil {
+			return receipt, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if !errors.Is(err, ethereum.NotFound) {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// EstimateGas estimates the gas used by msg on the chain of chainID
+func (c *ChainClient) EstimateGas(conf *types.ChainConfig, chainID uint64, msg ethereum.CallMsg) (uint64, error) {
+	cli, err := c.getEthClient(conf, chainID, "")
+	if err != nil {
+		return 0, err
+	}
+	defer cli.Close()
+
+	return cli.EstimateGas(context.Background(), msg)
+}
+
+// BalanceOf returns the latest balance in wei of operator on chain
+func (c *ChainClient) BalanceOf(conf *types.ChainConfig, chainID uint64, sop *optypes.SyncOperator) (*big.Int, error) {
+	op := sop.Operator()
+	if op.Type != enums.OPERATOR_KEY__ECDSA {
+		return nil, errors.New("invalid operator key type, require ECDSA")
+	}
+	cli, err := c.getEthClient(conf, chainID, "")
+	if err != nil {
+		return nil, err
+	}
+	defer cli.Close()
+
+	pk := crypto.ToECDSAUnsafe(common.FromHex(op.PrivateKey))
+	return cli.BalanceAt(context.Background(), crypto.PubkeyToAddress(pk.PublicKey), nil)
+}
+
+// PendingTxCount returns the count of unconfirmed transactions of operator, it
+// is the pending nonce minus the nonce of latest block
+func (c *ChainClient) PendingTxCount(conf *types.ChainConfig, chainID uint64, op *optypes.SyncOperator) (uint64, error) {
+	o := op.Operator()
+	if o.Type != enums.OPERATOR_KEY__ECDSA {
+		return 0, errors.New("invalid operator key type, require ECDSA")
+	}
+	cli, err := c.getEthClient(conf, chainID, "")
+	if err != nil {
+		return 0, err
+	}
+	defer cli.Close()
+
+	pk := crypto.ToECDSAUnsafe(common.FromHex(o.PrivateKey))
+	return pendingTxCount(context.Background(), cli, crypto.PubkeyToAddress(pk.PublicKey))
+}
+
+type nonceReader interface {
+	PendingNonceAt(context.Context, common.Address) (uint64, error)
+	NonceAt(context.Context, common.Address, *big.Int) (uint64, error)
+}
+
+func pendingTxCount(ctx context.Context, cli nonceReader, addr common.Address) (uint64, error) {
+	pending, err := cli.PendingNonceAt(ctx, addr)
+	if err != nil {
+		return 0, err
+	}
+	confirmed, err := cli.NonceAt(ctx, addr, nil)
+	if err != nil {
+		return 0, err
+	}
+	if pending < confirmed {
+		return 0, nil // pending state of node lags behind latest block
+	}
+	return pending - confirmed, nil
+}
+
+// WaitPendingTxsDrained waits until operator has no unconfirmed transactions
+// on each eth chain of conf, the pending count is polled every interval. it
+// returns an error if ctx is done before drained. only ECDSA operators send
+// eth transactions, so the others are drained already
+func WaitPendingTxsDrained(ctx context.Context, conf *types.ChainConfig, op *models.Operator, interval time.Duration) error {
+	if op.Type != enums.OPERATOR_KEY__ECDSA {
+		return nil
+	}
+	pk := crypto.ToECDSAUnsafe(common.FromHex(op.PrivateKey))
+	addr := crypto.PubkeyToAddress(pk.PublicKey)
+
+	for _, chain := range conf.ChainIDs {
+		if !chain.IsEth() {
+			continue
+		}
+		cli, err := ethclient.DialContext(ctx, chain.Endpoint)
+		if err != nil {
+			return err
+		}
+		err = waitPendingTxsDrained(ctx, cli, addr, interval)
+		cli.Close()
+		if err != nil {
+			return errors.Wrapf(err, "chain %d", chain.ChainID)
+		}
+	}
+	return nil
+}
+
+func waitPendingTxsDrained(ctx context.Context, cli nonceReader, addr common.Address, interval time.Duration) error {
+	for {
+		count, err := pendingTxCount(ctx, cli, addr)
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Wrapf(ctx.Err(), "%d transactions pending", count)
+		case <-time.After(interval):
+		}
+	}
+}
+
+// NewCallMsg builds call message from tx params. value is a decimal string and
+// data is hex encoded, sender is the address of the operator in pool when
+// fromStr is empty
+func NewCallMsg(fromStr, toStr, valueStr, dataStr, operatorName string, opPool optypes.Pool, prj *models.Project) (ethereum.CallMsg, error) {
+	msg := ethereum.CallMsg{}
+
+	if fromStr != "" {
+		msg.From = common.HexToAddress(fromStr)
+	} else {
+		if operatorName == "" {
+			operatorName = operator.DefaultOperatorName
+		}
+		sop, err := opPool.Get(prj.AccountID, operatorName)
+		if err != nil {
+			return msg, err
+		}
+		op := sop.Operator()
+		if op.Type != enums.OPERATOR_KEY__ECDSA {
+			return msg, errors.New("invalid operator key type, require ECDSA")
+		}
+		pk := crypto.ToECDSAUnsafe(common.FromHex(op.PrivateKey))
+		msg.From = crypto.PubkeyToAddress(pk.PublicKey)
+	}
+
+	if toStr != "" {
+		to := common.HexToAddress(toStr)
+		msg.To = &to
+	}
+
+	if valueStr != "" {
+		value, ok := new(big.Int).SetString(valueStr, 10)
+		if !ok {
+			return msg, errors.New("fail to read tx value")
+		}
+		msg.Value = value
+	}
+
+	data, err := hex.DecodeString(strings.TrimPrefix(dataStr, "0x"))
+	if err != nil {
+		return msg, err
+	}
+	msg.Data = data
+	return msg, nil
+}
+
+// multicall3ABI is the aggregate3 subset of the Multicall3 contract abi
+const multicall3ABI = `[{"inputs":[{"components":[{"internalType":"address","name":"target","type":"address"},{"internalType":"bool","name":"allowFailure","type":"bool"},{"internalType":"bytes","name":"callData","type":"bytes"}],"internalType":"struct Multicall3.Call3[]","name":"calls","type":"tuple[]"}],"name":"aggregate3","outputs":[{"components":[{"internalType":"bool","name":"success","type":"bool"},{"internalType":"bytes","name":"returnData","type":"bytes"}],"internalType":"struct Multicall3.Result[]","name":"returnData","type":"tuple[]"}],"stateMutability":"payable","type":"function"}]`
+
+type ContractCall struct {
+	To   string `json:"to"`
+	Data string `json:"data"`
+}
+
+type ContractCallResult struct {
+	Success    bool   `json:"success"`
+	ReturnData string `json:"returnData"`
+}
+
+const (
+	// MaxContractEventsBlockRange the max blocks scanned by a contract events
+	// query
+	MaxContractEventsBlockRange = 2000
+	// MaxContractEventsPerQuery the max events returned by a contract events
+	// query
+	MaxContractEventsPerQuery = 1000
+)
+
+// ContractEventsQuery queries contract events emitted after LastBlock
+type ContractEventsQuery struct {
+	Address string `json:"address"`
+	// Topics topic filters by position, each position matches any of the topics
+	Topics    [][]string `json:"topics,omitempty"`
+	LastBlock uint64     `json:"lastBlock"`
+}
+
+type ContractEventsResult struct {
+	Events []ethtypes.Log `json:"events"`
+	// LastBlock the highest block scanned, it should be persisted by caller as
+	// the cursor of next query
+	LastBlock uint64 `json:"lastBlock"`
+}
+
+// GetContractEventsSince filters the contract events from q.LastBlock+1 to the
+// latest block. at most MaxContractEventsBlockRange blocks are scanned and at
+// most MaxContractEventsPerQuery events are returned, the caller should query
+// again from the LastBlock returned until it reaches the latest block
+func (c *ChainClient) GetContractEventsSince(conf *types.ChainConfig, chainID uint64, q *ContractEventsQuery) (*ContractEventsResult, error) {
+	cli, err := c.getEthClient(conf, chainID, "")
+	if err != nil {
+		return nil, err
+	}
+	defer cli.Close()
+
+	head, err := cli.BlockNumber(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	ret := &ContractEventsResult{Events: []ethtypes.Log{}, LastBlock: q.LastBlock}
+	if head <= q.LastBlock {
+		return ret, nil
+	}
+	to := head
+	if to-q.LastBlock > MaxContractEventsBlockRange {
+		to = q.LastBlock + MaxContractEventsBlockRange
+	}
+
+	topics := make([][]common.Hash, 0, len(q.Topics))
+	for _, ts := range q.Topics {
+		hashes := make([]common.Hash, 0, len(ts))
+		for _, t := range ts {
+			hashes = append(hashes, common.HexToHash(t))
+		}
+		topics = append(topics, hashes)
+	}
+
+	metrics.BlockChainTxMtc.WithLabelValues(c.ProjectName, strconv.Itoa(int(chainID))).Inc()
+
+	logs, err := cli.FilterLogs(context.Background(), ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(q.LastBlock + 1),
+		ToBlock:   new(big.Int).SetUint64(to),
+		Addresses: []common.Address{common.HexToAddress(q.Address)},
+		Topics:    topics,
+	})
+	if err != nil {
+		return nil, err
+	}
+	logs, to, err = truncateContractEvents(logs, q.LastBlock, to, MaxContractEventsPerQuery)
+	if err != nil {
+		return nil, err
+	}
+	ret.Events = append(ret.Events, logs...)
+	ret.LastBlock = to
+	return ret, nil
+}
+
+// truncateContractEvents keeps the events of whole blocks when events of block
+// range (from, to] exceed max, and returns the events kept and the last block
+// covered by them. the events of a block are never split, so it fails if the
+// events of the first block exceed max
+func truncateContractEvents(logs []ethtypes.Log, from, to uint64, max int) ([]ethtypes.Log, uint64, error) {
+	if len(logs) <= max {
+		return logs, to, nil
+	}
+	last := logs[max].BlockNumber - 1
+	if last <= from {
+		return nil, 0, errors.Errorf("events of block %d exceed %d", logs[max].BlockNumber, max)
+	}
+	n := max
+	for n > 0 && logs[n-1].BlockNumber > last {
+		n--
+	}
+	return logs[:n], last, nil
+}
+
+// BlockInfo block metadata for anchoring proofs on-chain
+type BlockInfo struct {
+	Hash       string `json:"hash"`
+	ParentHash string `json:"parentHash"`
+	Timestamp  uint64 `json:"timestamp"`
+	Number     uint64 `json:"number"`
+	GasUsed    uint64 `json:"gasUsed"`
+}
+
+// GetBlock returns the block metadata of blockNumber, negative blockNumber
+// means the latest block
+func (c *ChainClient) GetBlock(conf *types.ChainConfig, chainID uint64, blockNumber int64) (*BlockInfo, error) {
+	cli, err := c.getEthClient(conf, chainID, "")
+	if err != nil {
+		return nil, err
+	}
+	defer cli.Close()
+
+	var number *big.Int
+	if blockNumber >= 0 {
+		number = big.NewInt(blockNumber)
+	}
+	block, err := cli.BlockByNumber(context.Background(), number)
+	if err != nil {
+		return nil, err
+	}
+	return &BlockInfo{
+		Hash:       block.Hash().Hex(),
+		ParentHash: block.ParentHash().Hex(),
+		Timestamp:  block.Time(),
+		Number:     block.NumberU64(),
+		GasUsed:    block.GasUsed(),
+	}, nil
+}
+
+// StorageProof EIP-1186 merkle proof of storage slot
+type StorageProof struct {
+	Key   string       `json:"key"`
+	Value *hexutil.Big `json:"value"`
+	Proof []string     `json:"proof"`
+}
+
+// AccountProof EIP-1186 merkle proof of account and its storage slots, the
+// quantities are hex encoded as returned by eth_getProof
+type AccountProof struct {
+	Address      common.Address  `json:"address"`
+	AccountProof []string        `json:"accountProof"`
+	Balance      *hexutil.Big    `json:"balance"`
+	CodeHash     common.Hash     `json:"codeHash"`
+	Nonce        hexutil.Uint64  `json:"nonce"`
+	StorageHash  common.Hash     `json:"storageHash"`
+	StorageProof []*StorageProof `json:"storageProof"`
+}
+
+// GetProof returns the merkle proof of address and storageKeys by eth_getProof
+// at blockNumber, negative blockNumber means the latest block
+func (c *ChainClient) GetProof(conf *types.ChainConfig, chainID uint64, address string, storageKeys []string, blockNumber int64) (*AccountProof, error) {
+	if !common.IsHexAddress(address) {
+		return nil, errors.Errorf("invalid address: %s", address)
+	}
+	chain, err := getChain(conf, chainID, "")
+	if err != nil {
+		return nil, err
+	}
+	if err = checkHealth(chain); err != nil {
+		return nil, err
+	}
+	cli, err := rpc.Dial(chain.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	defer cli.Close()
+
+	block := "latest"
+	if blockNumber >= 0 {
+		block = hexutil.EncodeUint64(uint64(blockNumber))
+	}
+	if storageKeys == nil {
+		storageKeys = []string{}
+	}
+	ret := &AccountProof{}
+	err = cli.CallContext(context.Background(), ret, "eth_getProof", common.HexToAddress(address), storageKeys, block)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// GasPrice suggested gas price of chain in wei, decimal strings
+type GasPrice struct {
+	GasPrice             string `json:"gasPrice"`
+	MaxPriorityFeePerGas string `json:"maxPriorityFeePerGas"`
+}
+
+// gasPriceCacheTTL the duration of suggested gas price cached for each chain
+var gasPriceCacheTTL = 3 * time.Second
+
+type gasPriceEntry struct {
+	price     *GasPrice
+	expiredAt time.Time
+}
+
+// gasPrices the cached gas prices; key: chain id, value: *gasPriceEntry
+var gasPrices sync.Map
+
+// GetGasPrice returns the suggested legacy gas price and EIP-1559 priority fee
+// of chain, the result is cached for gasPriceCacheTTL
+func (c *ChainClient) GetGasPrice(conf *types.ChainConfig, chainID uint64) (*GasPrice, error) {
+	if v, ok := gasPrices.Load(chainID); ok {
+		if e := v.(*gasPriceEntry); time.Now().Before(e.expiredAt) {
+			return e.price, nil
+		}
+	}
+
+	cli, err := c.getEthClient(conf, chainID, "")
+	if err != nil {
+		return nil, err
+	}
+	defer cli.Close()
+
+	ret, err := suggestGasPrice(context.Background(), cli)
+	if err != nil {
+		return nil, err
+	}
+	gasPrices.Store(chainID, &gasPriceEntry{
+		price:     ret,
+		expiredAt: time.Now().Add(gasPriceCacheTTL),
+	})
+	return ret, nil
+}
+
+type gasSuggester interface {
+	SuggestGasPrice(context.Context) (*big.Int, error)
+	SuggestGasTipCap(context.Context) (*big.Int, error)
+}
+
+// suggestGasPrice suggests gas price and priority fee. chains without EIP-1559
+// support reject eth_maxPriorityFeePerGas, the legacy gas price is used as
+// priority fee for them
+func suggestGasPrice(ctx context.Context, cli gasSuggester) (*GasPrice, error) {
+	price, err := cli.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tip, err := cli.SuggestGasTipCap(ctx)
+	if err != nil {
+		tip = price
+	}
+	return &GasPrice{
+		GasPrice:             price.String(),
+		MaxPriorityFeePerGas: tip.String(),
+	}, nil
+}
+
+// CallContractMulticall batches read-only calls through Multicall3 when the
+// chain has Multicall3Address configured, otherwise calls them one by one
+func (c *ChainClient) CallContractMulticall(conf *types.ChainConfig, chainID uint64, chainName enums.ChainName, calls []ContractCall) ([]ContractCallResult, error) {
+	chain, err := getChain(conf, chainID, chainName)
+	if err != nil {
+		return nil, err
+	}
+	if chain.Multicall3Address == "" {
+		results := make([]ContractCallResult, 0, len(calls))
+		for _, call := range calls {
+			data, err := c.CallContract(conf, chainID, chainName, call.To, call.Data)
+			if err != nil {
+				results = append(results, ContractCallResult{Success: false, ReturnData: "0x"})
+				continue
+			}
+			results = append(results, ContractCallResult{Success: true, ReturnData: hexutil.Encode(data)})
+		}
+		return results, nil
+	}
+
+	input, err := packAggregate3(calls)
+	if err != nil {
+		return nil, err
+	}
+	output, err := c.CallContract(conf, chainID, chainName, chain.Multicall3Address, hexutil.Encode(input))
+	if err != nil {
+		return nil, err
+	}
+	return unpackAggregate3(output)
+}
+
+// packAggregate3 encodes calls as the input of Multicall3 aggregate3, every
+// call is allowed to fail
+func packAggregate3(calls []ContractCall) ([]byte, error) {
+	parsed, err := abi.JSON(strings.NewReader(multicall3ABI))
+	if err != nil {
+		return nil, err
+	}
+
+	type call3 struct {
+		Target       common.Address
+		AllowFailure bool
+		CallData     []byte
+	}
+	args := make([]call3, 0, len(calls))
+	for _, call := range calls {
+		data, err := hex.DecodeString(strings.TrimPrefix(call.Data, "0x"))
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, call3{
+			Target:       common.HexToAddress(call.To),
+			AllowFailure: true,
+			CallData:     data,
+		})
+	}
+	return parsed.Pack("aggregate3", args)
+}
+
+// unpackAggregate3 decodes the output of Multicall3 aggregate3, malformed or
+// truncated output results in an error
+func unpackAggregate3(output []byte) ([]ContractCallResult, error) {
+	parsed, err := abi.JSON(strings.NewReader(multicall3ABI))
+	if err != nil {
+		return nil, err
+	}
+
+	unpacked, err := parsed.Unpack("aggregate3", output)
+	if err != nil {
+		return nil, err
+	}
+	if len(unpacked) != 1 {
+		return nil, errors.New("unexpected aggregate3 output")
+	}
+	ret, ok := unpacked[0].([]struct {
+		Success    bool   `json:"success"`
+		ReturnData []byte `json:"returnData"`
+	})
+	if !ok {
+		return nil, errors.New("unexpected aggregate3 output")
+	}
+
+	results := make([]ContractCallResult, 0, len(ret))
+	for _, r := range ret {
+		results = append(results, ContractCallResult{Success: r.Success, ReturnData: hexutil.Encode(r.ReturnData)})
+	}
+	return results, nil
+}
+
+const tokenURIABI = `[{"inputs":[{"internalType":"uint256","name":"tokenId","type":"uint256"}],"name":"tokenURI","outputs":[{"internalType":"string","name":"","type":"string"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"uint256","name":"id","type":"uint256"}],"name":"uri","outputs":[{"internalType":"string","name":"","type":"string"}],"stateMutability":"view","type":"function"}]`
+
+const (
+	TokenStandardERC721  = "ERC721"
+	TokenStandardERC1155 = "ERC1155"
+)
+
+// TokenURI calls `tokenURI(uint256)` of ERC721 or `uri(uint256)` of ERC1155
+// contract and returns the metadata uri of token. the `{id}` placeholder of
+// ERC1155 uri is returned as is
+func (c *ChainClient) TokenURI(conf *types.ChainConfig, chainID uint64, contract string, tokenID *big.Int, standard string) (string, error) {
+	method := ""
+	switch strings.ToUpper(standard) {
+	case TokenStandardERC721:
+		method = "tokenURI"
+	case TokenStandardERC1155:
+		method = "uri"
+	default:
+		return "", errors.Errorf("unsupported token standard: %s", standard)
+	}
+
+	parsed, err := abi.JSON(strings.NewReader(tokenURIABI))
+	if err != nil {
+		return "", err
+	}
+	input, err := parsed.Pack(method, tokenID)
+	if err != nil {
+		return "", err
+	}
+	output, err := c.CallContract(conf, chainID, "", contract, hexutil.Encode(input))
+	if err != nil {
+		return "", err
+	}
+	unpacked, err := parsed.Unpack(method, output)
+	if err != nil {
+		return "", err
+	}
+	if len(unpacked) != 1 {
+		return "", errors.Errorf("unexpected %s output", method)
+	}
+	uri, ok := unpacked[0].(string)
+	if !ok {
+		return "", errors.Errorf("unexpected %s output", method)
+	}
+	return uri, nil
+}
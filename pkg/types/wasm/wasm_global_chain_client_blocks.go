@@ -0,0 +1,89 @@
+package wasm
+
+import (
+	"context"
+	"time"
+
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+
+	"github.com/machinefi/w3bstream/pkg/types"
+)
+
+// newHeadPollInterval the interval of polling the latest header when the
+// endpoint doesn't support subscription, eg: http endpoint
+var newHeadPollInterval = 5 * time.Second
+
+// BlockHeader the new block header dispatched to wasm
+type BlockHeader struct {
+	BlockNumber uint64 `json:"blockNumber"`
+	Hash        string `json:"hash"`
+	Timestamp   uint64 `json:"timestamp"`
+}
+
+func newBlockHeader(h *ethtypes.Header) *BlockHeader {
+	return &BlockHeader{BlockNumber: h.Number.Uint64(), Hash: h.Hash().Hex(), Timestamp: h.Time}
+}
+
+// SubscribeNewHeads calls fn with each new block header of chain until ctx is
+// done. the endpoint is subscribed by eth_subscribe if notifications are
+// supported, otherwise the latest header is polled every newHeadPollInterval
+// and the intermediate blocks between polls are skipped
+func (c *ChainClient) SubscribeNewHeads(ctx context.Context, conf *types.ChainConfig, chainID uint64, fn func(*BlockHeader)) error {
+	cli, err := c.getEthClient(conf, chainID, "")
+	if err != nil {
+		return err
+	}
+
+	heads := make(chan *ethtypes.Header)
+	sub, err := cli.SubscribeNewHead(ctx, heads)
+	if errors.Is(err, rpc.ErrNotificationsUnsupported) {
+		go pollNewHeads(ctx, cli, fn)
+		return nil
+	}
+	if err != nil {
+		cli.Close()
+		return err
+	}
+
+	go func() {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case h := <-heads:
+				fn(newBlockHeader(h))
+			case <-sub.Err(): // connection lost, keep going by polling
+				go pollNewHeads(ctx, cli, fn)
+				return
+			case <-ctx.Done():
+				cli.Close()
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func pollNewHeads(ctx context.Context, cli *ethclient.Client, fn func(*BlockHeader)) {
+	defer cli.Close()
+
+	ticker := time.NewTicker(newHeadPollInterval)
+	defer ticker.Stop()
+
+	last := uint64(0)
+	for {
+		select {
+		case <-ticker.C:
+			h, err := cli.HeaderByNumber(ctx, nil)
+			if err != nil || h.Number.Uint64() <= last {
+				continue
+			}
+			last = h.Number.Uint64()
+			fn(newBlockHeader(h))
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package wasm
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/pkg/errors"
+
+	"github.com/machinefi/w3bstream/pkg/types"
+)
+
+var ErrChainUnhealthy = errors.New("chain endpoint is unhealthy")
+
+var (
+	// healthCheckInterval the interval of checking healthy endpoint
+	healthCheckInterval = 30 * time.Second
+	// reconnectMinBackoff and reconnectMaxBackoff bound the exponential backoff
+	// of reconnecting unhealthy endpoint
+	reconnectMinBackoff = time.Second
+	reconnectMaxBackoff = 30 * time.Second
+	// healthCheckTimeout the timeout of each check
+	healthCheckTimeout = 10 * time.Second
+)
+
+// endpointHealth monitors the health of chain endpoint in background, the
+// endpoint is healthy until the first check failed
+type endpointHealth struct {
+	endpoint string
+	healthy  atomic.Bool
+}
+
+// endpoints health monitors of eth endpoints; key: endpoint url
+var endpoints sync.Map
+
+// healthOf returns the health monitor of endpoint, the monitor is started at
+// the first time and keeps running in background
+func healthOf(endpoint string) *endpointHealth {
+	h := &endpointHealth{endpoint: endpoint}
+	h.healthy.Store(true)
+	if v, loaded := endpoints.LoadOrStore(endpoint, h); loaded {
+		return v.(*endpointHealth)
+	}
+	go h.run()
+	return h
+}
+
+func (h *endpointHealth) IsHealthy() bool { return h.healthy.Load() }
+
+func (h *endpointHealth) run() {
+	backoff := reconnectMinBackoff
+	for {
+		if err := h.check(); err != nil {
+			h.healthy.Store(false)
+			time.Sleep(backoff)
+			if backoff *= 2; backoff > reconnectMaxBackoff {
+				backoff = reconnectMaxBackoff
+			}
+			continue
+		}
+		h.healthy.Store(true)
+		backoff = reconnectMinBackoff
+		time.Sleep(healthCheckInterval)
+	}
+}
+
+// check connects endpoint and queries the latest block number
+func (h *endpointHealth) check() error {
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	defer cancel()
+
+	cli, err := ethclient.DialContext(ctx, h.endpoint)
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+
+	_, err = cli.BlockNumber(ctx)
+	return err
+}
+
+// IsHealthy reports if the endpoint of chain is healthy, the endpoint is
+// monitored since the first query
+func (c *ChainClient) IsHealthy(conf *types.ChainConfig, chainID uint64) bool {
+	chain, err := getChain(conf, chainID, "")
+	if err != nil {
+		return false
+	}
+	return chain.IsSolana() || healthOf(chain.Endpoint).IsHealthy()
+}
+
+func checkHealth(chain *types.Chain) error {
+	if !healthOf(chain.Endpoint).IsHealthy() {
+		return errors.Wrapf(ErrChainUnhealthy, "chain %d", chain.ChainID)
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+package wasm
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/pkg/errors"
+
+	"github.com/machinefi/w3bstream/pkg/enums"
+	"github.com/machinefi/w3bstream/pkg/models"
+	"github.com/machinefi/w3bstream/pkg/modules/metrics"
+	"github.com/machinefi/w3bstream/pkg/modules/operator"
+	optypes "github.com/machinefi/w3bstream/pkg/modules/operator/pool/types"
+	"github.com/machinefi/w3bstream/pkg/types"
+)
+
+// default EIP-712 domain of openzeppelin MinimalForwarder
+const (
+	defaultForwarderName    = "MinimalForwarder"
+	defaultForwarderVersion = "0.0.1"
+)
+
+// relayTimeout the timeout of posting forward request to relayer
+var relayTimeout = 30 * time.Second
+
+// RelayTx meta-transaction sent through EIP-2771 relayer
+type RelayTx struct {
+	// RelayerURL should be one of RelayerURLs of project env
+	RelayerURL       string `json:"relayerUrl"`
+	ForwarderAddress string `json:"forwarderAddress"`
+	// ForwarderName and ForwarderVersion EIP-712 domain of forwarder, default
+	// is the domain of openzeppelin MinimalForwarder
+	ForwarderName    string `json:"forwarderName,omitempty"`
+	ForwarderVersion string `json:"forwarderVersion,omitempty"`
+	To               string `json:"to"`
+	Data             string `json:"data"`
+	Value            string `json:"value,omitempty"`
+	// Gas gas limit of forwarded call, it is estimated if not assigned
+	Gas uint64 `json:"gas,omitempty"`
+}
+
+func (tx *RelayTx) SetDefault() {
+	if tx.ForwarderName == "" {
+		tx.ForwarderName = defaultForwarderName
+	}
+	if tx.ForwarderVersion == "" {
+		tx.ForwarderVersion = defaultForwarderVersion
+	}
+	if tx.Value == "" {
+		tx.Value = "0"
+	}
+}
+
+// ForwardRequest EIP-2771 forward request, numbers are decimal strings
+type ForwardRequest struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Value string `json:"value"`
+	Gas   string `json:"gas"`
+	Nonce string `json:"nonce"`
+	Data  string `json:"data"`
+}
+
+// RelayRequest the body posted to relayer
+type RelayRequest struct {
+	Request          *ForwardRequest `json:"request"`
+	Signature        string          `json:"signature"`
+	ForwarderAddress string          `json:"forwarderAddress"`
+}
+
+// RelayResponse the body responded by relayer
+type RelayResponse struct {
+	TxHash string `json:"txHash"`
+}
+
+// TypedData returns the EIP-712 typed data of forward request
+func (r *ForwardRequest) TypedData(chainID uint64, forwarder, name, version string) apitypes.TypedData {
+	return apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"ForwardRequest": {
+				{Name: "from", Type: "address"},
+				{Name: "to", Type: "address"},
+				{Name: "value", Type: "uint256"},
+				{Name: "gas", Type: "uint256"},
+				{Name: "nonce", Type: "uint256"},
+				{Name: "data", Type: "bytes"},
+			},
+		},
+		PrimaryType: "ForwardRequest",
+		Domain: apitypes.TypedDataDomain{
+			Name:              name,
+			Version:           version,
+			ChainId:           math.NewHexOrDecimal256(int64(chainID)),
+			VerifyingContract: forwarder,
+		},
+		Message: apitypes.TypedDataMessage{
+			"from":  r.From,
+			"to":    r.To,
+			"value": r.Value,
+			"gas":   r.Gas,
+			"nonce": r.Nonce,
+			"data":  r.Data,
+		},
+	}
+}
+
+// Sign signs the EIP-712 hash of forward request, the recovery id of signature
+// is 27 or 28
+func (r *ForwardRequest) Sign(chainID uint64, forwarder, name, version string, pk *ecdsa.PrivateKey) (string, error) {
+	hash, _, err := apitypes.TypedDataAndHash(r.TypedData(chainID, forwarder, name, version))
+	if err != nil {
+		return "", err
+	}
+	sig, err := crypto.Sign(hash, pk)
+	if err != nil {
+		return "", err
+	}
+	sig[crypto.RecoveryIDOffset] += 27
+	return hexutil.Encode(sig), nil
+}
+
+// getNonceSelector selector of `getNonce(address)` of forwarder
+var getNonceSelector = crypto.Keccak256([]byte("getNonce(address)"))[:4]
+
+// SendTXRelay signs the forward request by project default operator and
+// submits it to relayer, returns the tx hash responded by relayer
+func (c *ChainClient) SendTXRelay(conf *types.ChainConfig, chainID uint64, tx *RelayTx, opPool optypes.Pool, prj *models.Project) (string, error) {
+	tx.SetDefault()
+	if tx.RelayerURL == "" || tx.ForwarderAddress == "" || tx.To == "" {
+		return "", errors.New("missing relayerUrl, forwarderAddress or to")
+	}
+	value, ok := new(big.Int).SetString(tx.Value, 10)
+	if !ok {
+		return "", errors.New("fail to read tx value")
+	}
+	data, err := hexutil.Decode(tx.Data)
+	if err != nil {
+		return "", errors.Wrap(err, "data")
+	}
+
+	op, err := opPool.Get(prj.AccountID, operator.DefaultOperatorName)
+	if err != nil {
+		return "", err
+	}
+	// the forward request is signed offline and the nonce is managed by
+	// forwarder, so Mux is not held across the calls to chain and relayer
+	opm := op.Operator()
+	if opm.Type != enums.OPERATOR_KEY__ECDSA {
+		return "", errors.New("invalid operator key type, require ECDSA")
+	}
+	pk := crypto.ToECDSAUnsafe(common.FromHex(opm.PrivateKey))
+
+	cli, err := c.getEthClient(conf, chainID, "")
+	if err != nil {
+		return "", err
+	}
+	defer cli.Close()
+
+	sender := crypto.PubkeyToAddress(pk.PublicKey)
+	forwarder := common.HexToAddress(tx.ForwarderAddress)
+	to := common.HexToAddress(tx.To)
+
+	ret, err := cli.CallContract(context.Background(), ethereum.CallMsg{
+		To:   &forwarder,
+		Data: append(append([]byte{}, getNonceSelector...), common.LeftPadBytes(sender.Bytes(), 32)...),
+	}, nil)
+	if err != nil {
+		return "", errors.Wrap(err, "get forwarder nonce")
+	}
+	nonce := new(big.Int).SetBytes(ret)
+
+	gas := tx.Gas
+	if gas == 0 {
+		// forwarder appends sender address to calldata (EIP-2771)
+		gas, err = cli.EstimateGas(context.Background(), ethereum.CallMsg{
+			From:  forwarder,
+			To:    &to,
+			Value: value,
+			Data:  append(append([]byte{}, data...), sender.Bytes()...),
+		})
+		if err != nil {
+			return "", errors.Wrap(err, "estimate gas")
+		}
+	}
+
+	req := &ForwardRequest{
+		From:  sender.Hex(),
+		To:    to.Hex(),
+		Value: value.String(),
+		Gas:   strconv.FormatUint(gas, 10),
+		Nonce: nonce.String(),
+		Data:  hexutil.Encode(data),
+	}
+	sig, err := req.Sign(chainID, forwarder.Hex(), tx.ForwarderName, tx.ForwarderVersion, pk)
+	if err != nil {
+		return "", errors.Wrap(err, "sign forward request")
+	}
+
+	metrics.BlockChainTxMtc.WithLabelValues(c.ProjectName, strconv.Itoa(int(chainID))).Inc()
+
+	return postRelayRequest(tx.RelayerURL, &RelayRequest{
+		Request:          req,
+		Signature:        sig,
+		ForwarderAddress: forwarder.Hex(),
+	})
+}
+
+func postRelayRequest(url string, req *RelayRequest) (string, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	cli := &http.Client{Timeout: relayTimeout}
+	rsp, err := cli.Post(url, "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		return "", err
+	}
+	defer rsp.Body.Close()
+
+	content, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return "", err
+	}
+	if rsp.StatusCode/100 != 2 {
+		return "", errors.Errorf("relayer responded %d: %s", rsp.StatusCode, content)
+	}
+	ret := &RelayResponse{}
+	if err = json.Unmarshal(content, ret); err != nil {
+		return "", errors.Wrap(err, "relayer response")
+	}
+	if ret.TxHash == "" {
+		return "", errors.New("missing txHash in relayer response")
+	}
+	return ret.TxHash, nil
+}
@@ -0,0 +1,50 @@
+package wasm_test
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	. "github.com/onsi/gomega"
+
+	"github.com/machinefi/w3bstream/pkg/types/wasm"
+)
+
+func TestForwardRequest_Sign(t *testing.T) {
+	pk, err := crypto.GenerateKey()
+	NewWithT(t).Expect(err).To(BeNil())
+
+	req := &wasm.ForwardRequest{
+		From:  crypto.PubkeyToAddress(pk.PublicKey).Hex(),
+		To:    "0x0000000000000000000000000000000000000002",
+		Value: "0",
+		Gas:   "100000",
+		Nonce: "1",
+		Data:  "0x1234",
+	}
+	forwarder := "0x0000000000000000000000000000000000000001"
+
+	sig, err := req.Sign(4690, forwarder, "MinimalForwarder", "0.0.1", pk)
+	NewWithT(t).Expect(err).To(BeNil())
+
+	raw, err := hexutil.Decode(sig)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(raw[64]).To(BeNumerically(">=", 27))
+
+	hash, _, err := apitypes.TypedDataAndHash(req.TypedData(4690, forwarder, "MinimalForwarder", "0.0.1"))
+	NewWithT(t).Expect(err).To(BeNil())
+
+	raw[64] -= 27
+	pub, err := crypto.SigToPub(hash, raw)
+	NewWithT(t).Expect(err).To(BeNil())
+	NewWithT(t).Expect(crypto.PubkeyToAddress(*pub).Hex()).To(Equal(req.From))
+}
+
+func TestEnv_RelayerAllowed(t *testing.T) {
+	env := &wasm.Env{RelayerURLs: []string{"https://relayer.example.com/relay"}}
+	NewWithT(t).Expect(env.RelayerAllowed("https://relayer.example.com/relay")).To(BeTrue())
+	NewWithT(t).Expect(env.RelayerAllowed("https://relayer.example.com/relay/")).To(BeFalse())
+	NewWithT(t).Expect(env.RelayerAllowed("http://127.0.0.1:8545")).To(BeFalse())
+	NewWithT(t).Expect((&wasm.Env{}).RelayerAllowed("")).To(BeFalse())
+}
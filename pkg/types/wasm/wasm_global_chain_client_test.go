@@ -0,0 +1,240 @@
+package wasm
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+
+	"github.com/machinefi/w3bstream/pkg/enums"
+	"github.com/machinefi/w3bstream/pkg/models"
+	optypes "github.com/machinefi/w3bstream/pkg/modules/operator/pool/types"
+	"github.com/machinefi/w3bstream/pkg/types"
+)
+
+func TestTruncateContractEvents(t *testing.T) {
+	logs := []ethtypes.Log{
+		{BlockNumber: 11}, {BlockNumber: 11},
+		{BlockNumber: 12}, {BlockNumber: 12},
+		{BlockNumber: 13},
+	}
+
+	t.Run("NotExceeded", func(t *testing.T) {
+		ret, last, err := truncateContractEvents(logs, 10, 20, 5)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(ret).To(HaveLen(5))
+		NewWithT(t).Expect(last).To(Equal(uint64(20)))
+	})
+
+	t.Run("KeepWholeBlocks", func(t *testing.T) {
+		ret, last, err := truncateContractEvents(logs, 10, 20, 3)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(ret).To(HaveLen(2))
+		NewWithT(t).Expect(last).To(Equal(uint64(11)))
+
+		ret, last, err = truncateContractEvents(logs, 10, 20, 4)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(ret).To(HaveLen(4))
+		NewWithT(t).Expect(last).To(Equal(uint64(12)))
+	})
+
+	t.Run("FirstBlockExceeded", func(t *testing.T) {
+		_, _, err := truncateContractEvents(logs, 10, 20, 1)
+		NewWithT(t).Expect(err).NotTo(BeNil())
+	})
+}
+
+func TestUnpackAggregate3(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(multicall3ABI))
+	NewWithT(t).Expect(err).To(BeNil())
+
+	type result struct {
+		Success    bool
+		ReturnData []byte
+	}
+	output, err := parsed.Methods["aggregate3"].Outputs.Pack([]result{
+		{Success: true, ReturnData: []byte{0x01, 0x02}},
+		{Success: false, ReturnData: []byte{}},
+	})
+	NewWithT(t).Expect(err).To(BeNil())
+
+	t.Run("Valid", func(t *testing.T) {
+		results, err := unpackAggregate3(output)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(results).To(Equal([]ContractCallResult{
+			{Success: true, ReturnData: "0x0102"},
+			{Success: false, ReturnData: "0x"},
+		}))
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		results, err := unpackAggregate3(nil)
+		NewWithT(t).Expect(err).NotTo(BeNil())
+		NewWithT(t).Expect(results).To(BeNil())
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		for _, n := range []int{1, 31, 32, 64, 96, len(output) - 32, len(output) - 1} {
+			results, err := unpackAggregate3(output[:n])
+			NewWithT(t).Expect(err).NotTo(BeNil(), "truncated to %d bytes", n)
+			NewWithT(t).Expect(results).To(BeNil())
+		}
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		// array offset points out of output
+		malformed := append([]byte{}, output...)
+		malformed[30] = 0xff
+		results, err := unpackAggregate3(malformed)
+		NewWithT(t).Expect(err).NotTo(BeNil())
+		NewWithT(t).Expect(results).To(BeNil())
+
+		// array length exceeds output
+		malformed = append([]byte{}, output...)
+		malformed[62] = 0xff
+		results, err = unpackAggregate3(malformed)
+		NewWithT(t).Expect(err).NotTo(BeNil())
+		NewWithT(t).Expect(results).To(BeNil())
+	})
+}
+
+func TestPackAggregate3(t *testing.T) {
+	t.Run("InvalidCallData", func(t *testing.T) {
+		_, err := packAggregate3([]ContractCall{{To: "0x01", Data: "0xzz"}})
+		NewWithT(t).Expect(err).NotTo(BeNil())
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		input, err := packAggregate3([]ContractCall{{To: "0x01", Data: "0x0102"}})
+		NewWithT(t).Expect(err).To(BeNil())
+		// 4 bytes selector of aggregate3((address,bool,bytes)[])
+		NewWithT(t).Expect(input[:4]).To(Equal([]byte{0x82, 0xad, 0x56, 0xcb}))
+	})
+}
+
+type gasSuggesterFake struct {
+	price, tip       *big.Int
+	priceErr, tipErr error
+}
+
+func (f *gasSuggesterFake) SuggestGasPrice(context.Context) (*big.Int, error) {
+	return f.price, f.priceErr
+}
+
+func (f *gasSuggesterFake) SuggestGasTipCap(context.Context) (*big.Int, error) {
+	return f.tip, f.tipErr
+}
+
+func TestSuggestGasPrice(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("EIP1559", func(t *testing.T) {
+		ret, err := suggestGasPrice(ctx, &gasSuggesterFake{price: big.NewInt(100), tip: big.NewInt(2)})
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(ret).To(Equal(&GasPrice{GasPrice: "100", MaxPriorityFeePerGas: "2"}))
+	})
+
+	t.Run("NonEIP1559FallbackToGasPrice", func(t *testing.T) {
+		ret, err := suggestGasPrice(ctx, &gasSuggesterFake{
+			price:  big.NewInt(100),
+			tipErr: errors.New("the method eth_maxPriorityFeePerGas does not exist"),
+		})
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(ret).To(Equal(&GasPrice{GasPrice: "100", MaxPriorityFeePerGas: "100"}))
+	})
+
+	t.Run("GasPriceFailed", func(t *testing.T) {
+		_, err := suggestGasPrice(ctx, &gasSuggesterFake{priceErr: errors.New("any"), tip: big.NewInt(2)})
+		NewWithT(t).Expect(err).NotTo(BeNil())
+	})
+}
+
+type nonceReaderFake struct {
+	pending, confirmed       uint64
+	pendingErr, confirmedErr error
+	// confirm the count of transactions confirmed each time the pending nonce
+	// is read
+	confirm uint64
+}
+
+func (f *nonceReaderFake) PendingNonceAt(context.Context, common.Address) (uint64, error) {
+	if f.confirmed+f.confirm <= f.pending {
+		f.confirmed += f.confirm
+	}
+	return f.pending, f.pendingErr
+}
+
+func (f *nonceReaderFake) NonceAt(context.Context, common.Address, *big.Int) (uint64, error) {
+	return f.confirmed, f.confirmedErr
+}
+
+func TestPendingTxCount(t *testing.T) {
+	ctx := context.Background()
+	addr := common.HexToAddress("0x01")
+
+	cases := []struct {
+		name   string
+		cli    *nonceReaderFake
+		count  uint64
+		failed bool
+	}{
+		{"Pending", &nonceReaderFake{pending: 12, confirmed: 10}, 2, false},
+		{"NoPending", &nonceReaderFake{pending: 10, confirmed: 10}, 0, false},
+		{"PendingLagsBehind", &nonceReaderFake{pending: 9, confirmed: 10}, 0, false},
+		{"PendingNonceFailed", &nonceReaderFake{pendingErr: errors.New("any")}, 0, true},
+		{"NonceFailed", &nonceReaderFake{pending: 12, confirmedErr: errors.New("any")}, 0, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			count, err := pendingTxCount(ctx, c.cli, addr)
+			NewWithT(t).Expect(err != nil).To(Equal(c.failed))
+			NewWithT(t).Expect(count).To(Equal(c.count))
+		})
+	}
+}
+
+func TestChainClient_PendingTxCount_InvalidKeyType(t *testing.T) {
+	op := &optypes.SyncOperator{Op: &models.Operator{
+		OperatorInfo: models.OperatorInfo{Type: enums.OPERATOR_KEY__ED25519},
+	}}
+	_, err := (&ChainClient{}).PendingTxCount(&types.ChainConfig{}, 4690, op)
+	NewWithT(t).Expect(err).NotTo(BeNil())
+}
+
+func TestWaitPendingTxsDrained(t *testing.T) {
+	addr := common.HexToAddress("0x01")
+
+	t.Run("Drained", func(t *testing.T) {
+		cli := &nonceReaderFake{pending: 13, confirmed: 10, confirm: 1}
+		err := waitPendingTxsDrained(context.Background(), cli, addr, time.Millisecond)
+		NewWithT(t).Expect(err).To(BeNil())
+		NewWithT(t).Expect(cli.confirmed).To(Equal(uint64(13)))
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		cli := &nonceReaderFake{pending: 12, confirmed: 10}
+		err := waitPendingTxsDrained(ctx, cli, addr, time.Millisecond)
+		NewWithT(t).Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		cli := &nonceReaderFake{pendingErr: errors.New("any")}
+		err := waitPendingTxsDrained(context.Background(), cli, addr, time.Millisecond)
+		NewWithT(t).Expect(err).NotTo(BeNil())
+	})
+
+	t.Run("NotECDSA", func(t *testing.T) {
+		op := &models.Operator{OperatorInfo: models.OperatorInfo{Type: enums.OPERATOR_KEY__ED25519}}
+		err := WaitPendingTxsDrained(context.Background(), &types.ChainConfig{}, op, time.Millisecond)
+		NewWithT(t).Expect(err).To(BeNil())
+	})
+}
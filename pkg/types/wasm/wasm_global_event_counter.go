@@ -0,0 +1,62 @@
+package wasm
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/gomodule/redigo/redis"
+
+	"github.com/machinefi/w3bstream/pkg/depends/base/types"
+	confredis "github.com/machinefi/w3bstream/pkg/depends/conf/redis"
+)
+
+// EventCounter project scoped monotonic event counter persisted in redis
+type EventCounter struct {
+	rds  *confredis.Redis
+	key  string
+	base func() (int64, error)
+
+	mu     sync.Mutex
+	inited bool
+}
+
+// NewEventCounter creates counter of project. base is used to initialize the
+// counter when it does not exist in redis, eg: count of persisted event logs
+func NewEventCounter(rds *confredis.Redis, prj types.SFID, base func() (int64, error)) *EventCounter {
+	return &EventCounter{
+		rds:  rds,
+		key:  rds.Key(fmt.Sprintf("event_count:%d", prj)),
+		base: base,
+	}
+}
+
+func (c *EventCounter) init() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.inited {
+		return nil
+	}
+	n, err := c.base()
+	if err != nil {
+		return err
+	}
+	// SETNX keeps the counter if it was initialized by other instances
+	if _, err = c.rds.Exec(&confredis.Cmd{Name: "SETNX", Args: []interface{}{c.key, n}}); err != nil {
+		return err
+	}
+	c.inited = true
+	return nil
+}
+
+// Next returns current counter value and increases it atomically
+func (c *EventCounter) Next() (int64, error) {
+	if err := c.init(); err != nil {
+		return 0, err
+	}
+	n, err := redis.Int64(c.rds.Exec(&confredis.Cmd{Name: "INCR", Args: []interface{}{c.key}}))
+	if err != nil {
+		return 0, err
+	}
+	return n - 1, nil
+}